@@ -28,8 +28,22 @@ func main() {
 	smollmEnabled := flag.Bool("smollm-enabled", false, "Enable smollm proxy mode")
 	smollmUpstreamURL := flag.String("smollm-upstream-url", "http://127.0.0.1:8081", "Upstream llama.cpp server URL")
 
+	maxClientBatchSize := flag.Int("max-client-batch-size", 32, "Maximum number of requests accepted per POST to /v1/batch/completions")
+
+	toolsEnabled := flag.Bool("enable-tools", false, "Enable the built-in tool executor toolbox (dir_tree, read_file, http_get, now, calculator) for streamed tool calls")
+
+	backendsConfig := flag.String("backends-config", "", "Path to a YAML models config routing specific models to real gRPC backends instead of the built-in simulator")
+
+	providersConfig := flag.String("providers-config", "", "Path to a YAML providers config routing specific models (by name or glob) to real upstream providers (openai, anthropic, ollama, gemini, passthrough)")
+
 	flag.Parse()
 
+	if v := os.Getenv("MAX_CLIENT_BATCH_SIZE"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			*maxClientBatchSize = n
+		}
+	}
+
 	addr := fmt.Sprintf(":%d", *port)
 	log.Printf("Starting OpenAI API Simulator on %s", addr)
 	defaults := streaming.StreamOptions{}
@@ -73,7 +87,7 @@ func main() {
 		}
 	}
 
-	handler := server.NewRouterWithStreamDefaults(defaults, *defaultResponseLength, *smollmEnabled, *smollmUpstreamURL)
+	handler := server.NewRouterWithOptions(defaults, *defaultResponseLength, *smollmEnabled, *smollmUpstreamURL, *maxClientBatchSize, *toolsEnabled, *backendsConfig, *providersConfig)
 	if err := http.ListenAndServe(addr, handler); err != nil {
 		log.Fatalf("server failed: %v", err)
 	}
@@ -0,0 +1,149 @@
+package nanochat
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ChatChunk is one OpenAI chat.completion.chunk SSE event, kept close to
+// the wire (Data holds the exact JSON payload the backend sent) so an
+// upstream handler can forward it straight to its own client as
+// "data: <Data>\n\n" instead of decoding and re-encoding it.
+type ChatChunk struct {
+	// Data is the raw JSON payload of the "data:" field(s) for this event,
+	// already joined across any multi-line continuation. Empty for the
+	// terminal chunk, where Done is true instead.
+	Data string
+	// Delta is the convenience-extracted choices[0].delta.content, for
+	// callers that just want the text and don't care about the wire
+	// format.
+	Delta string
+	// FinishReason is choices[0].finish_reason, empty until the final
+	// content chunk.
+	FinishReason string
+	// Done is true once the backend has sent the terminal "data: [DONE]"
+	// event; Data, Delta, and FinishReason are meaningless on this chunk.
+	Done bool
+}
+
+type chatStreamItem struct {
+	chunk ChatChunk
+	err   error
+}
+
+// ChatStream streams ChatChunk values from an Engine.Chat call.
+type ChatStream struct {
+	items <-chan chatStreamItem
+}
+
+// Next returns the next chunk, or io.EOF once the stream is exhausted.
+func (s *ChatStream) Next(ctx context.Context) (ChatChunk, error) {
+	select {
+	case item, ok := <-s.items:
+		if !ok {
+			return ChatChunk{}, io.EOF
+		}
+		return item.chunk, item.err
+	case <-ctx.Done():
+		return ChatChunk{}, ctx.Err()
+	}
+}
+
+// CollectText drains the stream and concatenates every delta into a single
+// string. It's the ChatStream equivalent of the old CompletionToken-based
+// StreamResponse.CollectTokens, kept for callers (and tests) that just want
+// the final text rather than the raw chunks.
+func (s *ChatStream) CollectText(ctx context.Context) (string, error) {
+	var result strings.Builder
+
+	for {
+		chunk, err := s.Next(ctx)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return "", err
+		}
+		if chunk.Done {
+			break
+		}
+		result.WriteString(chunk.Delta)
+	}
+
+	return result.String(), nil
+}
+
+// drainTracking wraps stream so done is called exactly once, when the
+// underlying stream is fully drained (or errors out). PythonEngine.Chat
+// uses it to stop watching for cancellation once a stream is no longer
+// live, and EnginePool uses it to track a worker's in-flight chats for the
+// full lifetime of the stream rather than just the initial dispatch.
+func drainTracking(stream *ChatStream, done func()) *ChatStream {
+	out := make(chan chatStreamItem, 10)
+	go func() {
+		defer close(out)
+		defer done()
+		for item := range stream.items {
+			out <- item
+		}
+	}()
+	return &ChatStream{items: out}
+}
+
+// openAIDeltaChunk is the shape of a chat.completion.chunk SSE payload,
+// used to fill in ChatChunk's convenience fields from its raw Data.
+type openAIDeltaChunk struct {
+	Choices []struct {
+		Delta struct {
+			Content string `json:"content"`
+		} `json:"delta"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+}
+
+// newChatStreamFromSSE reads OpenAI-dialect chat.completion.chunk events
+// from r and relays them as ChatChunk values, stopping at the terminal
+// "data: [DONE]" event. r is closed once the stream is exhausted.
+func newChatStreamFromSSE(r io.ReadCloser) *ChatStream {
+	items := make(chan chatStreamItem, 10)
+
+	go func() {
+		defer close(items)
+		defer r.Close()
+
+		err := readSSEEvents(r, func(event sseEvent) error {
+			if event.Data == "" {
+				return nil
+			}
+			if event.Data == "[DONE]" {
+				items <- chatStreamItem{chunk: ChatChunk{Done: true}}
+				return nil
+			}
+
+			var parsed openAIDeltaChunk
+			if err := json.Unmarshal([]byte(event.Data), &parsed); err != nil {
+				items <- chatStreamItem{err: fmt.Errorf("failed to parse chunk: %w", err)}
+				return nil
+			}
+
+			chunk := ChatChunk{Data: event.Data}
+			if len(parsed.Choices) > 0 {
+				choice := parsed.Choices[0]
+				chunk.Delta = choice.Delta.Content
+				if choice.FinishReason != nil {
+					chunk.FinishReason = *choice.FinishReason
+				}
+			}
+			items <- chatStreamItem{chunk: chunk}
+			return nil
+		})
+		if err != nil {
+			items <- chatStreamItem{err: fmt.Errorf("read error: %w", err)}
+		}
+	}()
+
+	return &ChatStream{items: items}
+}
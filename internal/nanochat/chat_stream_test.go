@@ -0,0 +1,51 @@
+package nanochat
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+)
+
+type stringReadCloser struct {
+	io.Reader
+}
+
+func (stringReadCloser) Close() error { return nil }
+
+func TestNewChatStreamFromSSECollectsDeltasAndStopsAtDone(t *testing.T) {
+	raw := strings.Join([]string{
+		`data: {"choices":[{"delta":{"content":"Hello"}}]}`,
+		`data: {"choices":[{"delta":{"content":" world"},"finish_reason":"stop"}]}`,
+		`data: [DONE]`,
+		"",
+	}, "\n\n")
+
+	stream := newChatStreamFromSSE(stringReadCloser{strings.NewReader(raw)})
+
+	text, err := stream.CollectText(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if text != "Hello world" {
+		t.Errorf("expected 'Hello world', got %q", text)
+	}
+}
+
+func TestChatStreamNextReturnsEOFAfterDone(t *testing.T) {
+	raw := `data: [DONE]` + "\n\n"
+
+	stream := newChatStreamFromSSE(stringReadCloser{strings.NewReader(raw)})
+
+	chunk, err := stream.Next(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !chunk.Done {
+		t.Error("expected Done chunk")
+	}
+
+	if _, err := stream.Next(context.Background()); err != io.EOF {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+}
@@ -17,12 +17,32 @@ import (
 )
 
 const (
-	cacheRoot      = ".cache/openai-api-simulator"
-	modelURL       = "https://huggingface.co/sdobson/nanochat/resolve/main/nanochat-q4_k_m.gguf"
-	modelFile      = "nanochat-q4_k_m.gguf"
-	expectedSHA256 = "8f2d9e8c5d8e9b1a3f7c9e2d4b6e1a8f7c3d2e9f1a0b8c7d6e5f4a3b2c1d0e9"
+	cacheRoot = ".cache/openai-api-simulator"
+	modelURL  = "https://huggingface.co/sdobson/nanochat/resolve/main/nanochat-q4_k_m.gguf"
 )
 
+// GGUFModelSource describes a model file to download: where to fetch it and,
+// optionally, its expected SHA256. Callers that want to serve a model
+// beyond the built-in nanochat checkpoint register one of these and pass
+// it to ensureGGUFModelSource instead of touching package constants.
+type GGUFModelSource struct {
+	Name string
+	URL  string
+	// SHA256 is the expected digest, if known up front. Leave empty to
+	// have ensureGGUFModelSource discover it from a sidecar checksum file
+	// published alongside URL.
+	SHA256 string
+}
+
+// defaultGGUFModelSource is the nanochat checkpoint ensureModel fetches. Its
+// SHA256 is intentionally left empty: the upstream repo doesn't publish a
+// fixed digest for it, so it's discovered via fetchChecksumFor instead of
+// a hardcoded (and easily stale) constant.
+var defaultGGUFModelSource = GGUFModelSource{
+	Name: "nanochat",
+	URL:  modelURL,
+}
+
 // getLatestTag retrieves the latest llama.cpp release tag by following the redirect
 func getLatestTag() string {
 	client := &http.Client{
@@ -61,44 +81,119 @@ func binaryURL(tag string) (url, innerName string) {
 	panic("unsupported platform")
 }
 
-// downloadFile downloads a file from url to destPath with a progress bar
-func downloadFile(url, destPath string) error {
-	// Get the file size
-	resp, err := http.Head(url)
+// downloadFile downloads url to destPath, resuming from any partial
+// destPath already on disk via an HTTP Range request when the server
+// supports it, and returns the SHA256 of the complete file computed while
+// streaming so the caller's verification costs nothing extra.
+func downloadFile(url, destPath string) (string, error) {
+	client := &http.Client{}
+
+	headResp, err := client.Head(url)
 	if err != nil {
-		return fmt.Errorf("failed to get file info: %w", err)
+		return "", fmt.Errorf("failed to get file info: %w", err)
 	}
-	fileSize := resp.ContentLength
+	headResp.Body.Close()
+	totalSize := headResp.ContentLength
+	acceptsRanges := headResp.Header.Get("Accept-Ranges") == "bytes"
 
-	// Create the file
-	out, err := os.Create(destPath)
+	var existingSize int64
+	if info, statErr := os.Stat(destPath); statErr == nil {
+		existingSize = info.Size()
+	}
+
+	if existingSize > 0 && acceptsRanges && (totalSize <= 0 || existingSize < totalSize) {
+		hash, err := resumeDownload(client, url, destPath, existingSize, totalSize)
+		if err == nil {
+			return hash, nil
+		}
+		fmt.Printf("resume failed (%v); restarting download from scratch\n", err)
+		if rmErr := os.Remove(destPath); rmErr != nil && !os.IsNotExist(rmErr) {
+			return "", fmt.Errorf("failed to remove partial download: %w", rmErr)
+		}
+	}
+
+	return freshDownload(client, url, destPath, totalSize)
+}
+
+// resumeDownload appends to an existing partial destPath starting at
+// existingSize, via a "Range: bytes=N-" request.
+func resumeDownload(client *http.Client, url, destPath string, existingSize, totalSize int64) (string, error) {
+	hasher := sha256.New()
+	existing, err := os.Open(destPath)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return "", fmt.Errorf("failed to open partial download: %w", err)
+	}
+	_, err = io.Copy(hasher, existing)
+	existing.Close()
+	if err != nil {
+		return "", fmt.Errorf("failed to hash partial download: %w", err)
 	}
-	defer out.Close()
 
-	// Download with progress bar
-	resp, err = http.Get(url)
+	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
-		return fmt.Errorf("failed to download: %w", err)
+		return "", fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", existingSize))
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
 	}
 	defer resp.Body.Close()
 
-	bar := progressbar.DefaultBytes(
-		fileSize,
-		fmt.Sprintf("Downloading %s", filepath.Base(destPath)),
-	)
+	if resp.StatusCode != http.StatusPartialContent {
+		return "", fmt.Errorf("server returned %d for range request", resp.StatusCode)
+	}
+
+	out, err := os.OpenFile(destPath, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return "", fmt.Errorf("failed to open file for resume: %w", err)
+	}
+	defer out.Close()
+
+	fmt.Printf("↻ Resuming %s from byte %d\n", filepath.Base(destPath), existingSize)
+	bar := progressbar.DefaultBytes(totalSize, fmt.Sprintf("Downloading %s", filepath.Base(destPath)))
+	bar.Add64(existingSize)
 
-	_, err = io.Copy(io.MultiWriter(out, bar), resp.Body)
+	if _, err := io.Copy(io.MultiWriter(out, hasher, bar), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+	fmt.Println()
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
+
+// freshDownload downloads url to destPath from the beginning, overwriting
+// whatever (if anything) was already there.
+func freshDownload(client *http.Client, url, destPath string, totalSize int64) (string, error) {
+	out, err := os.Create(destPath)
 	if err != nil {
-		return fmt.Errorf("failed to write file: %w", err)
+		return "", fmt.Errorf("failed to create file: %w", err)
 	}
+	defer out.Close()
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", fmt.Errorf("failed to download: %w", err)
+	}
+	defer resp.Body.Close()
 
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status %d downloading %s", resp.StatusCode, url)
+	}
+
+	hasher := sha256.New()
+	bar := progressbar.DefaultBytes(totalSize, fmt.Sprintf("Downloading %s", filepath.Base(destPath)))
+
+	if _, err := io.Copy(io.MultiWriter(out, hasher, bar), resp.Body); err != nil {
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
 	fmt.Println()
-	return nil
+
+	return hex.EncodeToString(hasher.Sum(nil)), nil
 }
 
-// verifySHA256 verifies the SHA256 checksum of a file
+// verifySHA256 verifies the SHA256 checksum of a file already on disk.
 func verifySHA256(filePath, expectedHash string) (bool, error) {
 	f, err := os.Open(filePath)
 	if err != nil {
@@ -112,37 +207,127 @@ func verifySHA256(filePath, expectedHash string) (bool, error) {
 	}
 
 	actualHash := hex.EncodeToString(h.Sum(nil))
-	return actualHash == expectedHash, nil
+	return strings.EqualFold(actualHash, expectedHash), nil
 }
 
-// ensureModel ensures the model file is downloaded and cached
-func ensureModel(cacheDir string) string {
-	modelPath := filepath.Join(cacheDir, modelFile)
+// fetchChecksumFor discovers the expected SHA256 for source, preferring an
+// explicit source.SHA256, then a sidecar "<url>.sha256" file, then a
+// "checksums.txt" published alongside it in the sha256sum(1)
+// "<hash>  <filename>" format. It returns "" with a nil error if none of
+// these are available, since not every model is published with a
+// checksum.
+func fetchChecksumFor(client *http.Client, source GGUFModelSource) (string, error) {
+	if source.SHA256 != "" {
+		return source.SHA256, nil
+	}
+
+	if hash, err := fetchSidecarHash(client, source.URL+".sha256", ""); err == nil && hash != "" {
+		return hash, nil
+	}
+
+	dir := source.URL[:strings.LastIndex(source.URL, "/")+1]
+	filename := filepath.Base(source.URL)
+	if hash, err := fetchSidecarHash(client, dir+"checksums.txt", filename); err == nil && hash != "" {
+		return hash, nil
+	}
+
+	return "", nil
+}
+
+// fetchSidecarHash GETs url and extracts a SHA256 hex digest from its
+// body. When filename is empty, the whole trimmed body is the digest (the
+// "<file>.sha256" convention); otherwise the body is treated as a
+// sha256sum(1) manifest and the line naming filename is matched.
+func fetchSidecarHash(client *http.Client, url, filename string) (string, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("sidecar checksum %s returned %d", url, resp.StatusCode)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", err
+	}
+
+	if filename == "" {
+		fields := strings.Fields(string(body))
+		if len(fields) == 0 {
+			return "", fmt.Errorf("empty sidecar checksum at %s", url)
+		}
+		return fields[0], nil
+	}
+
+	for _, line := range strings.Split(string(body), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == filename {
+			return fields[0], nil
+		}
+	}
+
+	return "", fmt.Errorf("no checksum for %s in %s", filename, url)
+}
+
+// ensureModel ensures the default nanochat model file is downloaded,
+// cached, and verified.
+func ensureModel(cacheDir string) (string, error) {
+	return ensureGGUFModelSource(cacheDir, defaultGGUFModelSource)
+}
+
+// ensureGGUFModelSource is like ensureModel but for an arbitrary GGUFModelSource,
+// so callers can serve models beyond the built-in nanochat checkpoint. It
+// returns an error instead of exiting the process on a failed or corrupt
+// download, deleting the corrupt file so the next attempt starts clean.
+func ensureGGUFModelSource(cacheDir string, source GGUFModelSource) (string, error) {
+	modelPath := filepath.Join(cacheDir, filepath.Base(source.URL))
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	expectedHash, err := fetchChecksumFor(client, source)
+	if err != nil {
+		fmt.Printf("warning: failed to fetch checksum for %s: %v\n", source.Name, err)
+	}
 
-	// Check if model exists and is valid
 	if _, err := os.Stat(modelPath); err == nil {
-		// Note: The ADR includes a SHA256 hash but it's a placeholder.
-		// For production, you'd want to verify it. For now, just check existence.
+		if expectedHash != "" {
+			ok, err := verifySHA256(modelPath, expectedHash)
+			if err != nil {
+				return "", fmt.Errorf("failed to verify cached %s: %w", source.Name, err)
+			}
+			if !ok {
+				if rmErr := os.Remove(modelPath); rmErr != nil {
+					return "", fmt.Errorf("cached %s failed checksum verification and could not be removed: %w", source.Name, rmErr)
+				}
+				return "", fmt.Errorf("cached %s failed checksum verification; deleted corrupt file, re-run to re-download", source.Name)
+			}
+		}
 		fmt.Printf("✓ Model already cached: %s\n", modelPath)
-		return modelPath
+		return modelPath, nil
 	}
 
-	// Download the model
-	fmt.Printf("↓ Downloading nanochat model (316 MB)...\n")
+	fmt.Printf("↓ Downloading %s...\n", source.Name)
 	tmpPath := modelPath + ".tmp"
-	if err := downloadFile(modelURL, tmpPath); err != nil {
-		fmt.Printf("Failed to download model: %v\n", err)
-		os.Exit(1)
+	actualHash, err := downloadFile(source.URL, tmpPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to download %s: %w", source.Name, err)
+	}
+
+	if expectedHash != "" && !strings.EqualFold(actualHash, expectedHash) {
+		if rmErr := os.Remove(tmpPath); rmErr != nil {
+			return "", fmt.Errorf("%s checksum mismatch (got %s, want %s); also failed to remove corrupt download: %w", source.Name, actualHash, expectedHash, rmErr)
+		}
+		return "", fmt.Errorf("%s checksum mismatch: got %s, want %s", source.Name, actualHash, expectedHash)
 	}
 
-	// Move to final location
 	if err := os.Rename(tmpPath, modelPath); err != nil {
-		fmt.Printf("Failed to save model: %v\n", err)
-		os.Exit(1)
+		return "", fmt.Errorf("failed to save %s: %w", source.Name, err)
 	}
 
-	fmt.Printf("✓ Model downloaded successfully\n")
-	return modelPath
+	fmt.Printf("✓ %s downloaded successfully\n", source.Name)
+	return modelPath, nil
 }
 
 // unzipFile extracts a specific file from a zip archive
@@ -216,7 +401,7 @@ func ensureLlamaServer(cacheDir string) string {
 	fmt.Printf("↓ Downloading llama-server...\n")
 
 	zipPath := filepath.Join(cacheDir, "llama-server.zip")
-	if err := downloadFile(url, zipPath); err != nil {
+	if _, err := downloadFile(url, zipPath); err != nil {
 		fmt.Printf("Failed to download llama-server: %v\n", err)
 		os.Exit(1)
 	}
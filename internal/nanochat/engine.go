@@ -0,0 +1,26 @@
+package nanochat
+
+import "context"
+
+// Engine is the common contract every nanochat inference backend satisfies,
+// whether it launches a Python process, a llama.cpp server, vLLM, or Apple's
+// MLX runtime. PythonEngine was the original implementation.
+type Engine interface {
+	// Start launches the backend subprocess and blocks until it reports
+	// healthy or the implementation's own timeout elapses.
+	Start(ctx context.Context, logPath string) error
+	// Stop interrupts the backend and waits for it to exit. It force-kills
+	// the process only when the interrupt signal cannot be delivered.
+	Stop() error
+	// Health reports whether the backend is currently responding.
+	Health(ctx context.Context) error
+	// Chat sends a completion request and returns a ChatStream of the
+	// backend's chat.completion.chunk events.
+	Chat(ctx context.Context, req *ChatCompletionRequest) (*ChatStream, error)
+	// URL returns the backend's base HTTP URL.
+	URL() string
+	// IsRunning reports whether the backend subprocess is currently alive.
+	IsRunning() bool
+}
+
+var _ Engine = (*PythonEngine)(nil)
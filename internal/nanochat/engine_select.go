@@ -0,0 +1,47 @@
+package nanochat
+
+import (
+	"fmt"
+	"os"
+)
+
+// Backend names understood by NewEngine and the NANOCHAT_BACKEND
+// environment variable.
+const (
+	BackendPython   = "python"
+	BackendLlamaCPP = "llama.cpp"
+	BackendVLLM     = "vllm"
+	BackendMLX      = "mlx"
+)
+
+// NewEngine constructs the Engine for the named backend (one of
+// BackendPython, BackendLlamaCPP, BackendVLLM, BackendMLX). An empty backend
+// falls back to the NANOCHAT_BACKEND environment variable, then to
+// BackendPython, so operators can move the simulator onto a GPU host's
+// real inference stack without recompiling, while keeping the lightweight
+// Python engine as the default on developer machines.
+//
+// cacheDir is used by BackendLlamaCPP to store the downloaded llama-server
+// binary and model; modelDir is used by BackendPython, BackendVLLM, and
+// BackendMLX to locate the model to serve.
+func NewEngine(backend, cacheDir, modelDir string) (Engine, error) {
+	if backend == "" {
+		backend = os.Getenv("NANOCHAT_BACKEND")
+	}
+	if backend == "" {
+		backend = BackendPython
+	}
+
+	switch backend {
+	case BackendPython:
+		return NewPythonEngine(modelDir), nil
+	case BackendLlamaCPP:
+		return NewLlamaServerEngine(cacheDir), nil
+	case BackendVLLM:
+		return NewVLLMEngine(modelDir), nil
+	case BackendMLX:
+		return NewMLXEngine(modelDir)
+	default:
+		return nil, fmt.Errorf("unknown NANOCHAT_BACKEND %q (want %q, %q, %q, or %q)", backend, BackendPython, BackendLlamaCPP, BackendVLLM, BackendMLX)
+	}
+}
@@ -0,0 +1,64 @@
+package nanochat
+
+import (
+	"os"
+	"runtime"
+	"testing"
+)
+
+func TestNewEngineDefaultsToPython(t *testing.T) {
+	os.Unsetenv("NANOCHAT_BACKEND")
+
+	engine, err := NewEngine("", "/tmp/test-cache", "/tmp/test-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := engine.(*PythonEngine); !ok {
+		t.Errorf("expected *PythonEngine, got %T", engine)
+	}
+}
+
+func TestNewEngineHonorsBackendArgOverEnv(t *testing.T) {
+	os.Setenv("NANOCHAT_BACKEND", BackendVLLM)
+	defer os.Unsetenv("NANOCHAT_BACKEND")
+
+	engine, err := NewEngine(BackendLlamaCPP, "/tmp/test-cache", "/tmp/test-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := engine.(*LlamaServerEngine); !ok {
+		t.Errorf("expected *LlamaServerEngine, got %T", engine)
+	}
+}
+
+func TestNewEngineFallsBackToEnvVar(t *testing.T) {
+	os.Setenv("NANOCHAT_BACKEND", BackendVLLM)
+	defer os.Unsetenv("NANOCHAT_BACKEND")
+
+	engine, err := NewEngine("", "/tmp/test-cache", "/tmp/test-model")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if _, ok := engine.(*VLLMEngine); !ok {
+		t.Errorf("expected *VLLMEngine, got %T", engine)
+	}
+}
+
+func TestNewEngineRejectsUnknownBackend(t *testing.T) {
+	if _, err := NewEngine("not-a-backend", "/tmp/test-cache", "/tmp/test-model"); err == nil {
+		t.Error("expected error for unknown backend")
+	}
+}
+
+func TestNewMLXEngineRejectsNonAppleSilicon(t *testing.T) {
+	if runtime.GOOS == "darwin" && runtime.GOARCH == "arm64" {
+		t.Skip("only verifies the rejection path on non-Apple-Silicon hosts")
+	}
+
+	if _, err := NewMLXEngine("/tmp/test-model"); err == nil {
+		t.Error("expected error constructing MLXEngine on non-darwin/arm64")
+	}
+}
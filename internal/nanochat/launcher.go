@@ -28,7 +28,10 @@ func Run(publicPort, llamaPort int) error {
 
 	// Ensure llama-server and model are available
 	serverPath := ensureLlamaServer(cacheDir)
-	modelPath := ensureModel(cacheDir)
+	modelPath, err := ensureModel(cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to prepare model: %w", err)
+	}
 
 	// Start llama.cpp server
 	fmt.Printf("→ Starting llama.cpp server on 127.0.0.1:%d", llamaPort)
@@ -0,0 +1,54 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"time"
+)
+
+// llamaServerPort is the fixed port LlamaServerEngine binds llama-server to.
+// It's distinct from the port launcher.go's Run uses so the two code paths
+// never collide if both happen to run on the same host.
+const llamaServerPort = 8082
+
+// LlamaServerEngine manages a llama.cpp llama-server subprocess, reusing the
+// same binary download/cache and GPU-offload logic as the standalone
+// nanochat launcher (downloader.go's ensureLlamaServer and gpuLayers).
+type LlamaServerEngine struct {
+	*subprocessEngine
+	cacheDir string
+}
+
+// NewLlamaServerEngine creates a llama.cpp engine manager that caches its
+// binary and model under cacheDir.
+func NewLlamaServerEngine(cacheDir string) *LlamaServerEngine {
+	return &LlamaServerEngine{
+		subprocessEngine: newSubprocessEngine(
+			"[LlamaServerEngine]", "llama-server",
+			fmt.Sprintf("http://127.0.0.1:%d", llamaServerPort), "/v1/models",
+		),
+		cacheDir: cacheDir,
+	}
+}
+
+// Start downloads (if needed) and launches the llama-server subprocess.
+func (e *LlamaServerEngine) Start(ctx context.Context, logPath string) error {
+	serverPath := ensureLlamaServer(e.cacheDir)
+	modelPath, err := ensureModel(e.cacheDir)
+	if err != nil {
+		return fmt.Errorf("failed to prepare model: %w", err)
+	}
+
+	return e.subprocessEngine.start(ctx, logPath, serverPath, []string{
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", llamaServerPort),
+		"--model", modelPath,
+		"--ctx-size", "4096",
+		"--temp", "0.7",
+		"--n-gpu-layers", gpuLayers(),
+		"--threads", fmt.Sprintf("%d", runtime.NumCPU()),
+	}, 45*time.Second)
+}
+
+var _ Engine = (*LlamaServerEngine)(nil)
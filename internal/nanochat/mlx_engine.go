@@ -0,0 +1,57 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"runtime"
+	"time"
+)
+
+// mlxServerPort is the fixed port MLXEngine binds mlx_lm.server to.
+const mlxServerPort = 8084
+
+// MLXEngine manages an Apple MLX inference subprocess (mlx_lm.server),
+// which only runs on Apple Silicon. It's gated at construction time rather
+// than with a build tag so the rest of the package still compiles and the
+// selection error surfaces as a normal Go error instead of a build failure
+// on non-Darwin hosts.
+type MLXEngine struct {
+	*subprocessEngine
+	modelDir string
+	mlxBin   string
+}
+
+// NewMLXEngine creates an MLX engine manager that serves the model at
+// modelDir. It returns an error on any platform other than darwin/arm64,
+// since MLX requires Apple Silicon's unified memory and Metal support.
+func NewMLXEngine(modelDir string) (*MLXEngine, error) {
+	if runtime.GOOS != "darwin" || runtime.GOARCH != "arm64" {
+		return nil, fmt.Errorf("mlx engine requires darwin/arm64 (Apple Silicon), got %s/%s", runtime.GOOS, runtime.GOARCH)
+	}
+
+	mlxBin := os.Getenv("MLX_BIN")
+	if mlxBin == "" {
+		mlxBin = "mlx_lm.server"
+	}
+
+	return &MLXEngine{
+		subprocessEngine: newSubprocessEngine(
+			"[MLXEngine]", "mlx_lm.server",
+			fmt.Sprintf("http://127.0.0.1:%d", mlxServerPort), "/v1/models",
+		),
+		modelDir: modelDir,
+		mlxBin:   mlxBin,
+	}, nil
+}
+
+// Start launches the mlx_lm.server subprocess.
+func (e *MLXEngine) Start(ctx context.Context, logPath string) error {
+	return e.subprocessEngine.start(ctx, logPath, e.mlxBin, []string{
+		"--model", e.modelDir,
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", mlxServerPort),
+	}, 45*time.Second)
+}
+
+var _ Engine = (*MLXEngine)(nil)
@@ -2,6 +2,10 @@ package nanochat
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -24,23 +28,185 @@ const (
 	nanoMetaFile       = "meta_000650.json"
 	nanoTokenizerFile  = "tokenizer.pkl"
 	nanoTokenBytesFile = "token_bytes.pt"
+
+	// manifestFile ships alongside the model files and lists each one's
+	// expected sha256.
+	manifestFile = "manifest.json"
+
+	// defaultChunks is how many concurrent byte-range requests a fresh
+	// download is split across.
+	defaultChunks = 4
+	// defaultMaxRetries is how many extra attempts a failed chunk gets,
+	// with exponential backoff, before the whole download fails.
+	defaultMaxRetries = 3
+)
+
+// ChecksumMode controls how ModelManager reacts when manifest.json doesn't
+// list a checksum for a file it just downloaded.
+type ChecksumMode int
+
+const (
+	// ChecksumOptional skips verification for files the manifest doesn't
+	// cover. This is the default, since manifest.json may not exist at
+	// all for every source ModelManager is pointed at.
+	ChecksumOptional ChecksumMode = iota
+	// ChecksumRequired fails the download if any file lacks a manifest
+	// entry to verify against.
+	ChecksumRequired
 )
 
-// ModelManager handles downloading and caching nanochat model files
+// manifest is the shape of manifest.json: a map of filename to its
+// expected sha256.
+type manifest struct {
+	Files map[string]struct {
+		SHA256 string `json:"sha256"`
+	} `json:"files"`
+}
+
+// ModelFile is one file a ModelSpec expects ModelManager to fetch and
+// cache.
+type ModelFile struct {
+	// Name is the filename as it appears at the model's source and in
+	// the cache directory.
+	Name string
+	// MinSize is the smallest size (in bytes) VerifyIntegrity accepts
+	// for this file; 0 skips the check.
+	MinSize int64
+	// Optional marks a file ModelExists/VerifyIntegrity don't require,
+	// matching the nanochat checkpoint's optional token_bytes.pt.
+	Optional bool
+}
+
+// ModelSpec describes one downloadable model: its identity (for
+// ModelRegistry) and the files that make it up. The zero value is not
+// useful; use defaultNanochatSpec or a ModelRegistry-loaded spec.
+type ModelSpec struct {
+	// ID names this model within a ModelRegistry, and its cache
+	// subdirectory (cacheDir/<ID>/...).
+	ID string
+	// Alias is the model name clients request (e.g. via
+	// ChatCompletionRequest.Model) to select this model.
+	Alias string
+	// Source is the URI ModelSourceFromURI resolves to fetch Files from.
+	Source string
+	Files  []ModelFile
+}
+
+// defaultNanochatSpec reproduces ModelManager's original hardcoded file
+// list, so NewModelManager without WithModelSpec behaves exactly as it
+// did before ModelSpec existed.
+func defaultNanochatSpec() ModelSpec {
+	return ModelSpec{
+		ID:     "nanochat-650",
+		Alias:  "nanochat",
+		Source: huggingFaceURL,
+		Files: []ModelFile{
+			{Name: nanoModelFile, MinSize: 1000000000},
+			{Name: nanoMetaFile, MinSize: 100},
+			{Name: nanoTokenizerFile, MinSize: 100000},
+			{Name: nanoTokenBytesFile, MinSize: 100000, Optional: true},
+		},
+	}
+}
+
+// requiredFiles returns spec's non-optional file names.
+func (spec ModelSpec) requiredFiles() []string {
+	var names []string
+	for _, f := range spec.Files {
+		if !f.Optional {
+			names = append(names, f.Name)
+		}
+	}
+	return names
+}
+
+// allFiles returns every file name in spec, optional or not.
+func (spec ModelSpec) allFiles() []string {
+	names := make([]string, len(spec.Files))
+	for i, f := range spec.Files {
+		names[i] = f.Name
+	}
+	return names
+}
+
+// ModelManager handles downloading and caching nanochat model files. It
+// tries each configured ModelSource in turn, so operators can mirror the
+// weights to their own object store (S3, GCS, a local directory for
+// air-gapped installs, ...) instead of patching the hardcoded Hugging
+// Face default.
 type ModelManager struct {
 	cacheDir string
-	client   *http.Client
 	mu       sync.Mutex
+
+	sources      []ModelSource
+	chunks       int
+	maxRetries   int
+	checksumMode ChecksumMode
+	progress     ProgressReporter
+	spec         ModelSpec
 }
 
-// NewModelManager creates a new model manager with the given cache directory
-func NewModelManager(cacheDir string) *ModelManager {
-	return &ModelManager{
-		cacheDir: cacheDir,
-		client: &http.Client{
-			Timeout: 5 * time.Minute,
-		},
+// ModelManagerOption configures optional ModelManager behavior; see
+// WithSources, WithChunks, WithMaxRetries, and WithChecksumMode.
+type ModelManagerOption func(*ModelManager)
+
+// WithSources overrides the default Hugging Face source with sources,
+// tried in order. A file is fetched from the first source whose Stat
+// succeeds; if every source returns ErrSourceObjectNotFound or another
+// error, the download fails.
+func WithSources(sources ...ModelSource) ModelManagerOption {
+	return func(mm *ModelManager) { mm.sources = sources }
+}
+
+// WithChunks sets how many concurrent byte-range requests a fresh download
+// is split into. Values <= 1 disable chunking.
+func WithChunks(n int) ModelManagerOption {
+	return func(mm *ModelManager) { mm.chunks = n }
+}
+
+// WithMaxRetries sets how many extra attempts a failed chunk gets before
+// the whole download fails.
+func WithMaxRetries(n int) ModelManagerOption {
+	return func(mm *ModelManager) { mm.maxRetries = n }
+}
+
+// WithChecksumMode sets whether a missing manifest entry is tolerated
+// (ChecksumOptional, the default) or fatal (ChecksumRequired).
+func WithChecksumMode(mode ChecksumMode) ModelManagerOption {
+	return func(mm *ModelManager) { mm.checksumMode = mode }
+}
+
+// WithProgressReporter sets where downloadFile reports per-file progress.
+// Without this option, ModelManager reports to nothing.
+func WithProgressReporter(reporter ProgressReporter) ModelManagerOption {
+	return func(mm *ModelManager) { mm.progress = reporter }
+}
+
+// WithModelSpec overrides the file list ModelManager downloads and
+// caches. Without this option, ModelManager fetches the original
+// hardcoded nanochat-650 checkpoint (defaultNanochatSpec), preserving
+// behavior for callers that predate ModelSpec; ModelRegistry always
+// supplies one explicitly.
+func WithModelSpec(spec ModelSpec) ModelManagerOption {
+	return func(mm *ModelManager) { mm.spec = spec }
+}
+
+// NewModelManager creates a new model manager with the given cache
+// directory. Without WithSources, it fetches from the Hugging Face
+// repository the nanochat checkpoint is published under.
+func NewModelManager(cacheDir string, opts ...ModelManagerOption) *ModelManager {
+	mm := &ModelManager{
+		cacheDir:   cacheDir,
+		sources:    []ModelSource{NewHTTPModelSource(huggingFaceURL, &http.Client{Timeout: 5 * time.Minute})},
+		chunks:     defaultChunks,
+		maxRetries: defaultMaxRetries,
+		progress:   noopProgressReporter{},
+		spec:       defaultNanochatSpec(),
 	}
+	for _, opt := range opts {
+		opt(mm)
+	}
+	return mm
 }
 
 // EnsureModel ensures all required model files are present in cache
@@ -56,7 +222,7 @@ func (mm *ModelManager) EnsureModel() error {
 	log.Printf("[ModelManager] Cache directory: %s", mm.cacheDir)
 
 	// Check which files need to be downloaded
-	requiredFiles := []string{nanoModelFile, nanoMetaFile, nanoTokenizerFile}
+	requiredFiles := mm.spec.requiredFiles()
 	filesToDownload := []string{}
 
 	for _, file := range requiredFiles {
@@ -76,8 +242,13 @@ func (mm *ModelManager) EnsureModel() error {
 
 	log.Printf("[ModelManager] Downloading %d missing file(s)...", len(filesToDownload))
 
+	mf, err := mm.fetchManifest()
+	if err != nil {
+		return fmt.Errorf("failed to fetch manifest: %w", err)
+	}
+
 	for _, file := range filesToDownload {
-		if err := mm.downloadFile(file); err != nil {
+		if err := mm.downloadFile(file, mf); err != nil {
 			return fmt.Errorf("failed to download %s: %w", file, err)
 		}
 	}
@@ -105,7 +276,7 @@ func (mm *ModelManager) ModelPath() string {
 
 // ModelExists checks if all required model files exist
 func (mm *ModelManager) ModelExists() bool {
-	requiredFiles := []string{nanoModelFile, nanoMetaFile, nanoTokenizerFile}
+	requiredFiles := mm.spec.requiredFiles()
 
 	for _, file := range requiredFiles {
 		path := filepath.Join(mm.cacheDir, file)
@@ -121,8 +292,7 @@ func (mm *ModelManager) ModelExists() bool {
 func (mm *ModelManager) CacheSize() (int64, error) {
 	var totalSize int64
 
-	files := []string{nanoModelFile, nanoMetaFile, nanoTokenizerFile, nanoTokenBytesFile}
-	for _, file := range files {
+	for _, file := range mm.spec.allFiles() {
 		path := filepath.Join(mm.cacheDir, file)
 		info, err := os.Stat(path)
 		if err != nil {
@@ -137,102 +307,301 @@ func (mm *ModelManager) CacheSize() (int64, error) {
 	return totalSize, nil
 }
 
-// downloadFile downloads a single file from Hugging Face
-func (mm *ModelManager) downloadFile(filename string) error {
-	url := fmt.Sprintf("%s/%s", huggingFaceURL, filename)
+// fetchManifest fetches and parses manifest.json from the first
+// configured source that has one. No source having one is not an error:
+// it just means no file in this download has a known checksum, which
+// downloadFile treats according to mm.checksumMode.
+func (mm *ModelManager) fetchManifest() (*manifest, error) {
+	for _, src := range mm.sources {
+		rc, err := src.Open(context.Background(), manifestFile, 0)
+		if errors.Is(err, ErrSourceObjectNotFound) {
+			continue
+		}
+		if err != nil {
+			log.Printf("[ModelManager] failed to fetch %s from %s: %v", manifestFile, src.URL(manifestFile), err)
+			continue
+		}
+
+		var mf manifest
+		decodeErr := json.NewDecoder(rc).Decode(&mf)
+		rc.Close()
+		if decodeErr != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", manifestFile, decodeErr)
+		}
+		return &mf, nil
+	}
+	return nil, nil
+}
+
+// checksumFor looks up filename's expected sha256 in mf, honoring
+// mm.checksumMode when it isn't listed.
+func (mm *ModelManager) checksumFor(mf *manifest, filename string) (string, error) {
+	if mf != nil {
+		if entry, ok := mf.Files[filename]; ok && entry.SHA256 != "" {
+			return entry.SHA256, nil
+		}
+	}
+	if mm.checksumMode == ChecksumRequired {
+		return "", fmt.Errorf("no manifest checksum for %s", filename)
+	}
+	return "", nil
+}
+
+// downloadFile downloads a single file into <file>.part, trying each of
+// mm.sources in order until one succeeds. Resuming a previous partial
+// download happens via a single Range request when possible; otherwise
+// the download is split into mm.chunks concurrent byte-range requests.
+// Once the file is fully assembled it's verified against mf (if a
+// checksum is available) and only then renamed into place, so a corrupt
+// or partial file never satisfies ModelExists.
+func (mm *ModelManager) downloadFile(filename string, mf *manifest) error {
 	path := filepath.Join(mm.cacheDir, filename)
+	partPath := path + ".part"
 
 	log.Printf("[ModelManager] Downloading: %s", filename)
 
-	// Create HTTP request with timeout
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
 	defer cancel()
 
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	var lastErr error
+	var tracker *progressTracker
+	for _, src := range mm.sources {
+		obj, err := src.Stat(ctx, filename)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		tracker = newProgressTracker(mm.progress, filename, obj.Size)
+		if err := mm.fetchIntoPart(ctx, src, filename, partPath, obj.Size, tracker); err != nil {
+			lastErr = err
+			tracker.fail(err)
+			continue
+		}
+		lastErr = nil
+		break
+	}
+	if lastErr != nil {
+		return fmt.Errorf("all sources failed for %s: %w", filename, lastErr)
+	}
+	tracker.done()
+
+	expectedSHA256, err := mm.checksumFor(mf, filename)
 	if err != nil {
-		return fmt.Errorf("failed to create request: %w", err)
+		os.Remove(partPath)
+		return err
+	}
+	if expectedSHA256 != "" {
+		if err := verifyFileSHA256(partPath, expectedSHA256); err != nil {
+			os.Remove(partPath)
+			return fmt.Errorf("%s failed checksum verification: %w", filename, err)
+		}
+	}
+
+	if err := os.Rename(partPath, path); err != nil {
+		return fmt.Errorf("failed to finalize %s: %w", filename, err)
 	}
 
-	// Execute request
-	resp, err := mm.client.Do(req)
+	log.Printf("[ModelManager] ✓ Downloaded: %s", filename)
+	return nil
+}
+
+// fetchIntoPart downloads filename from src into partPath, resuming an
+// existing partial file with a single ranged Open when possible, or
+// splitting a fresh download into mm.chunks concurrent range requests
+// otherwise.
+func (mm *ModelManager) fetchIntoPart(ctx context.Context, src ModelSource, filename, partPath string, totalSize int64, tracker *progressTracker) error {
+	if existing, err := os.Stat(partPath); err == nil {
+		if totalSize > 0 && existing.Size() < totalSize {
+			log.Printf("[ModelManager] Resuming %s from byte %d", filepath.Base(partPath), existing.Size())
+			tracker.add(existing.Size())
+			if err := mm.resumeRange(ctx, src, filename, partPath, existing.Size(), tracker); err == nil {
+				return nil
+			}
+			log.Printf("[ModelManager] resume failed; restarting %s from scratch", filepath.Base(partPath))
+		}
+		if err := os.Remove(partPath); err != nil {
+			return fmt.Errorf("failed to remove stale partial download: %w", err)
+		}
+	}
+
+	return mm.downloadChunked(ctx, src, filename, partPath, totalSize, tracker)
+}
+
+// downloadChunked splits [0, totalSize) across mm.chunks concurrent range
+// requests (or a single request for the whole file if chunking isn't
+// possible) and
+// writes each into its own offset of partPath via WriteAt.
+func (mm *ModelManager) downloadChunked(ctx context.Context, src ModelSource, filename, partPath string, totalSize int64, tracker *progressTracker) error {
+	out, err := os.Create(partPath)
 	if err != nil {
-		return fmt.Errorf("download request failed: %w", err)
+		return fmt.Errorf("failed to create %s: %w", partPath, err)
 	}
-	defer resp.Body.Close()
+	defer out.Close()
+
+	n := mm.chunks
+	if n < 1 || totalSize <= 0 {
+		n = 1
+	}
+	if totalSize > 0 {
+		if err := out.Truncate(totalSize); err != nil {
+			return fmt.Errorf("failed to preallocate %s: %w", partPath, err)
+		}
+	}
+
+	ranges := splitByteRanges(totalSize, n)
 
-	// Check response status
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, url)
+	var wg sync.WaitGroup
+	errs := make([]error, len(ranges))
+	for i, r := range ranges {
+		wg.Add(1)
+		go func(i int, r byteRange) {
+			defer wg.Done()
+			errs[i] = mm.downloadRangeWithRetry(ctx, src, filename, out, r, tracker)
+		}(i, r)
 	}
+	wg.Wait()
 
-	// Create output file
-	out, err := os.Create(path)
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resumeRange appends bytes starting at start to the existing partPath
+// via a single ranged Open.
+func (mm *ModelManager) resumeRange(ctx context.Context, src ModelSource, filename, partPath string, start int64, tracker *progressTracker) error {
+	out, err := os.OpenFile(partPath, os.O_WRONLY, 0644)
 	if err != nil {
-		return fmt.Errorf("failed to create file: %w", err)
+		return fmt.Errorf("failed to open %s for resume: %w", partPath, err)
 	}
 	defer out.Close()
 
-	// Copy with progress tracking
-	return mm.copyWithProgress(out, resp.Body, filename, resp.ContentLength)
+	return mm.downloadRangeWithRetry(ctx, src, filename, out, byteRange{start: start, end: -1}, tracker)
 }
 
-// copyWithProgress copies data and logs progress
-func (mm *ModelManager) copyWithProgress(dst io.Writer, src io.Reader, filename string, totalSize int64) error {
-	const chunkSize = 1024 * 1024 // 1MB
+// byteRange is an inclusive [start, end] span of a file, fetched by a
+// single ranged Open and written at the matching offset. end < 0 means
+// "to the end of the file" (an unranged request).
+type byteRange struct {
+	start, end int64
+}
 
-	buf := make([]byte, chunkSize)
-	var written int64
+// splitByteRanges divides [0, totalSize) into n roughly equal inclusive
+// byte ranges. If totalSize is unknown (<= 0) or n <= 1, it returns a
+// single range for the whole file, which is unranged (end < 0) only when
+// totalSize is unknown.
+func splitByteRanges(totalSize int64, n int) []byteRange {
+	if n <= 1 || totalSize <= 0 {
+		return []byteRange{{start: 0, end: totalSize - 1}}
+	}
 
-	for {
-		n, err := src.Read(buf)
-		if n > 0 {
-			if _, err := dst.Write(buf[:n]); err != nil {
-				return fmt.Errorf("write error: %w", err)
-			}
-			written += int64(n)
+	chunkSize := totalSize / int64(n)
+	ranges := make([]byteRange, 0, n)
+	start := int64(0)
+	for i := 0; i < n; i++ {
+		end := start + chunkSize - 1
+		if i == n-1 {
+			end = totalSize - 1
+		}
+		ranges = append(ranges, byteRange{start: start, end: end})
+		start = end + 1
+	}
+	return ranges
+}
 
-			// Log progress every 10MB
-			if totalSize > 0 && written%chunkSize == 0 {
-				percent := (written * 100) / totalSize
-				log.Printf("[ModelManager] %s: %d%%", filename, percent)
+// downloadRangeWithRetry downloads r into out at the matching offset,
+// retrying with exponential backoff up to mm.maxRetries times.
+func (mm *ModelManager) downloadRangeWithRetry(ctx context.Context, src ModelSource, filename string, out *os.File, r byteRange, tracker *progressTracker) error {
+	var lastErr error
+	backoff := time.Second
+	for attempt := 0; attempt <= mm.maxRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("[ModelManager] retrying range %d-%d (attempt %d/%d) after %v", r.start, r.end, attempt+1, mm.maxRetries+1, backoff)
+			time.Sleep(backoff)
+			backoff *= 2
+			if backoff > 30*time.Second {
+				backoff = 30 * time.Second
 			}
 		}
 
-		if err != nil {
-			if err == io.EOF {
-				break
-			}
-			return fmt.Errorf("read error: %w", err)
+		if err := mm.downloadRange(ctx, src, filename, out, r, tracker); err != nil {
+			lastErr = err
+			continue
 		}
+		return nil
+	}
+	return fmt.Errorf("range %d-%d failed after %d attempt(s): %w", r.start, r.end, mm.maxRetries+1, lastErr)
+}
+
+// downloadRange opens filename at r.start and writes up to r.end-r.start+1
+// bytes (or until EOF, for an unranged request) into out starting at
+// r.start.
+func (mm *ModelManager) downloadRange(ctx context.Context, src ModelSource, filename string, out *os.File, r byteRange, tracker *progressTracker) error {
+	rc, err := src.Open(ctx, filename, r.start)
+	if err != nil {
+		return fmt.Errorf("open failed: %w", err)
+	}
+	defer rc.Close()
+
+	var body io.Reader = rc
+	expected := int64(-1)
+	if r.end >= 0 {
+		expected = r.end - r.start + 1
+		body = io.LimitReader(rc, expected)
+	}
+
+	dst := io.Writer(io.NewOffsetWriter(out, r.start))
+	if tracker != nil {
+		dst = &progressCountingWriter{dst: dst, tracker: tracker}
+	}
+	written, err := io.Copy(dst, body)
+	if err != nil {
+		return fmt.Errorf("write error: %w", err)
 	}
 
-	log.Printf("[ModelManager] ✓ Downloaded: %s (%d bytes)", filename, written)
+	if expected >= 0 && written != expected {
+		return fmt.Errorf("short read: got %d bytes, want %d", written, expected)
+	}
+	return nil
+}
+
+// verifyFileSHA256 streams filePath through sha256.New() and compares the
+// digest against expectedHash.
+func verifyFileSHA256(filePath, expectedHash string) error {
+	f, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(h.Sum(nil)); actual != expectedHash {
+		return fmt.Errorf("checksum mismatch: got %s, want %s", actual, expectedHash)
+	}
 	return nil
 }
 
 // VerifyIntegrity checks if downloaded files match expected properties
 // (basic size checks without checksums)
 func (mm *ModelManager) VerifyIntegrity() error {
-	minSizes := map[string]int64{
-		nanoModelFile:      1000000000, // ~1GB minimum for model
-		nanoMetaFile:       100,        // ~1KB for metadata
-		nanoTokenizerFile:  100000,     // ~100KB for tokenizer
-		nanoTokenBytesFile: 100000,     // ~100KB for token bytes (optional)
-	}
-
-	for file, minSize := range minSizes {
-		path := filepath.Join(mm.cacheDir, file)
+	for _, f := range mm.spec.Files {
+		path := filepath.Join(mm.cacheDir, f.Name)
 		info, err := os.Stat(path)
 		if err != nil {
-			if os.IsNotExist(err) && file == nanoTokenBytesFile {
-				// token_bytes.pt is optional
+			if os.IsNotExist(err) && f.Optional {
 				continue
 			}
-			return fmt.Errorf("%s missing or unreadable: %w", file, err)
+			return fmt.Errorf("%s missing or unreadable: %w", f.Name, err)
 		}
 
-		if info.Size() < minSize {
-			return fmt.Errorf("%s seems truncated (size: %d)", file, info.Size())
+		if f.MinSize > 0 && info.Size() < f.MinSize {
+			return fmt.Errorf("%s seems truncated (size: %d)", f.Name, info.Size())
 		}
 	}
 
@@ -241,9 +610,7 @@ func (mm *ModelManager) VerifyIntegrity() error {
 
 // Clean removes cached model files
 func (mm *ModelManager) Clean() error {
-	files := []string{nanoModelFile, nanoMetaFile, nanoTokenizerFile, nanoTokenBytesFile}
-
-	for _, file := range files {
+	for _, file := range mm.spec.allFiles() {
 		path := filepath.Join(mm.cacheDir, file)
 		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
 			return fmt.Errorf("failed to remove %s: %w", file, err)
@@ -0,0 +1,247 @@
+package nanochat
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+// rangeServingHandler serves content from a fixed byte slice, honoring
+// HEAD, Range requests, and an optional manifest.json body.
+func rangeServingHandler(t *testing.T, content []byte, manifestBody string) http.HandlerFunc {
+	t.Helper()
+	return func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, manifestFile) {
+			if manifestBody == "" {
+				http.NotFound(w, r)
+				return
+			}
+			w.Write([]byte(manifestBody))
+			return
+		}
+
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		rangeHeader := r.Header.Get("Range")
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			return
+		}
+		if rangeHeader == "" {
+			w.Write(content)
+			return
+		}
+
+		var start int
+		if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start); err != nil {
+			http.Error(w, "bad range", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start:])
+	}
+}
+
+func testContent(n int) []byte {
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = byte(i % 251)
+	}
+	return b
+}
+
+func sha256Hex(b []byte) string {
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestDownloadFileChunkedAssemblesCorrectly(t *testing.T) {
+	content := testContent(10_000)
+	manifestJSON := fmt.Sprintf(`{"files":{%q:{"sha256":%q}}}`, nanoMetaFile, sha256Hex(content))
+
+	server := httptest.NewServer(rangeServingHandler(t, content, manifestJSON))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	mm := NewModelManager(cacheDir, WithChunks(4), WithSources(NewHTTPModelSource(server.URL, nil)))
+
+	mf, err := mm.fetchManifest()
+	if err != nil {
+		t.Fatalf("fetchManifest: %v", err)
+	}
+
+	if err := mm.downloadFile(nanoMetaFile, mf); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(cacheDir, nanoMetaFile))
+	if err != nil {
+		t.Fatalf("reading downloaded file: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Error("downloaded content doesn't match source")
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, nanoMetaFile+".part")); !os.IsNotExist(err) {
+		t.Error("expected .part file to be renamed away after success")
+	}
+}
+
+func TestDownloadFileResumesFromPartialFile(t *testing.T) {
+	content := testContent(5_000)
+	server := httptest.NewServer(rangeServingHandler(t, content, ""))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	mm := NewModelManager(cacheDir, WithSources(NewHTTPModelSource(server.URL, nil)))
+
+	partPath := filepath.Join(cacheDir, nanoMetaFile+".part")
+	if err := os.WriteFile(partPath, content[:2000], 0644); err != nil {
+		t.Fatalf("seeding partial file: %v", err)
+	}
+
+	if err := mm.downloadFile(nanoMetaFile, nil); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(cacheDir, nanoMetaFile))
+	if err != nil {
+		t.Fatalf("reading downloaded file: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Error("resumed download doesn't match source")
+	}
+}
+
+func TestDownloadFileChecksumMismatchDeletesPartialAndErrors(t *testing.T) {
+	content := testContent(1_000)
+	manifestJSON := fmt.Sprintf(`{"files":{%q:{"sha256":"%s"}}}`, nanoMetaFile, strings.Repeat("0", 64))
+
+	server := httptest.NewServer(rangeServingHandler(t, content, manifestJSON))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	mm := NewModelManager(cacheDir, WithSources(NewHTTPModelSource(server.URL, nil)))
+
+	mf, err := mm.fetchManifest()
+	if err != nil {
+		t.Fatalf("fetchManifest: %v", err)
+	}
+
+	if err := mm.downloadFile(nanoMetaFile, mf); err == nil {
+		t.Fatal("expected checksum mismatch error")
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheDir, nanoMetaFile)); !os.IsNotExist(err) {
+		t.Error("final file should not exist after checksum failure")
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, nanoMetaFile+".part")); !os.IsNotExist(err) {
+		t.Error("corrupt .part file should have been deleted")
+	}
+}
+
+func TestDownloadFileChecksumRequiredWithoutManifestEntryFails(t *testing.T) {
+	content := testContent(500)
+	server := httptest.NewServer(rangeServingHandler(t, content, ""))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	mm := NewModelManager(cacheDir, WithChecksumMode(ChecksumRequired), WithSources(NewHTTPModelSource(server.URL, nil)))
+
+	if err := mm.downloadFile(nanoMetaFile, nil); err == nil {
+		t.Fatal("expected error when no manifest checksum is available under ChecksumRequired")
+	}
+}
+
+func TestSplitByteRangesCoversWholeFileExactlyOnce(t *testing.T) {
+	totalSize := int64(10_007)
+	ranges := splitByteRanges(totalSize, 4)
+
+	var covered int64
+	for i, r := range ranges {
+		if i > 0 && r.start != ranges[i-1].end+1 {
+			t.Fatalf("range %d doesn't start where the previous one ended: %+v", i, ranges)
+		}
+		covered += r.end - r.start + 1
+	}
+	if covered != totalSize {
+		t.Errorf("ranges cover %d bytes, want %d", covered, totalSize)
+	}
+	if ranges[len(ranges)-1].end != totalSize-1 {
+		t.Errorf("last range should end at %d, got %d", totalSize-1, ranges[len(ranges)-1].end)
+	}
+}
+
+func TestDownloadFileRetriesAfterMidStreamDisconnect(t *testing.T) {
+	content := testContent(4_000)
+	var firstChunkAttempts int32
+
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, manifestFile) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Accept-Ranges", "bytes")
+
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			return
+		}
+
+		var start int
+		if _, err := fmt.Sscanf(r.Header.Get("Range"), "bytes=%d-", &start); err != nil {
+			http.Error(w, "bad range", http.StatusBadRequest)
+			return
+		}
+
+		if start == 0 && atomic.AddInt32(&firstChunkAttempts, 1) == 1 {
+			// Simulate a disconnect partway through the first chunk: write
+			// half the bytes, then close the connection without the rest.
+			w.WriteHeader(http.StatusPartialContent)
+			w.Write(content[start : start+(len(content)-start)/8])
+			if hj, ok := w.(http.Hijacker); ok {
+				conn, _, _ := hj.Hijack()
+				conn.Close()
+			}
+			return
+		}
+
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start:])
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(handler))
+	defer server.Close()
+
+	cacheDir := t.TempDir()
+	mm := NewModelManager(cacheDir, WithChunks(4), WithMaxRetries(2), WithSources(NewHTTPModelSource(server.URL, nil)))
+
+	if err := mm.downloadFile(nanoMetaFile, nil); err != nil {
+		t.Fatalf("downloadFile: %v", err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(cacheDir, nanoMetaFile))
+	if err != nil {
+		t.Fatalf("reading downloaded file: %v", err)
+	}
+	if string(got) != string(content) {
+		t.Error("downloaded content doesn't match source after retry")
+	}
+	if attempts := atomic.LoadInt32(&firstChunkAttempts); attempts < 2 {
+		t.Errorf("expected at least 2 attempts at the first chunk, got %d", attempts)
+	}
+}
+
+func TestSplitByteRangesUnknownSizeReturnsSingleRange(t *testing.T) {
+	ranges := splitByteRanges(0, 4)
+	if len(ranges) != 1 {
+		t.Fatalf("expected a single unranged request, got %d", len(ranges))
+	}
+}
@@ -0,0 +1,153 @@
+package nanochat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrSourceObjectNotFound is returned by ModelSource.Stat and
+// ModelSource.Open when the named file doesn't exist at that source, so
+// ModelManager can move on to the next configured source instead of
+// treating a missing object as fatal.
+var ErrSourceObjectNotFound = errors.New("nanochat: object not found at source")
+
+// Object describes a file a ModelSource can serve, as reported by Stat.
+type Object struct {
+	// Size is the file's length in bytes, or -1 if the source can't
+	// report it up front (in which case ModelManager falls back to an
+	// unranged, single-stream download).
+	Size int64
+	// ETag is an opaque, source-specific identifier for this version of
+	// the file (an HTTP ETag, an S3 object ETag, a GCS generation, ...).
+	// It's informational only; ModelManager verifies content via
+	// manifest.json checksums, not ETag.
+	ETag string
+}
+
+// ModelSource is a place model files can be fetched from: the Hugging
+// Face HTTP endpoint by default, or an operator-mirrored S3/GCS bucket or
+// local directory for air-gapped installs. ModelManager tries each
+// configured source in order, falling through to the next on
+// ErrSourceObjectNotFound or any other error.
+//
+// Open must support an arbitrary starting offset, mapping it onto
+// whatever the backend's native mechanism is (an HTTP Range header, S3's
+// Range parameter, GCS's NewRangeReader, os.File.Seek, ...) so
+// ModelManager can resume a partial download or split a fresh one into
+// concurrent byte-range requests.
+type ModelSource interface {
+	// Stat reports name's size (and ETag, if the backend has one)
+	// without downloading it. It returns ErrSourceObjectNotFound if name
+	// doesn't exist at this source.
+	Stat(ctx context.Context, name string) (Object, error)
+	// Open returns a reader over name's contents starting at offset. It
+	// returns ErrSourceObjectNotFound if name doesn't exist at this
+	// source.
+	Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error)
+	// URL returns a human-readable locator for name, for logging.
+	URL(name string) string
+}
+
+// httpModelSource serves model files from a plain HTTP(S) endpoint, such
+// as the Hugging Face repo ModelManager defaults to.
+type httpModelSource struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPModelSource returns a ModelSource that fetches files from
+// baseURL via plain HTTP GET/HEAD, always requesting a Range so a resumed
+// download (offset > 0) and a fresh one (offset == 0) go through the same
+// code path. baseURL is joined with name as "baseURL/name".
+func NewHTTPModelSource(baseURL string, client *http.Client) ModelSource {
+	if client == nil {
+		client = &http.Client{Timeout: 5 * time.Minute}
+	}
+	return &httpModelSource{baseURL: strings.TrimSuffix(baseURL, "/"), client: client}
+}
+
+func (s *httpModelSource) URL(name string) string {
+	return s.baseURL + "/" + name
+}
+
+func (s *httpModelSource) Stat(ctx context.Context, name string) (Object, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, s.URL(name), nil)
+	if err != nil {
+		return Object{}, err
+	}
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return Object{}, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return Object{}, ErrSourceObjectNotFound
+	}
+	if resp.StatusCode != http.StatusOK {
+		return Object{}, fmt.Errorf("HTTP %d: %s", resp.StatusCode, s.URL(name))
+	}
+	return Object{Size: resp.ContentLength, ETag: resp.Header.Get("ETag")}, nil
+}
+
+func (s *httpModelSource) Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL(name), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrSourceObjectNotFound
+	}
+	if offset > 0 && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server doesn't support resuming %s from byte %d (status %d)", name, offset, resp.StatusCode)
+	}
+	if offset == 0 && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusPartialContent {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %d fetching %s", resp.StatusCode, name)
+	}
+	return resp.Body, nil
+}
+
+// ModelSourceFromURI builds a ModelSource from a URI, dispatching on its
+// scheme:
+//
+//	https://host/path  -> HTTP source rooted at the URI
+//	s3://bucket/prefix  -> S3 source, optionally overriding the endpoint
+//	                       via the AWS_ENDPOINT_URL environment variable
+//	                       (for MinIO and other S3-compatible stores)
+//	gs://bucket/prefix  -> GCS source
+//	file:///local/path  -> local-directory source, for air-gapped installs
+func ModelSourceFromURI(uri string) (ModelSource, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source URI %q: %w", uri, err)
+	}
+
+	switch u.Scheme {
+	case "http", "https":
+		return NewHTTPModelSource(uri, nil), nil
+	case "s3":
+		return newS3ModelSource(u.Host, strings.TrimPrefix(u.Path, "/"))
+	case "gs":
+		return newGCSModelSource(u.Host, strings.TrimPrefix(u.Path, "/"))
+	case "file":
+		return NewFileModelSource(u.Path), nil
+	default:
+		return nil, fmt.Errorf("unsupported model source scheme %q in %q", u.Scheme, uri)
+	}
+}
@@ -0,0 +1,52 @@
+package nanochat
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// fileModelSource serves model files from a local directory, for
+// air-gapped installs where the weights are copied onto the host ahead
+// of time rather than fetched over the network.
+type fileModelSource struct {
+	dir string
+}
+
+// NewFileModelSource returns a ModelSource that reads files out of dir.
+func NewFileModelSource(dir string) ModelSource {
+	return &fileModelSource{dir: dir}
+}
+
+func (s *fileModelSource) URL(name string) string {
+	return "file://" + filepath.Join(s.dir, name)
+}
+
+func (s *fileModelSource) Stat(ctx context.Context, name string) (Object, error) {
+	info, err := os.Stat(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return Object{}, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Size: info.Size()}, nil
+}
+
+func (s *fileModelSource) Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error) {
+	f, err := os.Open(filepath.Join(s.dir, name))
+	if os.IsNotExist(err) {
+		return nil, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if offset > 0 {
+		if _, err := f.Seek(offset, io.SeekStart); err != nil {
+			f.Close()
+			return nil, err
+		}
+	}
+	return f, nil
+}
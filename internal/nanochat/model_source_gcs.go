@@ -0,0 +1,81 @@
+package nanochat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"cloud.google.com/go/storage"
+	"google.golang.org/api/option"
+)
+
+// gcsModelSource serves model files from a Google Cloud Storage bucket,
+// rooted at prefix.
+type gcsModelSource struct {
+	client *storage.Client
+	bucket string
+	prefix string
+}
+
+// newGCSModelSource builds a GCS-backed ModelSource for bucket/prefix,
+// using the standard Application Default Credentials chain.
+func newGCSModelSource(bucket, prefix string) (ModelSource, error) {
+	client, err := storage.NewClient(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &gcsModelSource{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+// newGCSModelSourceWithHTTPClient is used by tests to point the GCS
+// client at a local fake server via option.WithHTTPClient instead of
+// talking to real GCS.
+func newGCSModelSourceWithHTTPClient(ctx context.Context, httpClient *http.Client, endpoint, bucket, prefix string) (ModelSource, error) {
+	client, err := storage.NewClient(ctx,
+		option.WithHTTPClient(httpClient),
+		option.WithEndpoint(endpoint),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCS client: %w", err)
+	}
+	return &gcsModelSource{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+func (s *gcsModelSource) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+func (s *gcsModelSource) URL(name string) string {
+	return fmt.Sprintf("gs://%s/%s", s.bucket, s.key(name))
+}
+
+func (s *gcsModelSource) object(name string) *storage.ObjectHandle {
+	return s.client.Bucket(s.bucket).Object(s.key(name))
+}
+
+func (s *gcsModelSource) Stat(ctx context.Context, name string) (Object, error) {
+	attrs, err := s.object(name).Attrs(ctx)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return Object{}, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+	return Object{Size: attrs.Size, ETag: attrs.Etag}, nil
+}
+
+func (s *gcsModelSource) Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error) {
+	r, err := s.object(name).NewRangeReader(ctx, offset, -1)
+	if errors.Is(err, storage.ErrObjectNotExist) {
+		return nil, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return r, nil
+}
@@ -0,0 +1,120 @@
+package nanochat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// s3ModelSource serves model files from an S3 bucket (or an S3-compatible
+// store such as MinIO, via endpoint override), rooted at prefix.
+type s3ModelSource struct {
+	client *s3.Client
+	bucket string
+	prefix string
+}
+
+// newS3ModelSource builds an S3-backed ModelSource for bucket/prefix. The
+// endpoint is taken from the AWS_ENDPOINT_URL environment variable when
+// set, so operators can point this at a MinIO mirror instead of AWS; all
+// other client configuration (region, credentials) comes from the
+// standard AWS environment/config file chain.
+func newS3ModelSource(bucket, prefix string) (ModelSource, error) {
+	cfg, err := awsconfig.LoadDefaultConfig(context.Background())
+	if err != nil {
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+			o.BaseEndpoint = aws.String(endpoint)
+			o.UsePathStyle = true
+		}
+	})
+
+	return &s3ModelSource{client: client, bucket: bucket, prefix: prefix}, nil
+}
+
+// newS3ModelSourceWithClient is used by tests to inject a client pointed
+// at a local fake S3 server instead of going through newS3ModelSource's
+// environment-driven config.
+func newS3ModelSourceWithClient(client *s3.Client, bucket, prefix string) ModelSource {
+	return &s3ModelSource{client: client, bucket: bucket, prefix: prefix}
+}
+
+func (s *s3ModelSource) key(name string) string {
+	if s.prefix == "" {
+		return name
+	}
+	return s.prefix + "/" + name
+}
+
+func (s *s3ModelSource) URL(name string) string {
+	return fmt.Sprintf("s3://%s/%s", s.bucket, s.key(name))
+}
+
+func (s *s3ModelSource) Stat(ctx context.Context, name string) (Object, error) {
+	out, err := s.client.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	})
+	if isS3NotFound(err) {
+		return Object{}, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return Object{}, err
+	}
+
+	var size int64 = -1
+	if out.ContentLength != nil {
+		size = *out.ContentLength
+	}
+	return Object{Size: size, ETag: aws.ToString(out.ETag)}, nil
+}
+
+func (s *s3ModelSource) Open(ctx context.Context, name string, offset int64) (io.ReadCloser, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(s.bucket),
+		Key:    aws.String(s.key(name)),
+	}
+	if offset > 0 {
+		input.Range = aws.String(fmt.Sprintf("bytes=%d-", offset))
+	}
+
+	out, err := s.client.GetObject(ctx, input)
+	if isS3NotFound(err) {
+		return nil, ErrSourceObjectNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Body, nil
+}
+
+// isS3NotFound reports whether err is S3's "no such key" error, under
+// either of the shapes the SDK returns it as: a typed *types.NoSuchKey
+// from GetObject, or a generic smithy API error with code "NotFound" from
+// HeadObject (which doesn't distinguish a missing key from a missing
+// bucket in its typed errors).
+func isS3NotFound(err error) bool {
+	if err == nil {
+		return false
+	}
+	var noSuchKey *types.NoSuchKey
+	if errors.As(err, &noSuchKey) {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.ErrorCode() == "NotFound" || apiErr.ErrorCode() == "NoSuchKey"
+	}
+	return false
+}
@@ -0,0 +1,185 @@
+package nanochat
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestFileModelSourceStatAndOpen(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "weights.bin"), []byte("hello world"), 0644); err != nil {
+		t.Fatalf("seeding file: %v", err)
+	}
+
+	src := NewFileModelSource(dir)
+
+	obj, err := src.Stat(context.Background(), "weights.bin")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if obj.Size != 11 {
+		t.Errorf("Size = %d, want 11", obj.Size)
+	}
+
+	rc, err := src.Open(context.Background(), "weights.bin", 6)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+	buf := make([]byte, 5)
+	if _, err := rc.Read(buf); err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if string(buf) != "world" {
+		t.Errorf("read %q, want %q", buf, "world")
+	}
+}
+
+func TestFileModelSourceMissingFileReturnsNotFound(t *testing.T) {
+	src := NewFileModelSource(t.TempDir())
+
+	if _, err := src.Stat(context.Background(), "missing.bin"); !errors.Is(err, ErrSourceObjectNotFound) {
+		t.Errorf("Stat error = %v, want ErrSourceObjectNotFound", err)
+	}
+	if _, err := src.Open(context.Background(), "missing.bin", 0); !errors.Is(err, ErrSourceObjectNotFound) {
+		t.Errorf("Open error = %v, want ErrSourceObjectNotFound", err)
+	}
+}
+
+func TestModelSourceFromURI(t *testing.T) {
+	cases := []struct {
+		uri     string
+		wantErr bool
+	}{
+		{"https://example.com/models", false},
+		{"file:///tmp/models", false},
+		{"s3://my-bucket/prefix", false},
+		{"ftp://example.com/models", true},
+	}
+
+	for _, c := range cases {
+		_, err := ModelSourceFromURI(c.uri)
+		if (err != nil) != c.wantErr {
+			t.Errorf("ModelSourceFromURI(%q) error = %v, wantErr %v", c.uri, err, c.wantErr)
+		}
+	}
+}
+
+func TestModelSourceFromURIGCSScheme(t *testing.T) {
+	_, err := ModelSourceFromURI("gs://my-bucket/prefix")
+	if err != nil && strings.Contains(err.Error(), "could not find default credentials") {
+		t.Skip("no GCP credentials available in this environment")
+	}
+	if err != nil {
+		t.Fatalf("ModelSourceFromURI: %v", err)
+	}
+}
+
+func TestModelSourceFromURIHTTPRootsAtGivenURI(t *testing.T) {
+	src, err := ModelSourceFromURI("https://example.com/models")
+	if err != nil {
+		t.Fatalf("ModelSourceFromURI: %v", err)
+	}
+	if got, want := src.URL("meta.json"), "https://example.com/models/meta.json"; got != want {
+		t.Errorf("URL() = %q, want %q", got, want)
+	}
+}
+
+// fakeS3Handler serves a minimal subset of the S3 HTTP API needed to
+// exercise s3ModelSource: HEAD and ranged GET for one object, addressed
+// path-style as /bucket/key.
+func fakeS3Handler(t *testing.T, bucket, key string, content []byte) http.HandlerFunc {
+	t.Helper()
+	objectPath := "/" + bucket + "/" + key
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != objectPath {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<Error><Code>NoSuchKey</Code><Message>not found</Message></Error>`)
+			return
+		}
+
+		w.Header().Set("Accept-Ranges", "bytes")
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			return
+		}
+
+		rangeHeader := r.Header.Get("Range")
+		if rangeHeader == "" {
+			w.Write(content)
+			return
+		}
+
+		var start int
+		if _, err := fmt.Sscanf(rangeHeader, "bytes=%d-", &start); err != nil {
+			http.Error(w, "bad range", http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusPartialContent)
+		w.Write(content[start:])
+	}
+}
+
+func newTestS3Client(endpoint string) *s3.Client {
+	return s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(endpoint),
+		UsePathStyle: true,
+		Credentials:  credentials.NewStaticCredentialsProvider("test", "test", ""),
+	})
+}
+
+func TestS3ModelSourceStatAndOpen(t *testing.T) {
+	content := []byte(strings.Repeat("abc123", 500))
+	server := httptest.NewServer(fakeS3Handler(t, "models", "nanochat/weights.bin", content))
+	defer server.Close()
+
+	src := newS3ModelSourceWithClient(newTestS3Client(server.URL), "models", "nanochat")
+
+	obj, err := src.Stat(context.Background(), "weights.bin")
+	if err != nil {
+		t.Fatalf("Stat: %v", err)
+	}
+	if obj.Size != int64(len(content)) {
+		t.Errorf("Size = %d, want %d", obj.Size, len(content))
+	}
+
+	rc, err := src.Open(context.Background(), "weights.bin", 10)
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	defer rc.Close()
+
+	buf := make([]byte, len(content)-10)
+	if _, err := io.ReadFull(rc, buf); err != nil {
+		t.Fatalf("reading body: %v", err)
+	}
+	if string(buf) != string(content[10:]) {
+		t.Error("ranged body doesn't match source content")
+	}
+}
+
+func TestS3ModelSourceMissingKeyReturnsNotFound(t *testing.T) {
+	server := httptest.NewServer(fakeS3Handler(t, "models", "nanochat/weights.bin", []byte("x")))
+	defer server.Close()
+
+	src := newS3ModelSourceWithClient(newTestS3Client(server.URL), "models", "nanochat")
+
+	if _, err := src.Stat(context.Background(), "missing.bin"); !errors.Is(err, ErrSourceObjectNotFound) {
+		t.Errorf("Stat error = %v, want ErrSourceObjectNotFound", err)
+	}
+}
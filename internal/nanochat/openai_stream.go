@@ -0,0 +1,65 @@
+package nanochat
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// openAIChatRequest is the request body understood by the standard OpenAI
+// chat/completions streaming dialect, which llama.cpp's llama-server,
+// vLLM's api_server, and MLX's mlx_lm.server all speak.
+type openAIChatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []ChatMessage `json:"messages"`
+	Stream      bool          `json:"stream"`
+	Temperature *float32      `json:"temperature,omitempty"`
+	MaxTokens   *int          `json:"max_tokens,omitempty"`
+	TopK        *int          `json:"top_k,omitempty"`
+	// RequestID, when set, tells the server which id a later
+	// POST /cancel/{request_id} will use for this generation.
+	// PythonEngine.Chat always sets one; it is sent for every engine, and
+	// only inference_server.py acts on it.
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// streamOpenAIChat POSTs req to baseURL+"/v1/chat/completions" with
+// stream:true and relays the response as a ChatStream. LlamaServerEngine,
+// VLLMEngine, and MLXEngine share this instead of each reimplementing SSE
+// parsing, since they all proxy the same OpenAI-compatible wire format.
+func streamOpenAIChat(ctx context.Context, client *http.Client, baseURL string, req *ChatCompletionRequest) (*ChatStream, error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("no messages in request")
+	}
+
+	payload, err := json.Marshal(openAIChatRequest{
+		Messages:    req.Messages,
+		Stream:      true,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		TopK:        req.TopK,
+		RequestID:   req.RequestID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", baseURL+"/v1/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("server returned %d", resp.StatusCode)
+	}
+
+	return newChatStreamFromSSE(resp.Body), nil
+}
@@ -0,0 +1,196 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+)
+
+// ErrPoolSaturated is returned by EnginePool.Chat when more than
+// maxQueueLen Chat calls are dispatching at once.
+var ErrPoolSaturated = fmt.Errorf("nanochat: engine pool queue is full")
+
+// poolWorker pairs a PythonEngine with the bookkeeping EnginePool needs to
+// pick the least-busy healthy worker and report Stats.
+type poolWorker struct {
+	engine   *PythonEngine
+	inFlight int32
+	requests int64
+	started  time.Time
+}
+
+// EnginePool fans a Chat workload out across several PythonEngine
+// subprocesses, each on its own ephemeral port, so one slow or saturated
+// worker doesn't head-of-line-block every other in-flight stream the way a
+// single PythonEngine would.
+type EnginePool struct {
+	workers     []*poolWorker
+	maxQueueLen int
+	queueDepth  int32
+	draining    int32
+}
+
+// WorkerStats reports one pool worker's load.
+type WorkerStats struct {
+	URL         string
+	Running     bool
+	InFlight    int
+	RPS         float64
+	QueuedTotal int64
+}
+
+// NewEnginePool creates an EnginePool of size workers, each serving
+// modelDir. Ports are reserved up front by briefly listening on :0, then
+// released for the Python subprocess to bind to; maxQueueLen (0 means no
+// limit) bounds how many Chat calls may be dispatching to a worker at once
+// before Chat returns ErrPoolSaturated. Chat never waits for a free worker.
+func NewEnginePool(size int, modelDir string, maxQueueLen int) (*EnginePool, error) {
+	if size <= 0 {
+		return nil, fmt.Errorf("pool size must be positive, got %d", size)
+	}
+
+	workers := make([]*poolWorker, 0, size)
+	for i := 0; i < size; i++ {
+		port, err := reserveEphemeralPort()
+		if err != nil {
+			return nil, fmt.Errorf("failed to reserve port for worker %d: %w", i, err)
+		}
+		workers = append(workers, &poolWorker{engine: NewPythonEngineOnPort(modelDir, port)})
+	}
+
+	return &EnginePool{workers: workers, maxQueueLen: maxQueueLen}, nil
+}
+
+// reserveEphemeralPort asks the OS for a free port by briefly listening on
+// it, then closes the listener so the Python subprocess can bind it
+// instead. This is inherently racy (something else could grab the port in
+// between) but is the same best-effort approach net/http/httptest uses for
+// test servers.
+func reserveEphemeralPort() (int, error) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer l.Close()
+	return l.Addr().(*net.TCPAddr).Port, nil
+}
+
+// Start launches every worker's subprocess, writing each one's log to
+// logDir/python-engine-<n>.log when logDir is non-empty.
+func (p *EnginePool) Start(ctx context.Context, logDir string) error {
+	for i, w := range p.workers {
+		logPath := ""
+		if logDir != "" {
+			logPath = filepath.Join(logDir, fmt.Sprintf("python-engine-%d.log", i))
+		}
+		if err := w.engine.Start(ctx, logPath); err != nil {
+			return fmt.Errorf("failed to start pool worker %d: %w", i, err)
+		}
+		w.started = time.Now()
+	}
+	return nil
+}
+
+// Stop stops accepting new work, waits up to 10s for in-flight chats to
+// drain, and then stops every worker's subprocess.
+func (p *EnginePool) Stop() error {
+	atomic.StoreInt32(&p.draining, 1)
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) && p.totalInFlight() > 0 {
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	var firstErr error
+	for i, w := range p.workers {
+		if err := w.engine.Stop(); err != nil && firstErr == nil {
+			firstErr = fmt.Errorf("worker %d: %w", i, err)
+		}
+	}
+	return firstErr
+}
+
+// Chat routes req to the least-busy healthy worker. If more than
+// maxQueueLen Chat calls are already dispatching, it returns
+// ErrPoolSaturated instead of blocking; how busy the workers are does not
+// enter into that check.
+func (p *EnginePool) Chat(ctx context.Context, req *ChatCompletionRequest) (*ChatStream, error) {
+	if atomic.LoadInt32(&p.draining) != 0 {
+		return nil, fmt.Errorf("nanochat: engine pool is draining")
+	}
+
+	w := p.pickLeastBusy()
+	if w == nil {
+		return nil, ErrNoHealthyWorker
+	}
+
+	if p.maxQueueLen > 0 {
+		depth := atomic.AddInt32(&p.queueDepth, 1)
+		if int(depth) > p.maxQueueLen {
+			atomic.AddInt32(&p.queueDepth, -1)
+			return nil, ErrPoolSaturated
+		}
+		defer atomic.AddInt32(&p.queueDepth, -1)
+	}
+
+	atomic.AddInt32(&w.inFlight, 1)
+	atomic.AddInt64(&w.requests, 1)
+
+	stream, err := w.engine.Chat(ctx, req)
+	if err != nil {
+		atomic.AddInt32(&w.inFlight, -1)
+		return nil, err
+	}
+	return drainTracking(stream, func() { atomic.AddInt32(&w.inFlight, -1) }), nil
+}
+
+// ErrNoHealthyWorker is returned by Chat when no pool worker is currently
+// running.
+var ErrNoHealthyWorker = fmt.Errorf("nanochat: no healthy engine in pool")
+
+// pickLeastBusy returns the running worker with the fewest in-flight
+// chats, or nil if none are running.
+func (p *EnginePool) pickLeastBusy() *poolWorker {
+	var best *poolWorker
+	for _, w := range p.workers {
+		if !w.engine.IsRunning() {
+			continue
+		}
+		if best == nil || atomic.LoadInt32(&w.inFlight) < atomic.LoadInt32(&best.inFlight) {
+			best = w
+		}
+	}
+	return best
+}
+
+func (p *EnginePool) totalInFlight() int32 {
+	var total int32
+	for _, w := range p.workers {
+		total += atomic.LoadInt32(&w.inFlight)
+	}
+	return total
+}
+
+// Stats reports per-worker load: requests-per-second since the worker
+// started and its current queue depth (in-flight chats).
+func (p *EnginePool) Stats() []WorkerStats {
+	stats := make([]WorkerStats, 0, len(p.workers))
+	for _, w := range p.workers {
+		requests := atomic.LoadInt64(&w.requests)
+		var rps float64
+		if elapsed := time.Since(w.started).Seconds(); elapsed > 0 && !w.started.IsZero() {
+			rps = float64(requests) / elapsed
+		}
+		stats = append(stats, WorkerStats{
+			URL:         w.engine.URL(),
+			Running:     w.engine.IsRunning(),
+			InFlight:    int(atomic.LoadInt32(&w.inFlight)),
+			RPS:         rps,
+			QueuedTotal: requests,
+		})
+	}
+	return stats
+}
@@ -0,0 +1,54 @@
+package nanochat
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewEnginePoolRejectsNonPositiveSize(t *testing.T) {
+	if _, err := NewEnginePool(0, "/tmp/test-model", 10); err == nil {
+		t.Error("expected error for pool size 0")
+	}
+}
+
+func TestChatReturnsErrNoHealthyWorkerBeforeStart(t *testing.T) {
+	pool, err := NewEnginePool(2, "/tmp/test-model", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err = pool.Chat(context.Background(), &ChatCompletionRequest{
+		Messages: []ChatMessage{{Role: "user", Content: "hi"}},
+	})
+	if err != ErrNoHealthyWorker {
+		t.Errorf("expected ErrNoHealthyWorker, got %v", err)
+	}
+}
+
+func TestStatsReportsOneEntryPerWorker(t *testing.T) {
+	pool, err := NewEnginePool(3, "/tmp/test-model", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stats := pool.Stats()
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 worker stats, got %d", len(stats))
+	}
+	for _, s := range stats {
+		if s.Running {
+			t.Errorf("expected worker to be reported not running before Start, got %+v", s)
+		}
+	}
+}
+
+func TestPickLeastBusyReturnsNilWhenNoneRunning(t *testing.T) {
+	pool, err := NewEnginePool(2, "/tmp/test-model", 10)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if w := pool.pickLeastBusy(); w != nil {
+		t.Errorf("expected nil, got %+v", w)
+	}
+}
@@ -0,0 +1,220 @@
+package nanochat
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/schollz/progressbar/v3"
+)
+
+// progressReportInterval bounds how often a progressTracker recomputes its
+// transfer rate and calls ProgressReporter.OnProgress, so concurrent chunk
+// writers don't flood a reporter with a call per Write.
+const progressReportInterval = 250 * time.Millisecond
+
+// progressRateSmoothing weights each new instantaneous rate sample against
+// the running average, the same exponentially-weighted moving average a
+// terminal progress bar uses to keep its ETA from jittering between ticks.
+const progressRateSmoothing = 0.3
+
+// ProgressReporter observes a ModelManager download. EnsureModel calls
+// OnStart each time a source starts serving a file, OnProgress as bytes
+// arrive, OnError when that source's attempt fails (the next source, if
+// any, then calls OnStart again), and OnDone once the file is fetched.
+type ProgressReporter interface {
+	OnStart(file string, totalBytes int64)
+	OnProgress(file string, written, totalBytes int64, bytesPerSec float64)
+	OnDone(file string, written int64, elapsed time.Duration)
+	OnError(file string, err error)
+}
+
+// noopProgressReporter is the default ModelManager ProgressReporter: it
+// discards every event, so callers that don't care about progress pay
+// nothing beyond the tracker's atomic counter.
+type noopProgressReporter struct{}
+
+func (noopProgressReporter) OnStart(string, int64)                    {}
+func (noopProgressReporter) OnProgress(string, int64, int64, float64) {}
+func (noopProgressReporter) OnDone(string, int64, time.Duration)      {}
+func (noopProgressReporter) OnError(string, error)                    {}
+
+// progressTracker turns a stream of concurrent chunk writes into the
+// periodic, rate-smoothed OnProgress calls ProgressReporter expects. One
+// tracker is created per file in downloadFile and shared by every
+// downloadRange goroutine working on that file.
+type progressTracker struct {
+	reporter ProgressReporter
+	file     string
+	total    int64
+	start    time.Time
+
+	written int64 // atomic
+
+	mu        sync.Mutex
+	lastTick  time.Time
+	lastBytes int64
+	rate      float64
+}
+
+func newProgressTracker(reporter ProgressReporter, file string, total int64) *progressTracker {
+	now := time.Now()
+	reporter.OnStart(file, total)
+	return &progressTracker{reporter: reporter, file: file, total: total, start: now, lastTick: now}
+}
+
+// add records n more bytes written and, at most every progressReportInterval,
+// reports an updated transfer rate.
+func (t *progressTracker) add(n int64) {
+	written := atomic.AddInt64(&t.written, n)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := time.Now()
+	elapsed := now.Sub(t.lastTick)
+	if elapsed < progressReportInterval {
+		return
+	}
+
+	instant := float64(written-t.lastBytes) / elapsed.Seconds()
+	if t.rate == 0 {
+		t.rate = instant
+	} else {
+		t.rate = progressRateSmoothing*instant + (1-progressRateSmoothing)*t.rate
+	}
+	t.lastTick = now
+	t.lastBytes = written
+	t.reporter.OnProgress(t.file, written, t.total, t.rate)
+}
+
+func (t *progressTracker) done() {
+	t.reporter.OnDone(t.file, atomic.LoadInt64(&t.written), time.Since(t.start))
+}
+
+func (t *progressTracker) fail(err error) {
+	t.reporter.OnError(t.file, err)
+}
+
+// progressCountingWriter wraps a destination writer so every successful
+// Write is also reported to a progressTracker.
+type progressCountingWriter struct {
+	dst     io.Writer
+	tracker *progressTracker
+}
+
+func (w *progressCountingWriter) Write(p []byte) (int, error) {
+	n, err := w.dst.Write(p)
+	if n > 0 {
+		w.tracker.add(int64(n))
+	}
+	return n, err
+}
+
+// TerminalProgressReporter renders each file's progress as a
+// schollz/progressbar terminal bar: percentage, bytes transferred/total,
+// throughput, and ETA, matching the bar downloader.go already uses for the
+// GGUF download path.
+type TerminalProgressReporter struct {
+	mu   sync.Mutex
+	bars map[string]*progressbar.ProgressBar
+}
+
+// NewTerminalProgressReporter returns a ProgressReporter that prints a bar
+// per file to the terminal.
+func NewTerminalProgressReporter() *TerminalProgressReporter {
+	return &TerminalProgressReporter{bars: make(map[string]*progressbar.ProgressBar)}
+}
+
+func (r *TerminalProgressReporter) OnStart(file string, totalBytes int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.bars[file] = progressbar.DefaultBytes(totalBytes, fmt.Sprintf("Downloading %s", file))
+}
+
+func (r *TerminalProgressReporter) OnProgress(file string, written, _ int64, _ float64) {
+	r.mu.Lock()
+	bar := r.bars[file]
+	r.mu.Unlock()
+	if bar != nil {
+		bar.Set64(written)
+	}
+}
+
+func (r *TerminalProgressReporter) OnDone(file string, written int64, _ time.Duration) {
+	r.mu.Lock()
+	bar := r.bars[file]
+	delete(r.bars, file)
+	r.mu.Unlock()
+	if bar != nil {
+		bar.Set64(written)
+		bar.Finish()
+	}
+}
+
+func (r *TerminalProgressReporter) OnError(file string, err error) {
+	r.mu.Lock()
+	bar := r.bars[file]
+	delete(r.bars, file)
+	r.mu.Unlock()
+	if bar != nil {
+		bar.Clear()
+	}
+	fmt.Printf("Downloading %s failed: %v\n", file, err)
+}
+
+// progressEvent is the JSON-lines wire format JSONLinesProgressReporter
+// writes. The HTTP status/SSE handlers in pkg/server do not use it; they
+// encode StatusRegistry's FileStatus instead.
+type progressEvent struct {
+	Type        string  `json:"type"` // "start", "progress", "done", "error"
+	File        string  `json:"file"`
+	Written     int64   `json:"written"`
+	TotalBytes  int64   `json:"total_bytes,omitempty"`
+	BytesPerSec float64 `json:"bytes_per_sec,omitempty"`
+	ElapsedMS   int64   `json:"elapsed_ms,omitempty"`
+	Error       string  `json:"error,omitempty"`
+}
+
+// JSONLinesProgressReporter writes one JSON object per event, newline
+// delimited, to w: a machine-readable alternative to
+// TerminalProgressReporter for CLIs and CI logs that want to parse
+// progress rather than render it.
+type JSONLinesProgressReporter struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewJSONLinesProgressReporter returns a ProgressReporter that writes
+// newline-delimited JSON events to w.
+func NewJSONLinesProgressReporter(w io.Writer) *JSONLinesProgressReporter {
+	return &JSONLinesProgressReporter{w: w}
+}
+
+func (r *JSONLinesProgressReporter) emit(ev progressEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, err := json.Marshal(ev)
+	if err != nil {
+		return
+	}
+	r.w.Write(append(data, '\n'))
+}
+
+func (r *JSONLinesProgressReporter) OnStart(file string, totalBytes int64) {
+	r.emit(progressEvent{Type: "start", File: file, TotalBytes: totalBytes})
+}
+
+func (r *JSONLinesProgressReporter) OnProgress(file string, written, totalBytes int64, bytesPerSec float64) {
+	r.emit(progressEvent{Type: "progress", File: file, Written: written, TotalBytes: totalBytes, BytesPerSec: bytesPerSec})
+}
+
+func (r *JSONLinesProgressReporter) OnDone(file string, written int64, elapsed time.Duration) {
+	r.emit(progressEvent{Type: "done", File: file, Written: written, ElapsedMS: elapsed.Milliseconds()})
+}
+
+func (r *JSONLinesProgressReporter) OnError(file string, err error) {
+	r.emit(progressEvent{Type: "error", File: file, Error: err.Error()})
+}
@@ -0,0 +1,199 @@
+package nanochat
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// recordingReporter captures every ProgressReporter call it receives, for
+// assertions on ordering and monotonicity.
+type recordingReporter struct {
+	starts   []int64
+	progress []struct {
+		written, total int64
+		rate           float64
+	}
+	done []int64
+	errs []string
+}
+
+func (r *recordingReporter) OnStart(_ string, totalBytes int64) {
+	r.starts = append(r.starts, totalBytes)
+}
+
+func (r *recordingReporter) OnProgress(_ string, written, totalBytes int64, bytesPerSec float64) {
+	r.progress = append(r.progress, struct {
+		written, total int64
+		rate           float64
+	}{written, totalBytes, bytesPerSec})
+}
+
+func (r *recordingReporter) OnDone(_ string, written int64, _ time.Duration) {
+	r.done = append(r.done, written)
+}
+
+func (r *recordingReporter) OnError(_ string, err error) {
+	r.errs = append(r.errs, err.Error())
+}
+
+func TestProgressTrackerReportsMonotonicallyIncreasingBytes(t *testing.T) {
+	reporter := &recordingReporter{}
+	tracker := newProgressTracker(reporter, "model.pt", 1000)
+
+	if len(reporter.starts) != 1 || reporter.starts[0] != 1000 {
+		t.Fatalf("expected one OnStart(1000) call, got %v", reporter.starts)
+	}
+
+	tracker.add(100)
+	time.Sleep(progressReportInterval + 10*time.Millisecond)
+	tracker.add(200)
+	time.Sleep(progressReportInterval + 10*time.Millisecond)
+	tracker.add(300)
+
+	if len(reporter.progress) < 2 {
+		t.Fatalf("expected at least 2 OnProgress calls, got %d", len(reporter.progress))
+	}
+	last := int64(0)
+	for _, p := range reporter.progress {
+		if p.written < last {
+			t.Fatalf("OnProgress written went backwards: %d after %d", p.written, last)
+		}
+		last = p.written
+	}
+
+	if reporter.progress[len(reporter.progress)-1].rate <= 0 {
+		t.Fatalf("expected a non-zero rate after the first tick, got %v", reporter.progress[len(reporter.progress)-1].rate)
+	}
+
+	tracker.done()
+	if len(reporter.done) != 1 || reporter.done[0] != 600 {
+		t.Fatalf("expected OnDone(600), got %v", reporter.done)
+	}
+}
+
+func TestProgressTrackerReportsOnError(t *testing.T) {
+	reporter := &recordingReporter{}
+	tracker := newProgressTracker(reporter, "model.pt", 1000)
+
+	tracker.add(50)
+	tracker.fail(errors.New("connection reset"))
+
+	if len(reporter.errs) != 1 || reporter.errs[0] != "connection reset" {
+		t.Fatalf("expected OnError(\"connection reset\"), got %v", reporter.errs)
+	}
+	if len(reporter.done) != 0 {
+		t.Fatalf("expected no OnDone call after fail, got %v", reporter.done)
+	}
+}
+
+func TestProgressCountingWriterForwardsBytesAndErrors(t *testing.T) {
+	reporter := &recordingReporter{}
+	tracker := newProgressTracker(reporter, "model.pt", 10)
+
+	var buf bytes.Buffer
+	w := &progressCountingWriter{dst: &buf, tracker: tracker}
+
+	n, err := w.Write([]byte("hello"))
+	if err != nil || n != 5 {
+		t.Fatalf("Write() = (%d, %v), want (5, nil)", n, err)
+	}
+	if buf.String() != "hello" {
+		t.Fatalf("dst got %q, want %q", buf.String(), "hello")
+	}
+	if tracker.written != 5 {
+		t.Fatalf("tracker.written = %d, want 5", tracker.written)
+	}
+}
+
+func TestJSONLinesProgressReporterEmitsOneEventPerLine(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewJSONLinesProgressReporter(&buf)
+
+	reporter.OnStart("model.pt", 100)
+	reporter.OnProgress("model.pt", 50, 100, 12.5)
+	reporter.OnDone("model.pt", 100, 2*time.Second)
+
+	lines := strings.Split(strings.TrimRight(buf.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+	}
+
+	var start progressEvent
+	if err := json.Unmarshal([]byte(lines[0]), &start); err != nil {
+		t.Fatalf("unmarshal start event: %v", err)
+	}
+	if start.Type != "start" || start.TotalBytes != 100 {
+		t.Fatalf("unexpected start event: %+v", start)
+	}
+
+	var done progressEvent
+	if err := json.Unmarshal([]byte(lines[2]), &done); err != nil {
+		t.Fatalf("unmarshal done event: %v", err)
+	}
+	if done.Type != "done" || done.Written != 100 || done.ElapsedMS != 2000 {
+		t.Fatalf("unexpected done event: %+v", done)
+	}
+}
+
+func TestJSONLinesProgressReporterEmitsErrorEvent(t *testing.T) {
+	var buf bytes.Buffer
+	reporter := NewJSONLinesProgressReporter(&buf)
+
+	reporter.OnError("model.pt", errors.New("boom"))
+
+	var ev progressEvent
+	if err := json.Unmarshal(bytes.TrimRight(buf.Bytes(), "\n"), &ev); err != nil {
+		t.Fatalf("unmarshal error event: %v", err)
+	}
+	if ev.Type != "error" || ev.Error != "boom" {
+		t.Fatalf("unexpected error event: %+v", ev)
+	}
+}
+
+func TestStatusRegistrySnapshotReflectsLatestState(t *testing.T) {
+	registry := NewStatusRegistry()
+
+	registry.OnStart("model.pt", 1000)
+	registry.OnProgress("model.pt", 500, 1000, 100)
+
+	snapshot := registry.Snapshot()
+	if len(snapshot) != 1 {
+		t.Fatalf("expected 1 file in snapshot, got %d", len(snapshot))
+	}
+	if snapshot[0].State != DownloadStateRunning || snapshot[0].Written != 500 {
+		t.Fatalf("unexpected status: %+v", snapshot[0])
+	}
+	if snapshot[0].Percent != 50 {
+		t.Fatalf("expected 50%% complete, got %v", snapshot[0].Percent)
+	}
+
+	registry.OnDone("model.pt", 1000, time.Second)
+	snapshot = registry.Snapshot()
+	if snapshot[0].State != DownloadStateDone || snapshot[0].Percent != 100 {
+		t.Fatalf("unexpected status after done: %+v", snapshot[0])
+	}
+}
+
+func TestStatusRegistrySubscribeReceivesUpdates(t *testing.T) {
+	registry := NewStatusRegistry()
+
+	ch := make(chan FileStatus, 4)
+	cancel := registry.Subscribe(ch)
+	defer cancel()
+
+	registry.OnStart("model.pt", 1000)
+	registry.OnError("model.pt", errors.New("disk full"))
+
+	first := <-ch
+	if first.State != DownloadStatePending {
+		t.Fatalf("expected first update to be pending, got %+v", first)
+	}
+	second := <-ch
+	if second.State != DownloadStateError || second.Error != "disk full" {
+		t.Fatalf("expected second update to be the error, got %+v", second)
+	}
+}
@@ -1,9 +1,7 @@
 package nanochat
 
 import (
-	"bufio"
 	"context"
-	"encoding/json"
 	"fmt"
 	"io"
 	"log"
@@ -22,12 +20,51 @@ type PythonEngine struct {
 	url       string
 	client    *http.Client
 	logFile   *os.File
+	logPath   string
 	mu        sync.Mutex
 	isRunning bool
 	pythonBin string
 	modelDir  string
+	port      int
+
+	// startCtx is the context Start was called with, reused by the
+	// supervisor goroutine to relaunch the process with the same
+	// cancellation scope.
+	startCtx context.Context
+	// stopping is set by Stop before signaling the process, so the
+	// supervisor goroutine knows an exit was requested rather than a
+	// crash, and skips auto-restart.
+	stopping bool
+	// exited is closed by the supervisor once it has finished recording
+	// an exit, so Stop can block until cleanup (including log file
+	// closure) is complete.
+	exited chan struct{}
+
+	restartPolicy RestartPolicy
+	restartCount  int
+	lastExitCode  int
+	lastExitTime  time.Time
+	lastExitLog   string
 }
 
+// RestartPolicy controls whether PythonEngine relaunches its subprocess
+// after it exits unexpectedly, and how aggressively.
+type RestartPolicy struct {
+	// Enabled turns on auto-restart. When false (the default), an exited
+	// process is simply left stopped, as before this feature existed.
+	Enabled bool
+	// MaxRestarts caps the number of relaunch attempts; 0 means unlimited.
+	MaxRestarts int
+	// InitialBackoff is the delay before the first restart attempt.
+	InitialBackoff time.Duration
+	// MaxBackoff caps the exponentially growing delay between attempts;
+	// 0 means uncapped.
+	MaxBackoff time.Duration
+}
+
+// defaultLogTailLines is how many trailing log lines LastExit reports.
+const defaultLogTailLines = 20
+
 // ChatMessage represents a single chat message
 type ChatMessage struct {
 	Role    string `json:"role"`
@@ -40,6 +77,10 @@ type ChatCompletionRequest struct {
 	Temperature *float32      `json:"temperature,omitempty"`
 	MaxTokens   *int          `json:"max_tokens,omitempty"`
 	TopK        *int          `json:"top_k,omitempty"`
+	// RequestID identifies this request for later cancellation via
+	// PythonEngine.Cancel. Chat assigns one when the caller leaves it
+	// empty.
+	RequestID string `json:"request_id,omitempty"`
 }
 
 // CompletionToken represents a single token from streaming completion
@@ -49,8 +90,21 @@ type CompletionToken struct {
 	Error string `json:"error,omitempty"`
 }
 
-// NewPythonEngine creates a new Python engine manager
+// defaultPythonEnginePort is the port NewPythonEngine binds to. Callers that
+// need several PythonEngine instances running side by side (EnginePool) use
+// NewPythonEngineOnPort with distinct ports instead.
+const defaultPythonEnginePort = 8081
+
+// NewPythonEngine creates a new Python engine manager bound to the default
+// port.
 func NewPythonEngine(modelDir string) *PythonEngine {
+	return NewPythonEngineOnPort(modelDir, defaultPythonEnginePort)
+}
+
+// NewPythonEngineOnPort creates a Python engine manager bound to an
+// explicit port, so several instances can run concurrently (see
+// EnginePool).
+func NewPythonEngineOnPort(modelDir string, port int) *PythonEngine {
 	// Find Python executable
 	pythonBin := "python3"
 	if err := exec.Command(pythonBin, "--version").Run(); err != nil {
@@ -58,15 +112,18 @@ func NewPythonEngine(modelDir string) *PythonEngine {
 	}
 
 	return &PythonEngine{
-		url:       "http://127.0.0.1:8081",
+		url:       fmt.Sprintf("http://127.0.0.1:%d", port),
 		client:    &http.Client{Timeout: 30 * time.Second},
 		isRunning: false,
 		pythonBin: pythonBin,
 		modelDir:  modelDir,
+		port:      port,
 	}
 }
 
-// Start launches the Python inference server subprocess
+// Start launches the Python inference server subprocess and, once it's
+// healthy, hands it off to a supervisor goroutine that detects a crash and
+// (per SetRestartPolicy) relaunches it.
 func (pe *PythonEngine) Start(ctx context.Context, logPath string) error {
 	pe.mu.Lock()
 	defer pe.mu.Unlock()
@@ -75,6 +132,17 @@ func (pe *PythonEngine) Start(ctx context.Context, logPath string) error {
 		return fmt.Errorf("python engine already running")
 	}
 
+	pe.startCtx = ctx
+	pe.logPath = logPath
+	pe.stopping = false
+	pe.restartCount = 0
+
+	return pe.startLocked()
+}
+
+// startLocked launches the subprocess using pe.startCtx/pe.logPath. Callers
+// must hold pe.mu.
+func (pe *PythonEngine) startLocked() error {
 	log.Printf("[PythonEngine] Starting inference server on %s", pe.url)
 
 	// Prepare inference server script path
@@ -100,18 +168,18 @@ func (pe *PythonEngine) Start(ctx context.Context, logPath string) error {
 
 	// Setup log file
 	var err error
-	if logPath != "" {
-		pe.logFile, err = os.Create(logPath)
+	if pe.logPath != "" {
+		pe.logFile, err = os.Create(pe.logPath)
 		if err != nil {
 			return fmt.Errorf("failed to create log file: %w", err)
 		}
 	}
 
 	// Build command
-	pe.cmd = exec.CommandContext(ctx,
+	pe.cmd = exec.CommandContext(pe.startCtx,
 		pe.pythonBin,
 		scriptPath,
-		"--port", "8081",
+		"--port", fmt.Sprintf("%d", pe.port),
 		"--host", "127.0.0.1",
 		"--model-dir", pe.modelDir,
 	)
@@ -151,11 +219,12 @@ func (pe *PythonEngine) Start(ctx context.Context, logPath string) error {
 
 	pe.process = pe.cmd.Process
 	pe.isRunning = true
+	pe.exited = make(chan struct{})
 
 	log.Printf("[PythonEngine] Python process started (PID: %d)", pe.process.Pid)
 
 	// Wait for server to be ready
-	if err := pe.waitHealthy(ctx, 30*time.Second); err != nil {
+	if err := pe.waitHealthy(pe.startCtx, 30*time.Second); err != nil {
 		pe.isRunning = false
 		pe.process.Kill()
 		if pe.logFile != nil {
@@ -165,39 +234,35 @@ func (pe *PythonEngine) Start(ctx context.Context, logPath string) error {
 	}
 
 	log.Printf("[PythonEngine] Server ready at %s", pe.url)
+	go pe.supervise()
 	return nil
 }
 
-// Stop gracefully shuts down the Python server
+// Stop gracefully shuts down the Python server and waits for the
+// supervisor goroutine to record its exit.
 func (pe *PythonEngine) Stop() error {
 	pe.mu.Lock()
-	defer pe.mu.Unlock()
-
 	if !pe.isRunning || pe.process == nil {
+		pe.mu.Unlock()
 		return nil
 	}
 
-	log.Printf("[PythonEngine] Stopping server (PID: %d)", pe.process.Pid)
+	pe.stopping = true
+	process := pe.process
+	exited := pe.exited
+	pe.mu.Unlock()
+
+	log.Printf("[PythonEngine] Stopping server (PID: %d)", process.Pid)
 
 	// Try graceful shutdown first (SIGTERM)
-	if err := pe.process.Signal(os.Interrupt); err != nil {
+	if err := process.Signal(os.Interrupt); err != nil {
 		log.Printf("[PythonEngine] SIGTERM failed: %v, force killing", err)
-		_ = pe.process.Kill()
-	}
-
-	// Wait for process to exit
-	if pe.cmd != nil {
-		if err := pe.cmd.Wait(); err != nil && err.Error() != "signal: interrupt" {
-			log.Printf("[PythonEngine] Process wait error: %v", err)
-		}
+		_ = process.Kill()
 	}
 
-	pe.isRunning = false
-
-	// Close log file
-	if pe.logFile != nil {
-		pe.logFile.Close()
-		pe.logFile = nil
+	// Wait for the supervisor goroutine to observe the exit and clean up.
+	if exited != nil {
+		<-exited
 	}
 
 	log.Printf("[PythonEngine] Server stopped")
@@ -219,84 +284,34 @@ func (pe *PythonEngine) Health(ctx context.Context) error {
 	return nil
 }
 
-// Chat sends a completion request and streams tokens back
-func (pe *PythonEngine) Chat(ctx context.Context, req *ChatCompletionRequest) (<-chan CompletionToken, error) {
-	// Validate request
-	if len(req.Messages) == 0 {
-		return nil, fmt.Errorf("no messages in request")
+// Chat sends a completion request and returns a ChatStream of the server's
+// chat.completion.chunk events. inference_server.py speaks the same
+// OpenAI-compatible streaming dialect as the other engines, so this just
+// delegates to streamOpenAIChat rather than framing its own wire format.
+//
+// Protocol: Chat assigns req.RequestID when the caller hasn't already set
+// one, and registers a context.AfterFunc that POSTs /cancel/{request_id}
+// the instant ctx is cancelled (e.g. the client disconnected), rather than
+// letting inference_server.py generate a response nobody will read.
+// inference_server.py is expected to check a per-request cancel flag
+// between token steps and stop early once it sees one.
+func (pe *PythonEngine) Chat(ctx context.Context, req *ChatCompletionRequest) (*ChatStream, error) {
+	if req.RequestID == "" {
+		req.RequestID = newRequestID()
 	}
 
-	// Create output channel
-	tokens := make(chan CompletionToken, 10)
-
-	// Encode request to JSON
-	payload, err := json.Marshal(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to encode request: %w", err)
-	}
+	stop := context.AfterFunc(ctx, func() {
+		if err := pe.Cancel(context.Background(), req.RequestID); err != nil {
+			log.Printf("[PythonEngine] cancel %s failed: %v", req.RequestID, err)
+		}
+	})
 
-	// Create HTTP request
-	httpReq, err := http.NewRequestWithContext(
-		ctx,
-		"POST",
-		pe.url+"/chat/completions",
-		strings.NewReader(string(payload)),
-	)
+	stream, err := streamOpenAIChat(ctx, pe.client, pe.url, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
+		stop()
+		return nil, err
 	}
-
-	httpReq.Header.Set("Content-Type", "application/json")
-
-	// Send request in background
-	go func() {
-		defer close(tokens)
-
-		resp, err := pe.client.Do(httpReq)
-		if err != nil {
-			tokens <- CompletionToken{Error: fmt.Sprintf("request failed: %v", err)}
-			return
-		}
-		defer resp.Body.Close()
-
-		if resp.StatusCode != http.StatusOK {
-			tokens <- CompletionToken{Error: fmt.Sprintf("server returned %d", resp.StatusCode)}
-			return
-		}
-
-		// Read streaming response
-		scanner := bufio.NewScanner(resp.Body)
-		for scanner.Scan() {
-			line := scanner.Text()
-
-			// Skip empty lines and "data: " prefix
-			if !strings.HasPrefix(line, "data: ") {
-				continue
-			}
-
-			data := strings.TrimPrefix(line, "data: ")
-
-			// Parse JSON token
-			var token CompletionToken
-			if err := json.Unmarshal([]byte(data), &token); err != nil {
-				log.Printf("[PythonEngine] Failed to parse token: %v", err)
-				continue
-			}
-
-			tokens <- token
-
-			// Stop if done
-			if token.Done {
-				break
-			}
-		}
-
-		if err := scanner.Err(); err != nil {
-			tokens <- CompletionToken{Error: fmt.Sprintf("read error: %v", err)}
-		}
-	}()
-
-	return tokens, nil
+	return drainTracking(stream, func() { stop() }), nil
 }
 
 // waitHealthy polls /health endpoint until server is ready
@@ -0,0 +1,37 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// Cancel asks the Python inference server to stop generating for request
+// id. It's safe to call for a request that has already finished or was
+// never started; inference_server.py is expected to treat an unknown id as
+// a no-op rather than an error.
+func (pe *PythonEngine) Cancel(ctx context.Context, id string) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, pe.url+"/cancel/"+id, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create cancel request: %w", err)
+	}
+
+	resp, err := pe.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("cancel request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("cancel returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// newRequestID generates the server-assigned id Chat attaches to a request
+// so a later Cancel call can identify it to the inference loop.
+func newRequestID() string {
+	return "req-" + uuid.New().String()
+}
@@ -0,0 +1,26 @@
+package nanochat
+
+import (
+	"context"
+	"testing"
+)
+
+func TestNewRequestIDIsUniqueAndPrefixed(t *testing.T) {
+	a := newRequestID()
+	b := newRequestID()
+
+	if a == b {
+		t.Errorf("expected distinct request ids, got %q twice", a)
+	}
+	if a[:4] != "req-" {
+		t.Errorf("expected req- prefix, got %q", a)
+	}
+}
+
+func TestCancelFailsWhenServerUnreachable(t *testing.T) {
+	engine := NewPythonEngine("/tmp/test-model")
+
+	if err := engine.Cancel(context.Background(), "req-does-not-exist"); err == nil {
+		t.Error("expected error cancelling against an unreachable server")
+	}
+}
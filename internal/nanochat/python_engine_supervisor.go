@@ -0,0 +1,123 @@
+package nanochat
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// SetRestartPolicy configures whether and how aggressively PythonEngine
+// relaunches its subprocess after an unexpected exit. It takes effect on
+// the next exit; it does not affect a restart already in flight.
+func (pe *PythonEngine) SetRestartPolicy(policy RestartPolicy) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	pe.restartPolicy = policy
+}
+
+// LastExit reports the exit code, timestamp, and trailing log output of
+// the most recent subprocess exit, or the zero values if the engine has
+// never exited.
+func (pe *PythonEngine) LastExit() (code int, when time.Time, tailLog string) {
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	return pe.lastExitCode, pe.lastExitTime, pe.lastExitLog
+}
+
+// supervise waits for the current subprocess to exit, records its exit
+// code and log tail, and — unless Stop requested the exit or restarts are
+// disabled — relaunches it with exponential backoff up to
+// RestartPolicy.MaxRestarts.
+func (pe *PythonEngine) supervise() {
+	pe.mu.Lock()
+	cmd := pe.cmd
+	exited := pe.exited
+	pe.mu.Unlock()
+
+	waitErr := cmd.Wait()
+
+	pe.mu.Lock()
+	pe.isRunning = false
+	pe.lastExitCode = exitCode(waitErr)
+	pe.lastExitTime = time.Now()
+	pe.lastExitLog = pe.tailLogLocked(defaultLogTailLines)
+	if pe.logFile != nil {
+		pe.logFile.Close()
+		pe.logFile = nil
+	}
+
+	stopping := pe.stopping
+	policy := pe.restartPolicy
+	pe.restartCount++
+	restartCount := pe.restartCount
+	code := pe.lastExitCode
+	pe.mu.Unlock()
+
+	close(exited)
+
+	log.Printf("[PythonEngine] process exited (code %d)", code)
+
+	if stopping || !policy.Enabled {
+		return
+	}
+	if policy.MaxRestarts > 0 && restartCount > policy.MaxRestarts {
+		log.Printf("[PythonEngine] giving up after %d restart attempt(s)", restartCount)
+		return
+	}
+
+	backoff := policy.InitialBackoff
+	if backoff <= 0 {
+		backoff = time.Second
+	}
+	backoff *= time.Duration(uint64(1) << uint(restartCount-1))
+	if policy.MaxBackoff > 0 && backoff > policy.MaxBackoff {
+		backoff = policy.MaxBackoff
+	}
+
+	log.Printf("[PythonEngine] restarting in %v (attempt %d)", backoff, restartCount)
+	time.Sleep(backoff)
+
+	pe.mu.Lock()
+	defer pe.mu.Unlock()
+	if pe.stopping || pe.isRunning {
+		return
+	}
+	if err := pe.startLocked(); err != nil {
+		log.Printf("[PythonEngine] restart attempt %d failed: %v", restartCount, err)
+	}
+}
+
+// tailLogLocked returns the last n lines of the subprocess log file.
+// Callers must hold pe.mu.
+func (pe *PythonEngine) tailLogLocked(n int) string {
+	if pe.logPath == "" {
+		return ""
+	}
+
+	data, err := os.ReadFile(pe.logPath)
+	if err != nil {
+		return ""
+	}
+
+	lines := strings.Split(strings.TrimRight(string(data), "\n"), "\n")
+	if len(lines) > n {
+		lines = lines[len(lines)-n:]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// exitCode extracts the process exit code from the error cmd.Wait returns,
+// or -1 if it isn't an *exec.ExitError (e.g. the process was never started).
+func exitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+	return -1
+}
@@ -0,0 +1,55 @@
+package nanochat
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestExitCodeNilError(t *testing.T) {
+	if code := exitCode(nil); code != 0 {
+		t.Errorf("expected 0, got %d", code)
+	}
+}
+
+func TestExitCodeNonExitError(t *testing.T) {
+	if code := exitCode(errors.New("boom")); code != -1 {
+		t.Errorf("expected -1, got %d", code)
+	}
+}
+
+func TestTailLogLockedReturnsLastNLines(t *testing.T) {
+	dir := t.TempDir()
+	logPath := filepath.Join(dir, "engine.log")
+	content := "line1\nline2\nline3\nline4\n"
+	if err := os.WriteFile(logPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to write log: %v", err)
+	}
+
+	engine := NewPythonEngine("/tmp/test-model")
+	engine.logPath = logPath
+
+	tail := engine.tailLogLocked(2)
+	if tail != "line3\nline4" {
+		t.Errorf("expected last 2 lines, got %q", tail)
+	}
+}
+
+func TestLastExitZeroValueBeforeAnyRun(t *testing.T) {
+	engine := NewPythonEngine("/tmp/test-model")
+
+	code, when, tail := engine.LastExit()
+	if code != 0 || !when.IsZero() || tail != "" {
+		t.Errorf("expected zero values, got code=%d when=%v tail=%q", code, when, tail)
+	}
+}
+
+func TestSetRestartPolicy(t *testing.T) {
+	engine := NewPythonEngine("/tmp/test-model")
+	engine.SetRestartPolicy(RestartPolicy{Enabled: true, MaxRestarts: 3})
+
+	if !engine.restartPolicy.Enabled || engine.restartPolicy.MaxRestarts != 3 {
+		t.Errorf("restart policy not applied: %+v", engine.restartPolicy)
+	}
+}
@@ -0,0 +1,220 @@
+package nanochat
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/google/uuid"
+	"gopkg.in/yaml.v3"
+)
+
+// modelSpecsFile is the on-disk shape LoadModelSpecs parses: a list of
+// ModelSpec under a "models" key.
+type modelSpecsFile struct {
+	Models []struct {
+		ID     string `yaml:"id"`
+		Alias  string `yaml:"alias"`
+		Source string `yaml:"source"`
+		Files  []struct {
+			Name     string `yaml:"name"`
+			MinSize  int64  `yaml:"min_size"`
+			Optional bool   `yaml:"optional"`
+		} `yaml:"files"`
+	} `yaml:"models"`
+}
+
+// LoadModelSpecs reads a models.yaml describing the models a
+// ModelRegistry should serve. See defaultNanochatSpec for the shape each
+// entry maps to.
+func LoadModelSpecs(path string) ([]ModelSpec, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", path, err)
+	}
+
+	var parsed modelSpecsFile
+	if err := yaml.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	specs := make([]ModelSpec, 0, len(parsed.Models))
+	for _, m := range parsed.Models {
+		spec := ModelSpec{ID: m.ID, Alias: m.Alias, Source: m.Source}
+		for _, f := range m.Files {
+			spec.Files = append(spec.Files, ModelFile{Name: f.Name, MinSize: f.MinSize, Optional: f.Optional})
+		}
+		specs = append(specs, spec)
+	}
+	return specs, nil
+}
+
+// DefaultModelSpecs is the built-in model list a ModelRegistry falls
+// back to without a models.yaml on disk: the nanochat checkpoint
+// ModelManager has always served, plus the SmolLM proxy model.
+func DefaultModelSpecs() []ModelSpec {
+	return []ModelSpec{defaultNanochatSpec(), defaultSmolLMSpec()}
+}
+
+// defaultSmolLMSpec describes the SmolLM-135M checkpoint smollmModelName
+// names; pkg/server's smollm proxy mode expects this served locally by
+// llama.cpp rather than run through ModelManager, so its file list only
+// covers what a from-scratch local mirror would need.
+func defaultSmolLMSpec() ModelSpec {
+	return ModelSpec{
+		ID:     "smollm-135m",
+		Alias:  "smollm",
+		Source: "https://huggingface.co/" + smollmModelName + "/resolve/main",
+		Files: []ModelFile{
+			{Name: "model.safetensors"},
+			{Name: "tokenizer.json"},
+			{Name: "config.json"},
+		},
+	}
+}
+
+// pullJob tracks one in-flight or finished ModelRegistry.Pull call.
+type pullJob struct {
+	id   string
+	done chan struct{}
+	err  error
+}
+
+// ModelRegistry holds the set of models a simulator deployment can serve,
+// each downloaded independently into its own cacheRoot/<id> subdirectory.
+// ModelManagers are created lazily on first Get and reused afterwards.
+type ModelRegistry struct {
+	cacheRoot string
+	progress  ProgressReporter
+
+	mu       sync.Mutex
+	specs    map[string]ModelSpec
+	managers map[string]*ModelManager
+	jobs     map[string]*pullJob // keyed by model ID
+}
+
+// NewModelRegistry returns a ModelRegistry serving specs, each cached
+// under its own cacheRoot/<id> subdirectory. progress, if non-nil, is
+// attached to every ModelManager the registry creates, so a single
+// StatusRegistry can observe downloads across every model.
+func NewModelRegistry(cacheRoot string, specs []ModelSpec, progress ProgressReporter) *ModelRegistry {
+	byID := make(map[string]ModelSpec, len(specs))
+	for _, spec := range specs {
+		byID[spec.ID] = spec
+	}
+	return &ModelRegistry{
+		cacheRoot: cacheRoot,
+		progress:  progress,
+		specs:     byID,
+		managers:  make(map[string]*ModelManager),
+		jobs:      make(map[string]*pullJob),
+	}
+}
+
+// IDs returns every registered model ID, sorted for stable listing.
+func (r *ModelRegistry) IDs() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	ids := make([]string, 0, len(r.specs))
+	for id := range r.specs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Spec returns the ModelSpec registered under id.
+func (r *ModelRegistry) Spec(id string) (ModelSpec, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	spec, ok := r.specs[id]
+	return spec, ok
+}
+
+// Get returns the ModelManager for id, building it on first use with a
+// source resolved from the spec's Source URI and a cache directory
+// scoped to this model alone.
+func (r *ModelRegistry) Get(id string) (*ModelManager, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.getLocked(id)
+}
+
+func (r *ModelRegistry) getLocked(id string) (*ModelManager, error) {
+	if mgr, ok := r.managers[id]; ok {
+		return mgr, nil
+	}
+
+	spec, ok := r.specs[id]
+	if !ok {
+		return nil, fmt.Errorf("nanochat: unknown model %q", id)
+	}
+
+	source, err := ModelSourceFromURI(spec.Source)
+	if err != nil {
+		return nil, fmt.Errorf("nanochat: model %q: %w", id, err)
+	}
+
+	progress := r.progress
+	if progress == nil {
+		progress = noopProgressReporter{}
+	}
+
+	mgr := NewModelManager(
+		filepath.Join(r.cacheRoot, id),
+		WithSources(source),
+		WithModelSpec(spec),
+		WithProgressReporter(progress),
+	)
+	r.managers[id] = mgr
+	return mgr, nil
+}
+
+// Pull starts (or, for an already-running download, rejoins) an
+// asynchronous EnsureModel for id and returns a job id a caller can log
+// or correlate with the ProgressReporter passed to NewModelRegistry; the
+// download's actual progress is observed through that reporter (e.g. via
+// server.NewRouterWithDownloadStatus), not by polling the job itself.
+func (r *ModelRegistry) Pull(id string) (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if job, ok := r.jobs[id]; ok {
+		select {
+		case <-job.done:
+			// Previous pull finished; start a fresh one below.
+		default:
+			return job.id, nil
+		}
+	}
+
+	mgr, err := r.getLocked(id)
+	if err != nil {
+		return "", err
+	}
+
+	job := &pullJob{id: uuid.NewString(), done: make(chan struct{})}
+	r.jobs[id] = job
+
+	go func() {
+		err := mgr.EnsureModel()
+		job.err = err
+		close(job.done)
+	}()
+
+	return job.id, nil
+}
+
+// Delete removes id's cached files via its ModelManager's Clean.
+func (r *ModelRegistry) Delete(id string) error {
+	r.mu.Lock()
+	mgr, err := r.getLocked(id)
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return mgr.Clean()
+}
@@ -0,0 +1,210 @@
+package nanochat
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func testRegistrySpec(id string, content []byte) ModelSpec {
+	return ModelSpec{
+		ID:    id,
+		Alias: id,
+		Files: []ModelFile{{Name: "weights.bin", MinSize: int64(len(content))}},
+	}
+}
+
+func newTestRegistryServer(t *testing.T, content []byte) *httptest.Server {
+	t.Helper()
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, manifestFile) {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Accept-Ranges", "bytes")
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+			return
+		}
+
+		start := 0
+		if rangeHeader := r.Header.Get("Range"); rangeHeader != "" {
+			fmt.Sscanf(rangeHeader, "bytes=%d-", &start)
+			w.WriteHeader(http.StatusPartialContent)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+		w.Write(content[start:])
+	}))
+}
+
+func TestModelRegistryGetBuildsOneManagerPerModel(t *testing.T) {
+	content := []byte("weights")
+	server := newTestRegistryServer(t, content)
+	defer server.Close()
+
+	spec := testRegistrySpec("test-model", content)
+	spec.Source = server.URL
+	registry := NewModelRegistry(t.TempDir(), []ModelSpec{spec}, nil)
+
+	mgr1, err := registry.Get("test-model")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	mgr2, err := registry.Get("test-model")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if mgr1 != mgr2 {
+		t.Fatal("expected Get to reuse the same ModelManager for the same ID")
+	}
+
+	if _, err := registry.Get("missing-model"); err == nil {
+		t.Fatal("expected an error for an unregistered model ID")
+	}
+}
+
+func TestModelRegistryPullDownloadsIntoPerModelCacheDir(t *testing.T) {
+	content := []byte("weights")
+	server := newTestRegistryServer(t, content)
+	defer server.Close()
+
+	spec := testRegistrySpec("test-model", content)
+	spec.Source = server.URL
+	cacheRoot := t.TempDir()
+	registry := NewModelRegistry(cacheRoot, []ModelSpec{spec}, nil)
+
+	jobID, err := registry.Pull("test-model")
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if jobID == "" {
+		t.Fatal("expected a non-empty job id")
+	}
+
+	mgr, err := registry.Get("test-model")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	deadline := 0
+	for !mgr.ModelExists() {
+		if deadline > 200 {
+			t.Fatal("model never finished downloading")
+		}
+		time.Sleep(10 * time.Millisecond)
+		deadline++
+	}
+
+	if _, err := os.Stat(filepath.Join(cacheRoot, "test-model", "weights.bin")); err != nil {
+		t.Fatalf("expected weights.bin in the model's own cache subdirectory: %v", err)
+	}
+}
+
+func TestModelRegistryPullRejoinsInFlightJob(t *testing.T) {
+	content := []byte("weights")
+	server := newTestRegistryServer(t, content)
+	defer server.Close()
+
+	spec := testRegistrySpec("test-model", content)
+	spec.Source = server.URL
+	registry := NewModelRegistry(t.TempDir(), []ModelSpec{spec}, nil)
+
+	jobA, err := registry.Pull("test-model")
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	jobB, err := registry.Pull("test-model")
+	if err != nil {
+		t.Fatalf("Pull: %v", err)
+	}
+	if jobA != jobB {
+		t.Fatalf("expected a repeat Pull while downloading to rejoin the same job, got %q and %q", jobA, jobB)
+	}
+}
+
+func TestModelRegistryDeleteCleansCache(t *testing.T) {
+	content := []byte("weights")
+	server := newTestRegistryServer(t, content)
+	defer server.Close()
+
+	spec := testRegistrySpec("test-model", content)
+	spec.Source = server.URL
+	registry := NewModelRegistry(t.TempDir(), []ModelSpec{spec}, nil)
+
+	mgr, err := registry.Get("test-model")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if err := os.MkdirAll(mgr.ModelPath(), 0755); err != nil {
+		t.Fatalf("seed cache dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(mgr.ModelPath(), "weights.bin"), content, 0644); err != nil {
+		t.Fatalf("seed cache: %v", err)
+	}
+
+	if err := registry.Delete("test-model"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if mgr.ModelExists() {
+		t.Fatal("expected Delete to remove the cached file")
+	}
+}
+
+func TestDefaultModelSpecsIncludesNanochatAndSmolLM(t *testing.T) {
+	specs := DefaultModelSpecs()
+	var ids []string
+	for _, s := range specs {
+		ids = append(ids, s.ID)
+	}
+	wantNanochat, wantSmolLM := false, false
+	for _, id := range ids {
+		if id == "nanochat-650" {
+			wantNanochat = true
+		}
+		if id == "smollm-135m" {
+			wantSmolLM = true
+		}
+	}
+	if !wantNanochat || !wantSmolLM {
+		t.Fatalf("expected nanochat-650 and smollm-135m in %v", ids)
+	}
+}
+
+func TestLoadModelSpecsParsesYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models.yaml")
+	yamlBody := `
+models:
+  - id: custom-model
+    alias: custom
+    source: https://example.com/models/custom
+    files:
+      - name: weights.bin
+        min_size: 1024
+      - name: extra.json
+        optional: true
+`
+	if err := os.WriteFile(path, []byte(yamlBody), 0644); err != nil {
+		t.Fatalf("write models.yaml: %v", err)
+	}
+
+	specs, err := LoadModelSpecs(path)
+	if err != nil {
+		t.Fatalf("LoadModelSpecs: %v", err)
+	}
+	if len(specs) != 1 {
+		t.Fatalf("expected 1 spec, got %d", len(specs))
+	}
+	spec := specs[0]
+	if spec.ID != "custom-model" || spec.Alias != "custom" || spec.Source != "https://example.com/models/custom" {
+		t.Fatalf("unexpected spec: %+v", spec)
+	}
+	if len(spec.Files) != 2 || spec.Files[0].MinSize != 1024 || !spec.Files[1].Optional {
+		t.Fatalf("unexpected files: %+v", spec.Files)
+	}
+}
@@ -0,0 +1,87 @@
+package nanochat
+
+import (
+	"bufio"
+	"io"
+	"strings"
+)
+
+// sseEvent is one parsed Server-Sent Event: a run of field lines terminated
+// by a blank line. Per the SSE spec, repeated "data:" lines within the same
+// event are joined with "\n", "event:" sets the event type, "retry:" carries
+// a reconnection hint, and any line starting with ":" is a comment and
+// carries no field at all.
+type sseEvent struct {
+	Event string
+	Data  string
+	Retry string
+}
+
+// readSSEEvents reads Server-Sent Events from r, invoking handle once per
+// complete event. It stops at EOF or as soon as handle returns a non-nil
+// error, propagating that error to the caller.
+func readSSEEvents(r io.Reader, handle func(sseEvent) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var current sseEvent
+	var dataLines []string
+	hasEvent := false
+
+	flush := func() error {
+		if !hasEvent {
+			return nil
+		}
+		current.Data = strings.Join(dataLines, "\n")
+		err := handle(current)
+		current = sseEvent{}
+		dataLines = nil
+		hasEvent = false
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if line == "" {
+			if err := flush(); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+
+		field, value := splitSSEField(line)
+		hasEvent = true
+		switch field {
+		case "data":
+			dataLines = append(dataLines, value)
+		case "event":
+			current.Event = value
+		case "retry":
+			current.Retry = value
+		}
+	}
+
+	if err := flush(); err != nil {
+		return err
+	}
+
+	return scanner.Err()
+}
+
+// splitSSEField splits a raw SSE line into its field name and value. A
+// single leading space after the colon is stripped, as required by the
+// spec; a line with no colon is a field name with an empty value.
+func splitSSEField(line string) (field, value string) {
+	idx := strings.Index(line, ":")
+	if idx < 0 {
+		return line, ""
+	}
+	field = line[:idx]
+	value = strings.TrimPrefix(line[idx+1:], " ")
+	return field, value
+}
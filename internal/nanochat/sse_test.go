@@ -0,0 +1,66 @@
+package nanochat
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadSSEEventsJoinsMultiLineData(t *testing.T) {
+	raw := "data: line one\ndata: line two\n\n"
+
+	var events []sseEvent
+	if err := readSSEEvents(strings.NewReader(raw), func(e sseEvent) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Data != "line one\nline two" {
+		t.Errorf("expected joined data, got %q", events[0].Data)
+	}
+}
+
+func TestReadSSEEventsParsesEventAndRetryFields(t *testing.T) {
+	raw := "event: ping\nretry: 3000\ndata: {}\n\n"
+
+	var events []sseEvent
+	if err := readSSEEvents(strings.NewReader(raw), func(e sseEvent) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Event != "ping" {
+		t.Errorf("expected event 'ping', got %q", events[0].Event)
+	}
+	if events[0].Retry != "3000" {
+		t.Errorf("expected retry '3000', got %q", events[0].Retry)
+	}
+}
+
+func TestReadSSEEventsSkipsCommentLines(t *testing.T) {
+	raw := ": this is a comment\ndata: hello\n\n"
+
+	var events []sseEvent
+	if err := readSSEEvents(strings.NewReader(raw), func(e sseEvent) error {
+		events = append(events, e)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 event, got %d", len(events))
+	}
+	if events[0].Data != "hello" {
+		t.Errorf("expected data 'hello', got %q", events[0].Data)
+	}
+}
@@ -0,0 +1,128 @@
+package nanochat
+
+import (
+	"sync"
+	"time"
+)
+
+// DownloadState is where a file sits in ModelManager's download lifecycle,
+// as reported by StatusRegistry.
+type DownloadState string
+
+const (
+	DownloadStatePending DownloadState = "pending"
+	DownloadStateRunning DownloadState = "running"
+	DownloadStateDone    DownloadState = "done"
+	DownloadStateError   DownloadState = "error"
+)
+
+// FileStatus is a point-in-time snapshot of one file's download progress.
+type FileStatus struct {
+	File        string        `json:"file"`
+	State       DownloadState `json:"state"`
+	Written     int64         `json:"written"`
+	TotalBytes  int64         `json:"total_bytes,omitempty"`
+	Percent     float64       `json:"percent"`
+	BytesPerSec float64       `json:"bytes_per_sec,omitempty"`
+	ETASeconds  float64       `json:"eta_seconds,omitempty"`
+	Error       string        `json:"error,omitempty"`
+	UpdatedAt   time.Time     `json:"updated_at"`
+}
+
+// StatusRegistry is a ProgressReporter that keeps the latest FileStatus for
+// every file ModelManager has reported on, and fans each update out to any
+// subscribers, so an HTTP handler can serve a point-in-time snapshot (GET
+// .../status) or stream every update as it happens (GET .../events, via
+// SSE).
+type StatusRegistry struct {
+	mu          sync.RWMutex
+	files       map[string]FileStatus
+	subscribers map[chan FileStatus]struct{}
+}
+
+// NewStatusRegistry returns an empty StatusRegistry, ready to be passed to
+// ModelManager via WithProgressReporter.
+func NewStatusRegistry() *StatusRegistry {
+	return &StatusRegistry{
+		files:       make(map[string]FileStatus),
+		subscribers: make(map[chan FileStatus]struct{}),
+	}
+}
+
+func (s *StatusRegistry) set(status FileStatus) {
+	status.UpdatedAt = time.Now()
+
+	s.mu.Lock()
+	s.files[status.File] = status
+	var chans []chan FileStatus
+	for ch := range s.subscribers {
+		chans = append(chans, ch)
+	}
+	s.mu.Unlock()
+
+	for _, ch := range chans {
+		select {
+		case ch <- status:
+		default:
+			// Subscriber is behind; drop the update rather than block the
+			// download that's producing it.
+		}
+	}
+}
+
+func (s *StatusRegistry) OnStart(file string, totalBytes int64) {
+	s.set(FileStatus{File: file, State: DownloadStatePending, TotalBytes: totalBytes})
+}
+
+func (s *StatusRegistry) OnProgress(file string, written, totalBytes int64, bytesPerSec float64) {
+	status := FileStatus{
+		File:        file,
+		State:       DownloadStateRunning,
+		Written:     written,
+		TotalBytes:  totalBytes,
+		BytesPerSec: bytesPerSec,
+	}
+	if totalBytes > 0 {
+		status.Percent = 100 * float64(written) / float64(totalBytes)
+		if bytesPerSec > 0 {
+			status.ETASeconds = float64(totalBytes-written) / bytesPerSec
+		}
+	}
+	s.set(status)
+}
+
+func (s *StatusRegistry) OnDone(file string, written int64, _ time.Duration) {
+	s.set(FileStatus{File: file, State: DownloadStateDone, Written: written, TotalBytes: written, Percent: 100})
+}
+
+func (s *StatusRegistry) OnError(file string, err error) {
+	s.set(FileStatus{File: file, State: DownloadStateError, Error: err.Error()})
+}
+
+// Snapshot returns the current status of every file StatusRegistry has
+// heard about.
+func (s *StatusRegistry) Snapshot() []FileStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]FileStatus, 0, len(s.files))
+	for _, status := range s.files {
+		out = append(out, status)
+	}
+	return out
+}
+
+// Subscribe registers ch to receive every future status update until
+// cancel is called. ch should be buffered; a slow or full subscriber has
+// updates dropped rather than blocking the download.
+func (s *StatusRegistry) Subscribe(ch chan FileStatus) (cancel func()) {
+	s.mu.Lock()
+	s.subscribers[ch] = struct{}{}
+	s.mu.Unlock()
+
+	return func() {
+		s.mu.Lock()
+		delete(s.subscribers, ch)
+		s.mu.Unlock()
+	}
+}
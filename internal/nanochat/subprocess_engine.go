@@ -0,0 +1,180 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// subprocessEngine is the shared process-lifecycle implementation behind
+// LlamaServerEngine, VLLMEngine, and MLXEngine: spawning the subprocess,
+// wiring its output to a log file (or stdout/stderr), polling Health until
+// ready (killing the process if it never is), and on Stop sending SIGINT
+// before force-killing. Each engine type embeds a *subprocessEngine and
+// supplies only what's specific to its own backend: binary resolution,
+// command-line flags, fixed port, and health-check path. Stop, Health, Chat,
+// URL, and IsRunning are promoted straight from here; only Start (which
+// needs to resolve a binary and build an argv) is written per engine type.
+type subprocessEngine struct {
+	logTag      string // e.g. "[LlamaServerEngine]", prefixes every log.Printf call
+	processName string // e.g. "llama-server", used in start/already-running/not-ready messages
+	url         string
+	healthPath  string
+	client      *http.Client
+	cmd         *exec.Cmd
+	logFile     *os.File
+	mu          sync.Mutex
+	isRunning   bool
+}
+
+// newSubprocessEngine builds a subprocessEngine bound to url, checking
+// healthPath for readiness and logging under logTag/processName.
+func newSubprocessEngine(logTag, processName, url, healthPath string) *subprocessEngine {
+	return &subprocessEngine{
+		logTag:      logTag,
+		processName: processName,
+		url:         url,
+		healthPath:  healthPath,
+		client:      &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// start launches binPath(args...) as a subprocess, waits for it to report
+// healthy within timeout, and kills it if it never does. Callers resolve
+// binPath/args from their own engine-specific logic before calling this.
+func (e *subprocessEngine) start(ctx context.Context, logPath, binPath string, args []string, timeout time.Duration) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.isRunning {
+		return fmt.Errorf("%s already running", e.processName)
+	}
+
+	var err error
+	if logPath != "" {
+		e.logFile, err = os.Create(logPath)
+		if err != nil {
+			return fmt.Errorf("failed to create log file: %w", err)
+		}
+	}
+
+	e.cmd = exec.CommandContext(ctx, binPath, args...)
+	if e.logFile != nil {
+		e.cmd.Stdout = e.logFile
+		e.cmd.Stderr = e.logFile
+	} else {
+		e.cmd.Stdout = os.Stdout
+		e.cmd.Stderr = os.Stderr
+	}
+
+	if err := e.cmd.Start(); err != nil {
+		if e.logFile != nil {
+			e.logFile.Close()
+		}
+		return fmt.Errorf("failed to start %s: %w", e.processName, err)
+	}
+	e.isRunning = true
+
+	log.Printf("%s %s started (PID: %d)", e.logTag, e.processName, e.cmd.Process.Pid)
+
+	if err := e.waitHealthy(ctx, timeout); err != nil {
+		e.isRunning = false
+		e.cmd.Process.Kill()
+		if e.logFile != nil {
+			e.logFile.Close()
+		}
+		return fmt.Errorf("%s failed to become ready: %w", e.processName, err)
+	}
+
+	log.Printf("%s Server ready at %s", e.logTag, e.url)
+	return nil
+}
+
+// Stop sends SIGINT to the subprocess and waits for it to exit. It
+// force-kills the process only when SIGINT cannot be delivered.
+func (e *subprocessEngine) Stop() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if !e.isRunning || e.cmd == nil || e.cmd.Process == nil {
+		return nil
+	}
+
+	log.Printf("%s Stopping server (PID: %d)", e.logTag, e.cmd.Process.Pid)
+
+	if err := e.cmd.Process.Signal(os.Interrupt); err != nil {
+		log.Printf("%s SIGINT failed: %v, force killing", e.logTag, err)
+		_ = e.cmd.Process.Kill()
+	}
+
+	if err := e.cmd.Wait(); err != nil && err.Error() != "signal: interrupt" {
+		log.Printf("%s Process wait error: %v", e.logTag, err)
+	}
+
+	e.isRunning = false
+	if e.logFile != nil {
+		e.logFile.Close()
+		e.logFile = nil
+	}
+
+	log.Printf("%s Server stopped", e.logTag)
+	return nil
+}
+
+// Health checks if the subprocess is responding on its healthPath.
+func (e *subprocessEngine) Health(ctx context.Context) error {
+	resp, err := e.client.Get(e.url + e.healthPath)
+	if err != nil {
+		return fmt.Errorf("health check failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("health check returned %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// waitHealthy polls Health until it succeeds or timeout elapses.
+func (e *subprocessEngine) waitHealthy(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+
+	ticker := time.NewTicker(500 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Until(deadline)):
+			return fmt.Errorf("server not ready after %v", timeout)
+		case <-ticker.C:
+			if err := e.Health(ctx); err == nil {
+				return nil
+			}
+		}
+	}
+}
+
+// Chat sends a completion request and streams tokens back, proxying the
+// subprocess's OpenAI-compatible streaming endpoint.
+func (e *subprocessEngine) Chat(ctx context.Context, req *ChatCompletionRequest) (*ChatStream, error) {
+	return streamOpenAIChat(ctx, e.client, e.url, req)
+}
+
+// URL returns the server URL.
+func (e *subprocessEngine) URL() string {
+	return e.url
+}
+
+// IsRunning returns whether the engine is currently running.
+func (e *subprocessEngine) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.isRunning
+}
@@ -0,0 +1,52 @@
+package nanochat
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+)
+
+// vllmServerPort is the fixed port VLLMEngine binds vLLM's OpenAI-compatible
+// api_server to.
+const vllmServerPort = 8083
+
+// VLLMEngine manages a vLLM OpenAI-compatible api_server subprocess. Unlike
+// PythonEngine's small reference inference server, vLLM is a real GPU
+// inference engine; this is the extension point operators reach for on a
+// GPU host.
+type VLLMEngine struct {
+	*subprocessEngine
+	modelDir string
+	vllmBin  string
+}
+
+// NewVLLMEngine creates a vLLM engine manager that serves the model at
+// modelDir. The vllm binary is found via VLLM_BIN, defaulting to "vllm" on
+// PATH.
+func NewVLLMEngine(modelDir string) *VLLMEngine {
+	vllmBin := os.Getenv("VLLM_BIN")
+	if vllmBin == "" {
+		vllmBin = "vllm"
+	}
+
+	return &VLLMEngine{
+		subprocessEngine: newSubprocessEngine(
+			"[VLLMEngine]", "vllm server",
+			fmt.Sprintf("http://127.0.0.1:%d", vllmServerPort), "/health",
+		),
+		modelDir: modelDir,
+		vllmBin:  vllmBin,
+	}
+}
+
+// Start launches the vLLM api_server subprocess.
+func (e *VLLMEngine) Start(ctx context.Context, logPath string) error {
+	return e.subprocessEngine.start(ctx, logPath, e.vllmBin, []string{
+		"serve", e.modelDir,
+		"--host", "127.0.0.1",
+		"--port", fmt.Sprintf("%d", vllmServerPort),
+	}, 60*time.Second)
+}
+
+var _ Engine = (*VLLMEngine)(nil)
@@ -0,0 +1,316 @@
+package generator
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator/proto"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+)
+
+// Token is a single generated token, mirroring the proto Token message
+// (pkg/generator/proto/generator.proto).
+type Token struct {
+	Text string
+	Done bool
+}
+
+// Backend is a pluggable text generation backend. It mirrors the RPC
+// contract documented in pkg/generator/proto/generator.proto so that a
+// running simulator can dispatch requests to a real inference process
+// (llama.cpp, transformers, a custom model server) instead of always using
+// the built-in word-bank generator.
+type Backend interface {
+	// Generate streams generated tokens for a prompt, up to maxTokens. The
+	// channel is closed after the final token (Token.Done == true) or on
+	// error.
+	Generate(ctx context.Context, model, prompt string, maxTokens int64) (<-chan Token, error)
+	// Embed returns an embedding vector for input.
+	Embed(ctx context.Context, model, input string) ([]float64, error)
+	// Health reports whether the backend is ready to serve requests.
+	Health(ctx context.Context) bool
+}
+
+// SimulatedBackend is the in-process Backend implementation: it satisfies
+// the Backend contract with the built-in WordBank-driven generator instead
+// of dispatching to an out-of-process model server. It's the default
+// backend for any model not routed to a GRPCBackend.
+type SimulatedBackend struct {
+	textGen TextGenerator
+}
+
+// NewSimulatedBackend builds a Backend backed by the built-in coherent text
+// generator.
+func NewSimulatedBackend() *SimulatedBackend {
+	return &SimulatedBackend{textGen: NewCoherentTextGenerator()}
+}
+
+// Generate produces a full response with the built-in generator and streams
+// it one word at a time, so callers that only know about the Backend
+// interface see the same incremental delivery a real model would give.
+func (b *SimulatedBackend) Generate(ctx context.Context, model, prompt string, maxTokens int64) (<-chan Token, error) {
+	text := b.textGen.GenerateText(ctx, 1, int(maxTokens))
+	words := strings.Fields(text)
+
+	tokens := make(chan Token, len(words)+1)
+	for i, word := range words {
+		if i > 0 {
+			word = " " + word
+		}
+		tokens <- Token{Text: word}
+	}
+	tokens <- Token{Done: true}
+	close(tokens)
+	return tokens, nil
+}
+
+// Embed returns a deterministic, low-dimensional fake embedding derived
+// from a hash of input, so repeated calls with the same text are stable
+// without needing a real embedding model.
+func (b *SimulatedBackend) Embed(ctx context.Context, model, input string) ([]float64, error) {
+	sum := sha256.Sum256([]byte(input))
+	vec := make([]float64, 8)
+	for i := range vec {
+		vec[i] = float64(sum[i]) / 255.0
+	}
+	return vec, nil
+}
+
+// Health always reports ready; there is no out-of-process dependency to
+// fail.
+func (b *SimulatedBackend) Health(ctx context.Context) bool {
+	return true
+}
+
+// GRPCBackend is a reference Backend implementation that talks to an
+// out-of-process model server over a real gRPC connection, dispatching
+// PredictStream/Embed/Health/ModelInfo/TokenizerInfo against the BackendClient generated
+// (by hand - see pkg/generator/proto's package doc) from generator.proto.
+type GRPCBackend struct {
+	address string
+}
+
+// NewGRPCBackend builds a Backend that dials address lazily on each call,
+// matching how PythonEngine treats its subprocess address. address is
+// either a bare "host:port" or a "grpc://host:port" URL; the scheme, when
+// present, is stripped before dialing since grpc.NewClient's default resolver
+// already expects a bare authority.
+func NewGRPCBackend(address string) *GRPCBackend {
+	return &GRPCBackend{address: address}
+}
+
+// target strips the "grpc://" scheme from address, if present.
+func (b *GRPCBackend) target() string {
+	return strings.TrimPrefix(b.address, "grpc://")
+}
+
+// dial opens a connection to the backend for a single call. GRPCBackend
+// doesn't keep a persistent ClientConn: it dials per call, the same
+// lazy-connect shape the net/rpc-based predecessor of this type used, so a
+// backend that isn't running yet (e.g. BackendProcess still starting it)
+// fails each individual call instead of wedging a long-lived connection.
+func (b *GRPCBackend) dial() (*grpc.ClientConn, error) {
+	conn, err := grpc.NewClient(b.target(),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		proto.DialOption(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("backend %s unreachable: %w", b.address, err)
+	}
+	return conn, nil
+}
+
+// Generate streams tokens as the backend produces them, via generator.proto's
+// server-streaming PredictStream rpc, instead of waiting for a full response
+// and replaying it locally.
+func (b *GRPCBackend) Generate(ctx context.Context, model, prompt string, maxTokens int64) (<-chan Token, error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+
+	stream, err := proto.NewBackendClient(conn).PredictStream(ctx, &proto.PredictRequest{
+		Model: model, Prompt: prompt, MaxTokens: maxTokens,
+	})
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("backend %s predict stream failed: %w", b.address, err)
+	}
+
+	tokens := make(chan Token)
+	go func() {
+		defer close(tokens)
+		defer conn.Close()
+		for {
+			tok, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				return
+			}
+			select {
+			case tokens <- Token{Text: tok.Text, Done: tok.Done}:
+			case <-ctx.Done():
+				return
+			}
+			if tok.Done {
+				return
+			}
+		}
+	}()
+	return tokens, nil
+}
+
+func (b *GRPCBackend) Embed(ctx context.Context, model, input string) ([]float64, error) {
+	conn, err := b.dial()
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	reply, err := proto.NewBackendClient(conn).Embed(ctx, &proto.EmbedRequest{Model: model, Input: input})
+	if err != nil {
+		return nil, fmt.Errorf("backend %s embed failed: %w", b.address, err)
+	}
+	return reply.Vector, nil
+}
+
+// ModelInfo reports the name and version of the model the backend is
+// currently serving, via generator.proto's ModelInfo rpc.
+func (b *GRPCBackend) ModelInfo(ctx context.Context) (name, version string, err error) {
+	conn, err := b.dial()
+	if err != nil {
+		return "", "", err
+	}
+	defer conn.Close()
+
+	reply, err := proto.NewBackendClient(conn).ModelInfo(ctx, &proto.ModelInfoRequest{})
+	if err != nil {
+		return "", "", fmt.Errorf("backend %s model info failed: %w", b.address, err)
+	}
+	return reply.Name, reply.Version, nil
+}
+
+// TokenizerInfo reports the name and vocabulary size of the tokenizer the
+// backend uses, via generator.proto's TokenizerInfo rpc, so the simulator
+// can align its own token-count estimates with the backend's.
+func (b *GRPCBackend) TokenizerInfo(ctx context.Context) (name string, vocabSize int64, err error) {
+	conn, err := b.dial()
+	if err != nil {
+		return "", 0, err
+	}
+	defer conn.Close()
+
+	reply, err := proto.NewBackendClient(conn).TokenizerInfo(ctx, &proto.TokenizerInfoRequest{})
+	if err != nil {
+		return "", 0, fmt.Errorf("backend %s tokenizer info failed: %w", b.address, err)
+	}
+	return reply.TokenizerName, reply.VocabSize, nil
+}
+
+func (b *GRPCBackend) Health(ctx context.Context) bool {
+	conn, err := b.dial()
+	if err != nil {
+		return false
+	}
+	defer conn.Close()
+
+	reply, err := proto.NewBackendClient(conn).Health(ctx, &proto.HealthRequest{})
+	if err != nil {
+		return false
+	}
+	return reply.Ready
+}
+
+// BackendTextGenerator adapts a Backend to the TextGenerator interface,
+// draining its Generate stream into a single string and falling back to
+// the built-in coherent generator when the backend is unreachable or
+// errors, so a misconfigured route degrades gracefully instead of failing
+// the request.
+type BackendTextGenerator struct {
+	model    string
+	backend  Backend
+	fallback TextGenerator
+}
+
+// NewBackendTextGenerator wraps backend for the given model name.
+func NewBackendTextGenerator(model string, backend Backend) *BackendTextGenerator {
+	return &BackendTextGenerator{
+		model:    model,
+		backend:  backend,
+		fallback: NewCoherentTextGenerator(),
+	}
+}
+
+func (g *BackendTextGenerator) GenerateText(ctx context.Context, minLength, maxLength int) string {
+	tokens, err := g.backend.Generate(ctx, g.model, "", int64(maxLength))
+	if err != nil {
+		return g.fallback.GenerateText(ctx, minLength, maxLength)
+	}
+
+	var text strings.Builder
+	for tok := range tokens {
+		text.WriteString(tok.Text)
+	}
+	if text.Len() == 0 {
+		return g.fallback.GenerateText(ctx, minLength, maxLength)
+	}
+	return text.String()
+}
+
+func (g *BackendTextGenerator) GenerateChunk(ctx context.Context) string {
+	return g.fallback.GenerateChunk(ctx)
+}
+
+// BackendRouter resolves a TextGenerator for a requested model name,
+// dispatching to a configured backend when one is registered and falling
+// back to the built-in coherent generator for any unknown model.
+type BackendRouter struct {
+	routes   map[string]Backend
+	fallback TextGenerator
+}
+
+// NewBackendRouter builds a router with no configured routes; every model
+// resolves to the built-in generator until routes are loaded.
+func NewBackendRouter() *BackendRouter {
+	return &BackendRouter{
+		routes:   make(map[string]Backend),
+		fallback: NewCoherentTextGenerator(),
+	}
+}
+
+// NewBackendRouterFromConfig builds a router with one entry per model in
+// cfg: "simulated" models resolve to a SimulatedBackend and "grpc" models
+// dial cfg's address lazily on each request via GRPCBackend.
+func NewBackendRouterFromConfig(cfg *ModelsConfig) (*BackendRouter, error) {
+	router := NewBackendRouter()
+	for _, model := range cfg.Models {
+		switch model.Backend.Type {
+		case "", BackendTypeSimulated:
+			router.routes[model.Name] = NewSimulatedBackend()
+		case BackendTypeGRPC:
+			if model.Backend.Address == "" {
+				return nil, fmt.Errorf("model %q: backend.address is required for type %q", model.Name, BackendTypeGRPC)
+			}
+			router.routes[model.Name] = NewGRPCBackend(model.Backend.Address)
+		default:
+			return nil, fmt.Errorf("model %q: unknown backend type %q", model.Name, model.Backend.Type)
+		}
+	}
+	return router, nil
+}
+
+// Resolve returns the TextGenerator responsible for model, dispatching to a
+// configured backend if one is registered and reachable.
+func (r *BackendRouter) Resolve(model string) TextGenerator {
+	backend, ok := r.routes[model]
+	if !ok {
+		return r.fallback
+	}
+	return NewBackendTextGenerator(model, backend)
+}
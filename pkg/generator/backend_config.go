@@ -0,0 +1,67 @@
+package generator
+
+import (
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Backend type discriminators used in a model's YAML config entry.
+const (
+	BackendTypeSimulated = "simulated"
+	BackendTypeGRPC      = "grpc"
+)
+
+// ModelBackendConfig selects which Backend implementation serves a model:
+// "simulated" (the default) uses the built-in generator, "grpc" dials
+// Address - a bare "host:port" or a "grpc://host:port" URL - as a
+// GRPCBackend.
+type ModelBackendConfig struct {
+	Type    string `yaml:"type"`
+	Address string `yaml:"address,omitempty"`
+
+	// Command is parsed from the config but NewBackendRouterFromConfig
+	// does not run it: a "grpc" model is always dialed at Address, which
+	// must already be serving. NewBackendProcess can start a child process
+	// from such a command.
+	Command []string `yaml:"command,omitempty"`
+}
+
+// ModelConfig maps a model name the simulator serves to the backend that
+// should handle it.
+type ModelConfig struct {
+	Name    string             `yaml:"name"`
+	Backend ModelBackendConfig `yaml:"backend"`
+
+	// Tokenizer selects which tokenizer.Tokenizer implementation (see
+	// tokenizer.ByName) counts tokens for this model: "bpe" (the default) or
+	// "whitespace". Empty, like an unrecognized name, falls back to "bpe".
+	Tokenizer string `yaml:"tokenizer,omitempty"`
+}
+
+// ModelsConfig is the top-level YAML document read by LoadModelsConfig:
+//
+//	models:
+//	  - name: gpt-sim-1
+//	    backend: {type: simulated}
+//	  - name: llama2
+//	    backend: {type: grpc, address: grpc://127.0.0.1:9000}
+//	    tokenizer: whitespace
+type ModelsConfig struct {
+	Models []ModelConfig `yaml:"models"`
+}
+
+// LoadModelsConfig reads and parses a models.yaml-style backend config.
+func LoadModelsConfig(path string) (*ModelsConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read models config: %w", err)
+	}
+
+	var cfg ModelsConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse models config: %w", err)
+	}
+	return &cfg, nil
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// BackendProcess manages an out-of-process GRPCBackend's child process
+// lifecycle, similarly to internal/nanochat.PythonEngine: start it on
+// demand, poll its GRPCBackend.Health until ready, and stop it on command.
+// NewBackendRouterFromConfig does not create one for a ModelsConfig entry's
+// Command; a caller that wants the child process builds and starts it.
+type BackendProcess struct {
+	address string
+	command []string
+	backend *GRPCBackend
+
+	mu        sync.Mutex
+	cmd       *exec.Cmd
+	isRunning bool
+}
+
+// NewBackendProcess builds a manager for the backend listening on address,
+// started by running command (command[0] with command[1:] as arguments).
+func NewBackendProcess(address string, command []string) *BackendProcess {
+	return &BackendProcess{
+		address: address,
+		command: command,
+		backend: NewGRPCBackend(address),
+	}
+}
+
+// Start launches the child process and waits until the backend reports
+// healthy or timeout elapses.
+func (p *BackendProcess) Start(ctx context.Context, timeout time.Duration) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.isRunning {
+		return fmt.Errorf("backend process for %s already running", p.address)
+	}
+	if len(p.command) == 0 {
+		return fmt.Errorf("backend process for %s has no command configured", p.address)
+	}
+
+	cmd := exec.CommandContext(ctx, p.command[0], p.command[1:]...)
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("failed to start backend process for %s: %w", p.address, err)
+	}
+	p.cmd = cmd
+	p.isRunning = true
+
+	if err := p.waitHealthy(ctx, timeout); err != nil {
+		_ = cmd.Process.Kill()
+		p.isRunning = false
+		return fmt.Errorf("backend process for %s failed to become ready: %w", p.address, err)
+	}
+	return nil
+}
+
+// Stop terminates the child process, if running.
+func (p *BackendProcess) Stop() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if !p.isRunning || p.cmd == nil || p.cmd.Process == nil {
+		return nil
+	}
+
+	if err := p.cmd.Process.Kill(); err != nil {
+		return fmt.Errorf("failed to stop backend process for %s: %w", p.address, err)
+	}
+	_ = p.cmd.Wait()
+	p.isRunning = false
+	return nil
+}
+
+// IsRunning reports whether the child process was started and not yet
+// stopped.
+func (p *BackendProcess) IsRunning() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.isRunning
+}
+
+// Backend returns the GRPCBackend dialing this process's address.
+func (p *BackendProcess) Backend() *GRPCBackend {
+	return p.backend
+}
+
+func (p *BackendProcess) waitHealthy(ctx context.Context, timeout time.Duration) error {
+	deadline := time.Now().Add(timeout)
+	ticker := time.NewTicker(200 * time.Millisecond)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			if p.backend.Health(ctx) {
+				return nil
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("not ready after %v", timeout)
+			}
+		}
+	}
+}
@@ -0,0 +1,184 @@
+package generator
+
+import (
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator/proto"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+// fakeBackendServer is a minimal proto.BackendServer used to exercise
+// GRPCBackend against a real gRPC server without depending on an actual
+// model process.
+type fakeBackendServer struct {
+	proto.UnimplementedBackendServer
+}
+
+func (fakeBackendServer) Predict(ctx context.Context, in *proto.PredictRequest) (*proto.Token, error) {
+	return &proto.Token{Text: "hello world", Done: true}, nil
+}
+
+func (fakeBackendServer) PredictStream(in *proto.PredictRequest, stream proto.Backend_PredictStreamServer) error {
+	for _, word := range []string{"hello", " world"} {
+		if err := stream.Send(&proto.Token{Text: word}); err != nil {
+			return err
+		}
+	}
+	return stream.Send(&proto.Token{Done: true})
+}
+
+func (fakeBackendServer) Embed(ctx context.Context, in *proto.EmbedRequest) (*proto.EmbedResponse, error) {
+	return &proto.EmbedResponse{Vector: []float64{0.1, 0.2, 0.3}}, nil
+}
+
+func (fakeBackendServer) Health(ctx context.Context, in *proto.HealthRequest) (*proto.HealthResponse, error) {
+	return &proto.HealthResponse{Ready: true}, nil
+}
+
+func (fakeBackendServer) ModelInfo(ctx context.Context, in *proto.ModelInfoRequest) (*proto.ModelInfoResponse, error) {
+	return &proto.ModelInfoResponse{Name: "fake-model", Version: "1.0"}, nil
+}
+
+func (fakeBackendServer) TokenizerInfo(ctx context.Context, in *proto.TokenizerInfoRequest) (*proto.TokenizerInfoResponse, error) {
+	return &proto.TokenizerInfoResponse{TokenizerName: "bpe", VocabSize: 50257}, nil
+}
+
+// startFakeBackend runs a fakeBackendServer on an OS-assigned port and
+// returns its grpc://host:port address, stopping the server on test cleanup.
+func startFakeBackend(t *testing.T) string {
+	t.Helper()
+
+	lis, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := grpc.NewServer()
+	proto.RegisterBackendServer(srv, fakeBackendServer{})
+	go func() { _ = srv.Serve(lis) }()
+	t.Cleanup(srv.Stop)
+
+	return "grpc://" + lis.Addr().String()
+}
+
+func TestGRPCBackend_GenerateStreamsTokensFromRealServer(t *testing.T) {
+	backend := NewGRPCBackend(startFakeBackend(t))
+
+	tokens, err := backend.Generate(context.Background(), "llama2", "hi", 10)
+	require.NoError(t, err)
+
+	var text string
+	sawDone := false
+	for tok := range tokens {
+		text += tok.Text
+		if tok.Done {
+			sawDone = true
+		}
+	}
+	require.Equal(t, "hello world", text)
+	require.True(t, sawDone)
+}
+
+func TestGRPCBackend_EmbedHealthModelInfoTokenizerInfoAgainstRealServer(t *testing.T) {
+	backend := NewGRPCBackend(startFakeBackend(t))
+	ctx := context.Background()
+
+	vec, err := backend.Embed(ctx, "llama2", "hi")
+	require.NoError(t, err)
+	require.Equal(t, []float64{0.1, 0.2, 0.3}, vec)
+
+	require.True(t, backend.Health(ctx))
+
+	name, version, err := backend.ModelInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "fake-model", name)
+	require.Equal(t, "1.0", version)
+
+	tokenizerName, vocabSize, err := backend.TokenizerInfo(ctx)
+	require.NoError(t, err)
+	require.Equal(t, "bpe", tokenizerName)
+	require.Equal(t, int64(50257), vocabSize)
+}
+
+func TestBackendRouter_FallsBackForUnknownModel(t *testing.T) {
+	router := NewBackendRouter()
+	gen := router.Resolve("no-such-model")
+	text := gen.GenerateText(context.Background(), 10, 100)
+	require.NotEmpty(t, text)
+}
+
+func TestBackendTextGenerator_FallsBackOnUnreachableBackend(t *testing.T) {
+	gen := NewBackendTextGenerator("llama2", NewGRPCBackend("127.0.0.1:0"))
+	text := gen.GenerateText(context.Background(), 10, 100)
+	require.NotEmpty(t, text)
+}
+
+func TestSimulatedBackend_GenerateAndEmbed(t *testing.T) {
+	b := NewSimulatedBackend()
+
+	tokens, err := b.Generate(context.Background(), "gpt-sim-1", "hello", 50)
+	require.NoError(t, err)
+
+	var text string
+	sawDone := false
+	for tok := range tokens {
+		text += tok.Text
+		if tok.Done {
+			sawDone = true
+		}
+	}
+	require.NotEmpty(t, text)
+	require.True(t, sawDone)
+
+	vec1, err := b.Embed(context.Background(), "gpt-sim-1", "hello world")
+	require.NoError(t, err)
+	vec2, err := b.Embed(context.Background(), "gpt-sim-1", "hello world")
+	require.NoError(t, err)
+	require.Equal(t, vec1, vec2) // deterministic for the same input
+
+	require.True(t, b.Health(context.Background()))
+}
+
+func TestLoadModelsConfig_RoutesByBackendType(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "models.yaml")
+	yaml := `
+models:
+  - name: gpt-sim-1
+    backend:
+      type: simulated
+  - name: llama2
+    backend:
+      type: grpc
+      address: 127.0.0.1:9000
+`
+	require.NoError(t, os.WriteFile(path, []byte(yaml), 0o644))
+
+	cfg, err := LoadModelsConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Models, 2)
+
+	router, err := NewBackendRouterFromConfig(cfg)
+	require.NoError(t, err)
+
+	_, ok := router.routes["gpt-sim-1"].(*SimulatedBackend)
+	require.True(t, ok)
+
+	_, ok = router.routes["llama2"].(*GRPCBackend)
+	require.True(t, ok)
+}
+
+func TestNewBackendRouterFromConfig_RejectsUnknownType(t *testing.T) {
+	cfg := &ModelsConfig{Models: []ModelConfig{{Name: "m", Backend: ModelBackendConfig{Type: "bogus"}}}}
+	_, err := NewBackendRouterFromConfig(cfg)
+	require.Error(t, err)
+}
+
+func TestNewBackendRouterFromConfig_RequiresAddressForGRPC(t *testing.T) {
+	cfg := &ModelsConfig{Models: []ModelConfig{{Name: "m", Backend: ModelBackendConfig{Type: BackendTypeGRPC}}}}
+	_, err := NewBackendRouterFromConfig(cfg)
+	require.Error(t, err)
+}
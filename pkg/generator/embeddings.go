@@ -0,0 +1,54 @@
+package generator
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"math/rand"
+)
+
+// defaultEmbeddingDimensions is used for any model not listed in
+// embeddingDimensionsByModel.
+const defaultEmbeddingDimensions = 1536
+
+// embeddingDimensionsByModel mirrors OpenAI's published embedding sizes so a
+// request for a well-known model returns a vector of the length real
+// clients expect.
+var embeddingDimensionsByModel = map[string]int{
+	"text-embedding-3-small": 1536,
+	"text-embedding-3-large": 3072,
+	"text-embedding-ada-002": 1536,
+}
+
+// EmbeddingDimensions resolves the vector length for model, falling back to
+// defaultEmbeddingDimensions for any unrecognized model.
+func EmbeddingDimensions(model string) int {
+	if d, ok := embeddingDimensionsByModel[model]; ok {
+		return d
+	}
+	return defaultEmbeddingDimensions
+}
+
+// GenerateEmbedding returns a deterministic, L2-normalized pseudo-embedding
+// for input: the input's sha256 hash seeds a PRNG, so repeated calls with
+// the same text yield identical vectors - critical for testing vector-store
+// integrations - without needing a real embedding model.
+func GenerateEmbedding(input string, dimensions int) []float64 {
+	sum := sha256.Sum256([]byte(input))
+	seed := int64(binary.BigEndian.Uint64(sum[:8]))
+	rng := rand.New(rand.NewSource(seed))
+
+	vec := make([]float64, dimensions)
+	var norm float64
+	for i := range vec {
+		vec[i] = rng.NormFloat64()
+		norm += vec[i] * vec[i]
+	}
+	norm = math.Sqrt(norm)
+	if norm > 0 {
+		for i := range vec {
+			vec[i] /= norm
+		}
+	}
+	return vec
+}
@@ -0,0 +1,32 @@
+package generator
+
+import (
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateEmbedding_DeterministicForSameInput(t *testing.T) {
+	a := GenerateEmbedding("hello world", 1536)
+	b := GenerateEmbedding("hello world", 1536)
+	require.Equal(t, a, b)
+
+	c := GenerateEmbedding("a different string", 1536)
+	require.NotEqual(t, a, c)
+}
+
+func TestGenerateEmbedding_IsL2Normalized(t *testing.T) {
+	vec := GenerateEmbedding("normalize me", 256)
+	var sumSquares float64
+	for _, v := range vec {
+		sumSquares += v * v
+	}
+	require.InDelta(t, 1.0, math.Sqrt(sumSquares), 1e-9)
+}
+
+func TestEmbeddingDimensions_KnownModelsAndFallback(t *testing.T) {
+	require.Equal(t, 1536, EmbeddingDimensions("text-embedding-3-small"))
+	require.Equal(t, 3072, EmbeddingDimensions("text-embedding-3-large"))
+	require.Equal(t, defaultEmbeddingDimensions, EmbeddingDimensions("some-unknown-model"))
+}
@@ -0,0 +1,286 @@
+package proto
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// This file is the hand-maintained stand-in for what protoc-gen-go-grpc
+// would generate from generator.proto's `service Backend`: a typed client,
+// a server interface, and the ServiceDesc gRPC dispatches on. Keep it in
+// sync with generator.proto by hand - see the package doc comment in
+// messages.go for why it isn't generated.
+
+const (
+	serviceName = "generator.Backend"
+
+	methodPredict       = "/" + serviceName + "/Predict"
+	methodPredictStream = "/" + serviceName + "/PredictStream"
+	methodEmbed         = "/" + serviceName + "/Embed"
+	methodHealth        = "/" + serviceName + "/Health"
+	methodModelInfo     = "/" + serviceName + "/ModelInfo"
+	methodTokenizerInfo = "/" + serviceName + "/TokenizerInfo"
+)
+
+// BackendClient is the client API for the Backend service.
+type BackendClient interface {
+	Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*Token, error)
+	PredictStream(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (Backend_PredictStreamClient, error)
+	Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	ModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfoResponse, error)
+	TokenizerInfo(ctx context.Context, in *TokenizerInfoRequest, opts ...grpc.CallOption) (*TokenizerInfoResponse, error)
+}
+
+type backendClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewBackendClient wraps cc (typically dialed with grpc.NewClient/grpc.Dial)
+// as a BackendClient. It adds no call options of its own: calls carry this
+// package's jsonpb content-subtype only when cc was dialed with DialOption
+// (or a call passes grpc.CallContentSubtype itself).
+func NewBackendClient(cc grpc.ClientConnInterface) BackendClient {
+	return &backendClient{cc: cc}
+}
+
+func (c *backendClient) Predict(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (*Token, error) {
+	out := new(Token)
+	if err := c.cc.Invoke(ctx, methodPredict, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) PredictStream(ctx context.Context, in *PredictRequest, opts ...grpc.CallOption) (Backend_PredictStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &_Backend_PredictStream_streamDesc, methodPredictStream, opts...)
+	if err != nil {
+		return nil, err
+	}
+	cs := &backendPredictStreamClient{stream}
+	if err := cs.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := cs.CloseSend(); err != nil {
+		return nil, err
+	}
+	return cs, nil
+}
+
+func (c *backendClient) Embed(ctx context.Context, in *EmbedRequest, opts ...grpc.CallOption) (*EmbedResponse, error) {
+	out := new(EmbedResponse)
+	if err := c.cc.Invoke(ctx, methodEmbed, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	out := new(HealthResponse)
+	if err := c.cc.Invoke(ctx, methodHealth, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) ModelInfo(ctx context.Context, in *ModelInfoRequest, opts ...grpc.CallOption) (*ModelInfoResponse, error) {
+	out := new(ModelInfoResponse)
+	if err := c.cc.Invoke(ctx, methodModelInfo, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *backendClient) TokenizerInfo(ctx context.Context, in *TokenizerInfoRequest, opts ...grpc.CallOption) (*TokenizerInfoResponse, error) {
+	out := new(TokenizerInfoResponse)
+	if err := c.cc.Invoke(ctx, methodTokenizerInfo, in, out, opts...); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// Backend_PredictStreamClient is the client-side stream handle for
+// PredictStream, yielding one Token per Recv until the stream ends (io.EOF).
+type Backend_PredictStreamClient interface {
+	Recv() (*Token, error)
+	grpc.ClientStream
+}
+
+type backendPredictStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *backendPredictStreamClient) Recv() (*Token, error) {
+	m := new(Token)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// BackendServer is the server API for the Backend service.
+type BackendServer interface {
+	Predict(context.Context, *PredictRequest) (*Token, error)
+	PredictStream(*PredictRequest, Backend_PredictStreamServer) error
+	Embed(context.Context, *EmbedRequest) (*EmbedResponse, error)
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	ModelInfo(context.Context, *ModelInfoRequest) (*ModelInfoResponse, error)
+	TokenizerInfo(context.Context, *TokenizerInfoRequest) (*TokenizerInfoResponse, error)
+}
+
+// UnimplementedBackendServer can be embedded in a BackendServer
+// implementation to satisfy the interface for RPCs it doesn't need to
+// override, returning codes.Unimplemented for any call that reaches it.
+type UnimplementedBackendServer struct{}
+
+func (UnimplementedBackendServer) Predict(context.Context, *PredictRequest) (*Token, error) {
+	return nil, status.Error(codes.Unimplemented, "method Predict not implemented")
+}
+
+func (UnimplementedBackendServer) PredictStream(*PredictRequest, Backend_PredictStreamServer) error {
+	return status.Error(codes.Unimplemented, "method PredictStream not implemented")
+}
+
+func (UnimplementedBackendServer) Embed(context.Context, *EmbedRequest) (*EmbedResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Embed not implemented")
+}
+
+func (UnimplementedBackendServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
+}
+
+func (UnimplementedBackendServer) ModelInfo(context.Context, *ModelInfoRequest) (*ModelInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ModelInfo not implemented")
+}
+
+func (UnimplementedBackendServer) TokenizerInfo(context.Context, *TokenizerInfoRequest) (*TokenizerInfoResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method TokenizerInfo not implemented")
+}
+
+// Backend_PredictStreamServer is the server-side stream handle for
+// PredictStream; the handler calls Send once per generated token.
+type Backend_PredictStreamServer interface {
+	Send(*Token) error
+	grpc.ServerStream
+}
+
+type backendPredictStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *backendPredictStreamServer) Send(m *Token) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func _Backend_Predict_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(PredictRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Predict(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: methodPredict}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(BackendServer).Predict(ctx, req.(*PredictRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_PredictStream_Handler(srv any, stream grpc.ServerStream) error {
+	m := new(PredictRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(BackendServer).PredictStream(m, &backendPredictStreamServer{stream})
+}
+
+func _Backend_Embed_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(EmbedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Embed(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: methodEmbed}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(BackendServer).Embed(ctx, req.(*EmbedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_Health_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: methodHealth}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(BackendServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_ModelInfo_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(ModelInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).ModelInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: methodModelInfo}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(BackendServer).ModelInfo(ctx, req.(*ModelInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Backend_TokenizerInfo_Handler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(TokenizerInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(BackendServer).TokenizerInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{Server: srv, FullMethod: methodTokenizerInfo}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(BackendServer).TokenizerInfo(ctx, req.(*TokenizerInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var _Backend_PredictStream_streamDesc = grpc.StreamDesc{
+	StreamName:    "PredictStream",
+	Handler:       _Backend_PredictStream_Handler,
+	ServerStreams: true,
+}
+
+// BackendServiceDesc is the ServiceDesc RegisterBackendServer hands to the
+// grpc.Server; exported so tests and alternate transports can inspect it.
+var BackendServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*BackendServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Predict", Handler: _Backend_Predict_Handler},
+		{MethodName: "Embed", Handler: _Backend_Embed_Handler},
+		{MethodName: "Health", Handler: _Backend_Health_Handler},
+		{MethodName: "ModelInfo", Handler: _Backend_ModelInfo_Handler},
+		{MethodName: "TokenizerInfo", Handler: _Backend_TokenizerInfo_Handler},
+	},
+	Streams:  []grpc.StreamDesc{_Backend_PredictStream_streamDesc},
+	Metadata: "generator.proto",
+}
+
+// RegisterBackendServer registers srv's Backend implementation on s.
+func RegisterBackendServer(s grpc.ServiceRegistrar, srv BackendServer) {
+	s.RegisterService(&BackendServiceDesc, srv)
+}
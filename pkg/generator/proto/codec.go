@@ -0,0 +1,39 @@
+package proto
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is registered as a gRPC content-subtype so BackendClient/
+// BackendServer traffic is tagged "application/grpc+jsonpb" instead of the
+// default "application/grpc+proto" - a real gRPC call negotiated over real
+// HTTP/2 framing, just carrying JSON-encoded messages instead of protobuf
+// binary, since the message structs in this package aren't
+// protoc-generated proto.Message implementations.
+const codecName = "jsonpb"
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return codecName }
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+// DialOption tags every call made over the resulting connection with this
+// package's jsonpb content-subtype, so the grpc.Server on the other end
+// picks jsonCodec instead of grpc-go's default protobuf-binary codec.
+func DialOption() grpc.DialOption {
+	return grpc.WithDefaultCallOptions(grpc.CallContentSubtype(codecName))
+}
@@ -0,0 +1,62 @@
+// Package proto holds the wire types and gRPC service scaffolding for the
+// Backend RPC contract documented in generator.proto. This repo has no
+// protoc/protoc-gen-go-grpc toolchain available, so the message structs and
+// the BackendClient/BackendServer scaffolding below are maintained by hand
+// instead of generated; they mirror generator.proto field-for-field and are
+// kept in sync with it by hand whenever the .proto changes. Wire encoding
+// uses jsonCodec (see codec.go) rather than protobuf binary, since that
+// requires generated Marshal/Unmarshal code too - but the transport,
+// service/method dispatch, and streaming semantics are real grpc-go, not a
+// simulation of it.
+package proto
+
+// PredictRequest mirrors the proto message of the same name.
+type PredictRequest struct {
+	Model       string  `json:"model"`
+	Prompt      string  `json:"prompt"`
+	MaxTokens   int64   `json:"max_tokens"`
+	Temperature float64 `json:"temperature"`
+}
+
+// Token mirrors the proto message of the same name.
+type Token struct {
+	Text string `json:"text"`
+	Done bool   `json:"done"`
+}
+
+// EmbedRequest mirrors the proto message of the same name.
+type EmbedRequest struct {
+	Model string `json:"model"`
+	Input string `json:"input"`
+}
+
+// EmbedResponse mirrors the proto message of the same name.
+type EmbedResponse struct {
+	Vector []float64 `json:"vector"`
+}
+
+// HealthRequest mirrors the proto message of the same name.
+type HealthRequest struct{}
+
+// HealthResponse mirrors the proto message of the same name.
+type HealthResponse struct {
+	Ready bool `json:"ready"`
+}
+
+// ModelInfoRequest mirrors the proto message of the same name.
+type ModelInfoRequest struct{}
+
+// ModelInfoResponse mirrors the proto message of the same name.
+type ModelInfoResponse struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// TokenizerInfoRequest mirrors the proto message of the same name.
+type TokenizerInfoRequest struct{}
+
+// TokenizerInfoResponse mirrors the proto message of the same name.
+type TokenizerInfoResponse struct {
+	TokenizerName string `json:"tokenizer_name"`
+	VocabSize     int64  `json:"vocab_size"`
+}
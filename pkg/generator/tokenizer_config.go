@@ -0,0 +1,28 @@
+package generator
+
+import (
+	"log"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
+)
+
+// NewTokenizerRouterFromConfig builds a tokenizer.Router with one entry per
+// model in cfg that names a Tokenizer, mirroring NewBackendRouterFromConfig's
+// shape so the same backends YAML doubles as the per-model tokenizer config.
+// A model with no Tokenizer entry, or an unrecognized one, is left to the
+// router's default (bpe).
+func NewTokenizerRouterFromConfig(cfg *ModelsConfig) *tokenizer.Router {
+	router := tokenizer.NewRouter()
+	for _, model := range cfg.Models {
+		if model.Tokenizer == "" {
+			continue
+		}
+		t := tokenizer.ByName(model.Tokenizer)
+		if t == nil {
+			log.Printf("model %q: unrecognized tokenizer %q; using the default", model.Name, model.Tokenizer)
+			continue
+		}
+		router.Register(model.Name, t)
+	}
+	return router
+}
@@ -0,0 +1,35 @@
+package generator
+
+import (
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewTokenizerRouterFromConfig_RoutesByName(t *testing.T) {
+	cfg := &ModelsConfig{Models: []ModelConfig{
+		{Name: "gpt-sim-1", Tokenizer: "whitespace"},
+		{Name: "llama2"},
+	}}
+
+	router := NewTokenizerRouterFromConfig(cfg)
+
+	_, ok := router.Resolve("gpt-sim-1").(*tokenizer.WhitespaceTokenizer)
+	require.True(t, ok)
+
+	_, ok = router.Resolve("llama2").(*tokenizer.BPETokenizer)
+	require.True(t, ok)
+
+	_, ok = router.Resolve("unconfigured-model").(*tokenizer.BPETokenizer)
+	require.True(t, ok)
+}
+
+func TestNewTokenizerRouterFromConfig_UnrecognizedNameFallsBackToDefault(t *testing.T) {
+	cfg := &ModelsConfig{Models: []ModelConfig{{Name: "m", Tokenizer: "gguf-vocab"}}}
+
+	router := NewTokenizerRouterFromConfig(cfg)
+
+	_, ok := router.Resolve("m").(*tokenizer.BPETokenizer)
+	require.True(t, ok)
+}
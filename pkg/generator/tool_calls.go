@@ -0,0 +1,83 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// Float64Sampler is the minimal random source ShouldEmitToolCalls needs. A
+// plain *rand.Rand satisfies it for the non-streaming path; the streaming
+// package's per-request, mutex-guarded streamRand satisfies it too, so both
+// paths share this one decision while each keeps its own RNG lifetime.
+type Float64Sampler interface {
+	Float64() float64
+}
+
+// ShouldEmitToolCalls decides, given Tools is non-empty, whether a
+// completion should actually emit tool_calls: "always" and "never" are
+// fixed outcomes, "probability" rolls probability against rng so a
+// request's seed reproduces the decision identically whether the request is
+// streamed or not. An empty strategy defaults to "always", the simulator's
+// historical behavior.
+func ShouldEmitToolCalls(strategy string, probability float64, rng Float64Sampler) bool {
+	switch strategy {
+	case "never":
+		return false
+	case "probability":
+		return rng.Float64() < probability
+	default:
+		return true
+	}
+}
+
+// NewSeededRand returns a *rand.Rand seeded from seed, or from the current
+// time when seed is 0, so an unseeded request keeps non-deterministic
+// output while a seeded one reproduces its ShouldEmitToolCalls draw - the
+// non-streaming counterpart to pkg/streaming's per-request streamRand,
+// which seeds the same draw for the streaming path.
+func NewSeededRand(seed int64) *rand.Rand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return rand.New(rand.NewSource(seed))
+}
+
+// ToolResultReply builds a deterministic assistant reply that references
+// the outputs of a trailing run of role:"tool" messages - a client
+// executing the model's prior tool_calls and replying with their outputs in
+// a follow-up request. It mirrors SSEStreamHandler.runToolbox's wording for
+// the same "agent loop" shape, but here the results come from the client's
+// messages instead of a locally configured Toolbox. Shared by the
+// streaming and non-streaming chat completion paths so both render the same
+// reply for the same messages.
+func ToolResultReply(messages []models.ChatCompletionMessage) (string, bool) {
+	var toolMsgs []models.ChatCompletionMessage
+	for i := len(messages) - 1; i >= 0 && messages[i].Role == "tool"; i-- {
+		toolMsgs = append([]models.ChatCompletionMessage{messages[i]}, toolMsgs...)
+	}
+	if len(toolMsgs) == 0 {
+		return "", false
+	}
+
+	names := make(map[string]string)
+	for _, m := range messages {
+		for _, call := range m.ToolCalls {
+			names[call.ID] = call.Function.Name
+		}
+	}
+
+	results := make([]string, 0, len(toolMsgs))
+	for _, m := range toolMsgs {
+		content := strings.TrimSpace(m.Content)
+		if name := names[m.ToolCallID]; name != "" {
+			results = append(results, fmt.Sprintf("%s returned: %s", name, content))
+		} else {
+			results = append(results, content)
+		}
+	}
+	return "Based on the tool results, " + strings.Join(results, " ") + ".", true
+}
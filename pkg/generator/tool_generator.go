@@ -5,9 +5,16 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/rand"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+	"unicode"
 
+	"github.com/quantalogic/openai-api-simulator/pkg/grammar"
 	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/schema"
 	"github.com/quantalogic/openai-api-simulator/pkg/utils"
 )
 
@@ -15,9 +22,11 @@ import (
 type ToolCallStrategy string
 
 const (
-	StrategySequence   ToolCallStrategy = "sequence"
-	StrategyContextual ToolCallStrategy = "contextual"
-	StrategyRandom     ToolCallStrategy = "random"
+	StrategySequence    ToolCallStrategy = "sequence"
+	StrategyContextual  ToolCallStrategy = "contextual"
+	StrategyRandom      ToolCallStrategy = "random"
+	StrategyFirst       ToolCallStrategy = "first"
+	StrategyAllRequired ToolCallStrategy = "all-required"
 )
 
 // ToolDefinition describes a tool we can simulate.
@@ -26,25 +35,54 @@ type ToolDefinition struct {
 	Type     string
 }
 
+// Defaults for StrategyContextual's scoring: tuned so a tool sharing a
+// handful of words with the prompt still surfaces a call, while a toolset
+// with nothing in common yields none.
+const (
+	defaultContextualThreshold = 0.2
+	defaultContextualMaxCalls  = 3
+)
+
 // ToolCallGenerator produces simulated function call output.
 type ToolCallGenerator struct {
 	rand  *rand.Rand
 	idGen *utils.IDGenerator
+
+	// contextualThreshold and contextualMaxCalls tune StrategyContextual:
+	// a tool must score at least contextualThreshold to be called, and at
+	// most contextualMaxCalls tools are called per turn.
+	contextualThreshold float64
+	contextualMaxCalls  int
 }
 
 // NewToolCallGenerator builds a generator ready to create calls.
 func NewToolCallGenerator() *ToolCallGenerator {
 	return &ToolCallGenerator{
-		rand:  rand.New(rand.NewSource(time.Now().UnixNano())),
-		idGen: utils.NewIDGenerator(),
+		rand:                rand.New(rand.NewSource(time.Now().UnixNano())),
+		idGen:               utils.NewIDGenerator(),
+		contextualThreshold: defaultContextualThreshold,
+		contextualMaxCalls:  defaultContextualMaxCalls,
 	}
 }
 
+// NewToolCallGeneratorWithContextualConfig builds a generator whose
+// StrategyContextual threshold and call cap are overridden, so agent-loop
+// tests can dial contextual tool-selection realism up or down.
+func NewToolCallGeneratorWithContextualConfig(threshold float64, maxCalls int) *ToolCallGenerator {
+	g := NewToolCallGenerator()
+	g.contextualThreshold = threshold
+	g.contextualMaxCalls = maxCalls
+	return g
+}
+
 // GenerateToolCalls fabricates tool calls using the given strategy.
+// messages is only consulted by StrategyContextual, which scores tools
+// against the conversation; other strategies ignore it.
 func (g *ToolCallGenerator) GenerateToolCalls(
 	ctx context.Context,
 	tools []ToolDefinition,
 	strategy ToolCallStrategy,
+	messages []models.ChatCompletionMessage,
 ) ([]models.ChatCompletionMessageToolCall, error) {
 	if len(tools) == 0 {
 		return nil, nil
@@ -54,12 +92,62 @@ func (g *ToolCallGenerator) GenerateToolCalls(
 	case StrategySequence:
 		return g.sequenceCalls(tools), nil
 	case StrategyContextual:
-		return g.contextualCalls(tools), nil
+		return g.contextualCalls(tools, messages), nil
+	case StrategyFirst:
+		return g.sequenceCalls(tools[:1]), nil
+	case StrategyAllRequired:
+		return g.sequenceCalls(tools), nil
 	default:
 		return g.randomCalls(tools), nil
 	}
 }
 
+// GenerateToolCallsForChoice synthesizes tool calls honoring an OpenAI
+// `tool_choice` value: "none" suppresses calls, "auto"/"required"/nil
+// fall back to strategy, and an object naming a function
+// (`{"type":"function","function":{"name":"..."}}`) restricts generation to
+// just that tool. When parallel is false, at most one call is returned.
+func (g *ToolCallGenerator) GenerateToolCallsForChoice(
+	ctx context.Context,
+	tools []ToolDefinition,
+	toolChoice interface{},
+	strategy ToolCallStrategy,
+	parallel bool,
+	messages []models.ChatCompletionMessage,
+) ([]models.ChatCompletionMessageToolCall, error) {
+	if len(tools) == 0 {
+		return nil, nil
+	}
+
+	switch v := toolChoice.(type) {
+	case string:
+		if v == "none" {
+			return nil, nil
+		}
+	case map[string]interface{}:
+		if v["type"] == "function" {
+			if fn, ok := v["function"].(map[string]interface{}); ok {
+				if name, ok := fn["name"].(string); ok {
+					for _, tool := range tools {
+						if tool.Function.Name == name {
+							return []models.ChatCompletionMessageToolCall{g.generateCall(tool)}, nil
+						}
+					}
+				}
+			}
+		}
+	}
+
+	calls, err := g.GenerateToolCalls(ctx, tools, strategy, messages)
+	if err != nil || !parallel || len(calls) <= 1 {
+		if len(calls) > 1 && !parallel {
+			calls = calls[:1]
+		}
+		return calls, err
+	}
+	return calls, nil
+}
+
 func (g *ToolCallGenerator) sequenceCalls(tools []ToolDefinition) []models.ChatCompletionMessageToolCall {
 	var calls []models.ChatCompletionMessageToolCall
 	for _, tool := range tools {
@@ -68,16 +156,260 @@ func (g *ToolCallGenerator) sequenceCalls(tools []ToolDefinition) []models.ChatC
 	return calls
 }
 
-func (g *ToolCallGenerator) contextualCalls(tools []ToolDefinition) []models.ChatCompletionMessageToolCall {
-	var calls []models.ChatCompletionMessageToolCall
+// contextualCalls scores each tool against the latest user/system message
+// and calls every tool whose score clears g.contextualThreshold, ordered by
+// descending score and capped at g.contextualMaxCalls. A tool's score is
+// the fraction of its own vocabulary (name, description, parameter names)
+// that also appears in the prompt's vocabulary, after case-folding,
+// stop-word filtering, and light stemming.
+func (g *ToolCallGenerator) contextualCalls(tools []ToolDefinition, messages []models.ChatCompletionMessage) []models.ChatCompletionMessageToolCall {
+	prompt := latestUserOrSystemContent(messages)
+	promptTokens := contextualVocabulary(contextualTokens(prompt))
+	if len(promptTokens) == 0 {
+		return nil
+	}
+
+	type scoredTool struct {
+		tool  ToolDefinition
+		score float64
+	}
+	candidates := make([]scoredTool, 0, len(tools))
 	for _, tool := range tools {
-		if g.rand.Float64() < 0.5 {
-			calls = append(calls, g.generateCall(tool))
+		if score := scoreToolAgainstPrompt(tool, promptTokens); score >= g.contextualThreshold {
+			candidates = append(candidates, scoredTool{tool, score})
 		}
 	}
+	sort.SliceStable(candidates, func(i, j int) bool { return candidates[i].score > candidates[j].score })
+
+	if g.contextualMaxCalls > 0 && len(candidates) > g.contextualMaxCalls {
+		candidates = candidates[:g.contextualMaxCalls]
+	}
+
+	calls := make([]models.ChatCompletionMessageToolCall, 0, len(candidates))
+	for _, c := range candidates {
+		calls = append(calls, models.ChatCompletionMessageToolCall{
+			ID:   g.idGen.GenerateToolCallID(),
+			Type: nonEmpty(c.tool.Type, "function"),
+			Function: models.ChatCompletionMessageToolCallFunction{
+				Name:      c.tool.Function.Name,
+				Arguments: g.generateContextualArguments(c.tool, prompt),
+			},
+		})
+	}
 	return calls
 }
 
+// latestUserOrSystemContent returns the content of the most recent user or
+// system message, the turn a contextual strategy should be reacting to.
+func latestUserOrSystemContent(messages []models.ChatCompletionMessage) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == "user" || messages[i].Role == "system" {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
+
+// contextualStopWords are dropped before scoring so common glue words don't
+// inflate a tool's match score.
+var contextualStopWords = map[string]bool{
+	"a": true, "an": true, "the": true, "is": true, "are": true, "was": true,
+	"were": true, "to": true, "of": true, "in": true, "on": true, "at": true,
+	"for": true, "and": true, "or": true, "it": true, "this": true, "that": true,
+	"with": true, "me": true, "my": true, "i": true, "you": true, "your": true,
+	"please": true, "can": true, "could": true, "would": true, "do": true,
+	"does": true, "what": true, "how": true, "be": true,
+}
+
+// contextualTokens case-folds text, splits on non-alphanumeric runes, drops
+// stop words, and lightly stems the rest so e.g. "weather", "weathers", and
+// "weathered" all normalize to the same token for matching.
+func contextualTokens(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	tokens := make([]string, 0, len(fields))
+	for _, f := range fields {
+		if contextualStopWords[f] {
+			continue
+		}
+		tokens = append(tokens, stem(f))
+	}
+	return tokens
+}
+
+// stem trims a handful of common suffixes. It's a deliberately small
+// heuristic, not a real stemmer (Porter et al.) - good enough to fold
+// "searches"/"searching" onto "search" for tool-name matching.
+func stem(word string) string {
+	for _, suffix := range []string{"ing", "ed", "es", "s"} {
+		if strings.HasSuffix(word, suffix) && len(word) > len(suffix)+2 {
+			return strings.TrimSuffix(word, suffix)
+		}
+	}
+	return word
+}
+
+func contextualVocabulary(tokens []string) map[string]bool {
+	set := make(map[string]bool, len(tokens))
+	for _, t := range tokens {
+		set[t] = true
+	}
+	return set
+}
+
+// toolVocabulary collects the distinct stemmed tokens describing a tool:
+// its name, description, and parameter names.
+func toolVocabulary(tool ToolDefinition) map[string]bool {
+	words := contextualTokens(strings.ReplaceAll(tool.Function.Name, "_", " "))
+	words = append(words, contextualTokens(tool.Function.Description)...)
+	words = append(words, contextualTokens(strings.Join(parameterNames(tool.Function.Parameters), " "))...)
+	return contextualVocabulary(words)
+}
+
+func parameterNames(raw json.RawMessage) []string {
+	if len(raw) == 0 {
+		return nil
+	}
+	var sch schema.Schema
+	if err := json.Unmarshal(raw, &sch); err != nil {
+		return nil
+	}
+	names := make([]string, 0, len(sch.Properties))
+	for name := range sch.Properties {
+		names = append(names, name)
+	}
+	return names
+}
+
+// scoreToolAgainstPrompt is the fraction of a tool's own vocabulary that
+// also shows up in the prompt's vocabulary, so a short, highly specific
+// tool name (e.g. "get_weather") scores as high as a long one with only a
+// couple of matching words.
+func scoreToolAgainstPrompt(tool ToolDefinition, promptTokens map[string]bool) float64 {
+	toolWords := toolVocabulary(tool)
+	if len(toolWords) == 0 {
+		return 0
+	}
+	matches := 0
+	for w := range toolWords {
+		if promptTokens[w] {
+			matches++
+		}
+	}
+	return float64(matches) / float64(len(toolWords))
+}
+
+// quotedEntityPattern, numberEntityPattern, and dateTimeEntityPattern pull
+// plausible argument values out of free-form prompt text.
+var (
+	quotedEntityPattern   = regexp.MustCompile(`"([^"]+)"|'([^']+)'`)
+	numberEntityPattern   = regexp.MustCompile(`-?\d+(\.\d+)?`)
+	dateTimeEntityPattern = regexp.MustCompile(`\d{4}-\d{2}-\d{2}(T\d{2}:\d{2}(:\d{2})?(Z|[+-]\d{2}:\d{2})?)?`)
+)
+
+func quotedEntities(prompt string) []string {
+	matches := quotedEntityPattern.FindAllStringSubmatch(prompt, -1)
+	out := make([]string, 0, len(matches))
+	for _, m := range matches {
+		if m[1] != "" {
+			out = append(out, m[1])
+		} else {
+			out = append(out, m[2])
+		}
+	}
+	return out
+}
+
+func dateTimeEntities(prompt string) []string {
+	return dateTimeEntityPattern.FindAllString(prompt, -1)
+}
+
+// numberEntities excludes numbers that are part of a date-time entity so a
+// date like "2024-05-01" isn't also consumed as the plain number 2024.
+func numberEntities(prompt string) []string {
+	withoutDates := dateTimeEntityPattern.ReplaceAllString(prompt, "")
+	return numberEntityPattern.FindAllString(withoutDates, -1)
+}
+
+func parseNumberEntity(token string, integer bool) interface{} {
+	if integer {
+		if n, err := strconv.ParseInt(token, 10, 64); err == nil {
+			return n
+		}
+	}
+	if f, err := strconv.ParseFloat(token, 64); err == nil {
+		return f
+	}
+	return token
+}
+
+// generateContextualArguments populates a tool's parameters from entities
+// extracted out of the prompt: quoted strings feed string params, bare
+// numbers feed integer/number params, and ISO-8601-looking tokens feed
+// date-time formatted params. A property with no matching entity falls
+// back to schema sampling, same as the other strategies, but only when
+// it's required - StrategyContextual otherwise prefers to omit an optional
+// field over guessing it.
+func (g *ToolCallGenerator) generateContextualArguments(tool ToolDefinition, prompt string) string {
+	raw := tool.Function.Parameters
+	if len(raw) == 0 {
+		return "{}"
+	}
+	var root schema.Schema
+	if err := json.Unmarshal(raw, &root); err != nil {
+		return "{}"
+	}
+	if len(root.Properties) == 0 {
+		return "{}"
+	}
+
+	quotes := quotedEntities(prompt)
+	numbers := numberEntities(prompt)
+	dates := dateTimeEntities(prompt)
+
+	required := make(map[string]bool, len(root.Required))
+	for _, name := range root.Required {
+		required[name] = true
+	}
+
+	args := make(map[string]interface{}, len(root.Properties))
+	for name, prop := range root.Properties {
+		switch {
+		case (prop.Format == "date-time" || prop.Format == "date") && len(dates) > 0:
+			args[name] = dates[0]
+			dates = dates[1:]
+			continue
+		case (prop.Type == "integer" || prop.Type == "number") && len(numbers) > 0:
+			args[name] = parseNumberEntity(numbers[0], prop.Type == "integer")
+			numbers = numbers[1:]
+			continue
+		case prop.Type == "string" && len(quotes) > 0:
+			args[name] = quotes[0]
+			quotes = quotes[1:]
+			continue
+		}
+
+		if !required[name] {
+			continue
+		}
+		sampled, err := schema.Sample(prop, schema.SampleOptions{Seed: g.rand.Int63()})
+		if err != nil {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal(sampled, &value); err == nil {
+			args[name] = value
+		}
+	}
+
+	out, err := json.Marshal(args)
+	if err != nil {
+		return "{}"
+	}
+	return string(out)
+}
+
 func (g *ToolCallGenerator) randomCalls(tools []ToolDefinition) []models.ChatCompletionMessageToolCall {
 	num := g.rand.Intn(len(tools)) + 1
 	return g.sequenceCalls(shuffleTools(tools)[:num])
@@ -107,104 +439,135 @@ func (g *ToolCallGenerator) generateJSONArguments(raw json.RawMessage) string {
 		return "{}"
 	}
 
-	var schema map[string]interface{}
-	if err := json.Unmarshal(raw, &schema); err != nil {
+	var root schema.Schema
+	if err := json.Unmarshal(raw, &root); err != nil {
 		return "{}"
 	}
-
-	properties, ok := schema["properties"].(map[string]interface{})
-	if !ok {
+	if len(root.Properties) == 0 {
 		return "{}"
 	}
 
-	result := make(map[string]interface{})
-	for name := range properties {
-		result[name] = g.fakeJSONValue()
-	}
-
-	data, err := json.Marshal(result)
+	out, err := schema.Sample(root, schema.SampleOptions{Seed: g.rand.Int63()})
 	if err != nil {
 		return "{}"
 	}
-	return string(data)
+	return string(out)
 }
 
-// GenerateStructuredOutput generates a JSON string that matches the given JSONSchema definition.
-// This is used by the simulator to produce structured outputs for response_format: json_schema.
-func (g *ToolCallGenerator) GenerateStructuredOutput(schema models.JSONSchema) (string, error) {
-	// Only handle object schemas for MVP
-	if schema.Type != "object" {
-		// fallback to empty object
-		return "{}", nil
+// GenerateStructuredOutput generates a JSON string that matches the given
+// JSON Schema document (raw bytes) by compiling it into a pkg/grammar
+// Grammar and generating character-by-character from it - at each step
+// choosing only from the runes the grammar's current state permits, so
+// the result is schema-valid JSON by construction rather than by
+// sampling a Go value and marshaling it. This is used to produce
+// structured outputs for `response_format: json_schema`. When the schema
+// cannot be satisfied (a required field's `$ref` does not resolve), it
+// returns a non-empty refusal string instead of an error, mirroring the
+// `refusal` field OpenAI returns on the message.
+func (g *ToolCallGenerator) GenerateStructuredOutput(raw json.RawMessage) (content string, refusal string, err error) {
+	var root schema.Schema
+	if len(raw) == 0 {
+		return "{}", "", nil
 	}
-
-	// Build output map
-	result := make(map[string]interface{})
-
-	for name, prop := range schema.Properties {
-		// Always include required fields, optionally include non-required fields randomly
-		if contains(schema.Required, name) || g.rand.Float32() > 0.2 {
-			result[name] = g.generateValue(prop)
-		}
+	if uErr := json.Unmarshal(raw, &root); uErr != nil {
+		return "{}", "", nil
+	}
+	if root.Type != "object" {
+		return "{}", "", nil
 	}
 
-	out, err := json.Marshal(result)
-	if err != nil {
-		return "", fmt.Errorf("failed to marshal structured output: %w", err)
+	gram, cErr := grammar.Compile(root)
+	if cErr != nil {
+		return "", fmt.Sprintf("the requested response_format cannot be satisfied: %v", cErr), nil
+	}
+	out, gErr := gram.GenerateString(g.rand.Int63())
+	if gErr != nil {
+		return "", fmt.Sprintf("the requested response_format cannot be satisfied: %v", gErr), nil
 	}
-	return string(out), nil
+	return out, "", nil
 }
 
-func contains(slice []string, s string) bool {
-	for _, v := range slice {
-		if v == s {
-			return true
-		}
+// extractJSONSchemaBytes pulls the schema document out of a
+// `response_format: {"type":"json_schema", json_schema: ...}` value. The
+// client may nest the schema under a "schema" key
+// (`{"json_schema":{"name":"...","schema":{...}}}`) or provide it
+// directly; it tries the nested form first. ok is false when rf has no
+// `json_schema` key at all.
+func extractJSONSchemaBytes(rf map[string]interface{}) (schemaBytes json.RawMessage, ok bool) {
+	js, ok := rf["json_schema"]
+	if !ok {
+		return nil, false
+	}
+	b, _ := json.Marshal(js)
+	var wrapper struct {
+		Schema json.RawMessage `json:"schema"`
+	}
+	schemaBytes = b
+	if err := json.Unmarshal(b, &wrapper); err == nil && len(wrapper.Schema) > 0 {
+		schemaBytes = wrapper.Schema
 	}
-	return false
+	return schemaBytes, true
 }
 
-// generateValue creates a fake value matching the property definition.
-func (g *ToolCallGenerator) generateValue(prop models.PropertyDef) interface{} {
-	switch prop.Type {
-	case "string":
-		if len(prop.Enum) > 0 {
-			// choose one
-			if v, ok := prop.Enum[0].(string); ok {
-				return v
-			}
+// ResolveResponseFormat interprets an OpenAI `response_format` value
+// ("json_schema" or "json_object") and returns the structured content - or
+// a refusal, via GenerateStructuredOutput - it resolves to. Any other shape
+// (nil, plain prose, an unrecognized type) returns two empty strings so the
+// caller falls back to its own text generation. Shared by the non-streaming
+// and streaming completion paths so response_format is honored identically
+// by both.
+func (g *ToolCallGenerator) ResolveResponseFormat(responseFormat interface{}) (content, refusal string) {
+	rf, ok := responseFormat.(map[string]interface{})
+	if !ok {
+		return "", ""
+	}
+	switch rf["type"] {
+	case "json_schema":
+		schemaBytes, ok := extractJSONSchemaBytes(rf)
+		if !ok {
+			return "", ""
+		}
+		out, ref, err := g.GenerateStructuredOutput(schemaBytes)
+		if err != nil {
+			return "", ""
 		}
-		return fmt.Sprintf("str-%d", g.rand.Intn(1000))
-	case "number", "float":
-		return g.rand.Float64() * 100
-	case "integer":
-		return g.rand.Intn(100)
-	case "boolean":
-		return g.rand.Intn(2) == 0
-	case "array":
-		// simple array of strings
-		return []string{fmt.Sprintf("item%d", g.rand.Intn(10))}
-	case "object":
-		// nested objects: include minimal keys
-		out := map[string]interface{}{}
-		for k, p := range prop.Properties {
-			out[k] = g.generateValue(p)
-		}
-		return out
+		return out, ref
+	case "json_object":
+		return `{"result":"ok"}`, ""
 	default:
-		return fmt.Sprintf("val-%d", g.rand.Intn(1000))
+		return "", ""
 	}
 }
 
-func (g *ToolCallGenerator) fakeJSONValue() interface{} {
-	switch g.rand.Intn(3) {
-	case 0:
-		return fmt.Sprintf("value-%d", g.rand.Intn(1000))
-	case 1:
-		return g.rand.Float64() * 100
-	default:
-		return g.rand.Intn(2) == 0
+// ResolveResponseFormatGrammar mirrors ResolveResponseFormat for
+// "json_schema" response formats, but returns the compiled grammar
+// instead of an already-generated string, so the streaming path can walk
+// it character-by-character and forward fragments as content deltas as
+// they're decided instead of slicing a complete string. matched is false
+// for anything that isn't a "json_schema" response format resolving to
+// an object-rooted schema - including "json_object", whose response is a
+// fixed literal not worth compiling a grammar for - so callers should
+// fall back to ResolveResponseFormat in that case.
+func (g *ToolCallGenerator) ResolveResponseFormatGrammar(responseFormat interface{}) (gram *grammar.Grammar, refusal string, matched bool) {
+	rf, ok := responseFormat.(map[string]interface{})
+	if !ok || rf["type"] != "json_schema" {
+		return nil, "", false
+	}
+	schemaBytes, ok := extractJSONSchemaBytes(rf)
+	if !ok || len(schemaBytes) == 0 {
+		return nil, "", false
+	}
+
+	var root schema.Schema
+	if err := json.Unmarshal(schemaBytes, &root); err != nil || root.Type != "object" {
+		return nil, "", false
+	}
+
+	gr, err := grammar.Compile(root)
+	if err != nil {
+		return nil, fmt.Sprintf("the requested response_format cannot be satisfied: %v", err), true
 	}
+	return gr, "", true
 }
 
 func nonEmpty(value, fallback string) string {
@@ -4,7 +4,7 @@ import (
 	"context"
 	"testing"
 
-	"github.com/openai/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
 	"github.com/stretchr/testify/require"
 )
 
@@ -15,25 +15,193 @@ func TestGenerateToolCalls(t *testing.T) {
 		{Function: models.FunctionDefinition{Name: "do_b"}, Type: "function"},
 	}
 
-	calls, err := g.GenerateToolCalls(context.Background(), tools, StrategyRandom)
+	calls, err := g.GenerateToolCalls(context.Background(), tools, StrategyRandom, nil)
 	require.NoError(t, err)
 	require.True(t, len(calls) >= 1)
 }
 
-func TestGenerateStructuredOutput(t *testing.T) {
+func TestGenerateToolCalls_ContextualPicksRelevantTool(t *testing.T) {
 	g := NewToolCallGenerator()
-	schema := models.JSONSchema{
-		Type: "object",
-		Properties: map[string]models.PropertyDef{
-			"name":  {Type: "string"},
-			"age":   {Type: "integer"},
-			"email": {Type: "string"},
+	tools := []ToolDefinition{
+		{
+			Function: models.FunctionDefinition{
+				Name:        "get_weather",
+				Description: "Get the current weather for a city",
+				Parameters:  []byte(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+			},
+			Type: "function",
+		},
+		{
+			Function: models.FunctionDefinition{
+				Name:        "send_invoice",
+				Description: "Send a customer invoice",
+				Parameters:  []byte(`{"type":"object","properties":{"amount":{"type":"number"}},"required":["amount"]}`),
+			},
+			Type: "function",
+		},
+	}
+	messages := []models.ChatCompletionMessage{
+		{Role: "user", Content: `What's the weather like in "Paris" today?`},
+	}
+
+	calls, err := g.GenerateToolCalls(context.Background(), tools, StrategyContextual, messages)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.Equal(t, "get_weather", calls[0].Function.Name)
+	require.Contains(t, calls[0].Function.Arguments, "Paris")
+}
+
+func TestGenerateToolCalls_ContextualNoMatchYieldsNoCalls(t *testing.T) {
+	g := NewToolCallGenerator()
+	tools := []ToolDefinition{
+		{Function: models.FunctionDefinition{Name: "get_weather", Description: "Get the current weather"}, Type: "function"},
+	}
+	messages := []models.ChatCompletionMessage{{Role: "user", Content: "Tell me a joke"}}
+
+	calls, err := g.GenerateToolCalls(context.Background(), tools, StrategyContextual, messages)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+}
+
+func TestGenerateToolCalls_ContextualExtractsNumberAndDateEntities(t *testing.T) {
+	g := NewToolCallGeneratorWithContextualConfig(0, 1)
+	tools := []ToolDefinition{
+		{
+			Function: models.FunctionDefinition{
+				Name:        "schedule_meeting",
+				Description: "Schedule a meeting",
+				Parameters: []byte(`{"type":"object","properties":{
+					"attendees":{"type":"integer"},
+					"starts_at":{"type":"string","format":"date-time"}
+				},"required":["attendees","starts_at"]}`),
+			},
+			Type: "function",
 		},
-		Required: []string{"name", "email"},
 	}
+	messages := []models.ChatCompletionMessage{
+		{Role: "user", Content: "Schedule a meeting for 5 people starting 2024-05-01T10:00:00Z"},
+	}
+
+	calls, err := g.GenerateToolCalls(context.Background(), tools, StrategyContextual, messages)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.Contains(t, calls[0].Function.Arguments, "5")
+	require.Contains(t, calls[0].Function.Arguments, "2024-05-01T10:00:00Z")
+}
+
+func TestGenerateStructuredOutput(t *testing.T) {
+	g := NewToolCallGenerator()
+	raw := []byte(`{
+		"type": "object",
+		"properties": {
+			"name":  {"type": "string"},
+			"age":   {"type": "integer"},
+			"email": {"type": "string"}
+		},
+		"required": ["name", "email"]
+	}`)
 
-	out, err := g.GenerateStructuredOutput(schema)
+	out, refusal, err := g.GenerateStructuredOutput(raw)
 	require.NoError(t, err)
+	require.Empty(t, refusal)
 	require.Contains(t, out, "name")
 	require.Contains(t, out, "email")
 }
+
+func TestGenerateStructuredOutput_ResolvesRefs(t *testing.T) {
+	g := NewToolCallGenerator()
+	raw := []byte(`{
+		"type": "object",
+		"$defs": {
+			"Address": {
+				"type": "object",
+				"properties": {"city": {"type": "string"}}
+			}
+		},
+		"properties": {
+			"home": {"$ref": "#/$defs/Address"}
+		},
+		"required": ["home"]
+	}`)
+
+	out, refusal, err := g.GenerateStructuredOutput(raw)
+	require.NoError(t, err)
+	require.Empty(t, refusal)
+	require.Contains(t, out, "city")
+}
+
+func TestGenerateStructuredOutput_RefusesUnresolvableRef(t *testing.T) {
+	g := NewToolCallGenerator()
+	raw := []byte(`{
+		"type": "object",
+		"properties": {
+			"home": {"$ref": "#/$defs/Missing"}
+		},
+		"required": ["home"]
+	}`)
+
+	out, refusal, err := g.GenerateStructuredOutput(raw)
+	require.NoError(t, err)
+	require.Empty(t, out)
+	require.NotEmpty(t, refusal)
+}
+
+func TestResolveResponseFormat_JSONSchema(t *testing.T) {
+	g := NewToolCallGenerator()
+	responseFormat := map[string]interface{}{
+		"type": "json_schema",
+		"json_schema": map[string]interface{}{
+			"name": "person",
+			"schema": map[string]interface{}{
+				"type":       "object",
+				"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+				"required":   []interface{}{"name"},
+			},
+		},
+	}
+
+	content, refusal := g.ResolveResponseFormat(responseFormat)
+	require.Empty(t, refusal)
+	require.Contains(t, content, "name")
+}
+
+func TestResolveResponseFormat_JSONObject(t *testing.T) {
+	g := NewToolCallGenerator()
+	content, refusal := g.ResolveResponseFormat(map[string]interface{}{"type": "json_object"})
+	require.Empty(t, refusal)
+	require.Equal(t, `{"result":"ok"}`, content)
+}
+
+func TestResolveResponseFormat_UnrecognizedShapeFallsThrough(t *testing.T) {
+	g := NewToolCallGenerator()
+	content, refusal := g.ResolveResponseFormat("not a response_format object")
+	require.Empty(t, content)
+	require.Empty(t, refusal)
+}
+
+func TestGenerateToolCallsForChoice_NamedFunction(t *testing.T) {
+	g := NewToolCallGenerator()
+	tools := []ToolDefinition{
+		{Function: models.FunctionDefinition{Name: "do_a"}, Type: "function"},
+		{Function: models.FunctionDefinition{Name: "do_b"}, Type: "function"},
+	}
+
+	toolChoice := map[string]interface{}{
+		"type":     "function",
+		"function": map[string]interface{}{"name": "do_b"},
+	}
+
+	calls, err := g.GenerateToolCallsForChoice(context.Background(), tools, toolChoice, StrategyRandom, false, nil)
+	require.NoError(t, err)
+	require.Len(t, calls, 1)
+	require.Equal(t, "do_b", calls[0].Function.Name)
+}
+
+func TestGenerateToolCallsForChoice_None(t *testing.T) {
+	g := NewToolCallGenerator()
+	tools := []ToolDefinition{{Function: models.FunctionDefinition{Name: "do_a"}, Type: "function"}}
+
+	calls, err := g.GenerateToolCallsForChoice(context.Background(), tools, "none", StrategyRandom, false, nil)
+	require.NoError(t, err)
+	require.Empty(t, calls)
+}
@@ -0,0 +1,88 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// ToolExecutor runs a tool call locally and produces the `role: "tool"`
+// message a real agent loop would feed back to the model as that call's
+// result.
+type ToolExecutor interface {
+	Execute(ctx context.Context, call models.ChatCompletionMessageToolCall) (models.ChatCompletionMessage, error)
+}
+
+// ToolExecutorFunc adapts a plain function (decode arguments, return result
+// text or an error) to a ToolExecutor, analogous to http.HandlerFunc.
+type ToolExecutorFunc func(ctx context.Context, args json.RawMessage) (string, error)
+
+// Execute implements ToolExecutor.
+func (f ToolExecutorFunc) Execute(ctx context.Context, call models.ChatCompletionMessageToolCall) (models.ChatCompletionMessage, error) {
+	result, err := f(ctx, json.RawMessage(call.Function.Arguments))
+	if err != nil {
+		return models.ChatCompletionMessage{
+			Role:       "tool",
+			Content:    fmt.Sprintf("error: %v", err),
+			ToolCallID: call.ID,
+		}, err
+	}
+	return models.ChatCompletionMessage{
+		Role:       "tool",
+		Content:    result,
+		ToolCallID: call.ID,
+	}, nil
+}
+
+type toolboxEntry struct {
+	definition ToolDefinition
+	executor   ToolExecutor
+}
+
+// Toolbox is a registry of tools the simulator can actually execute, keyed
+// by function name. A request's `tools` may list more functions than the
+// toolbox knows about; callers should fall back to fabricated arguments
+// for anything Lookup doesn't find.
+type Toolbox struct {
+	mu      sync.RWMutex
+	entries map[string]toolboxEntry
+}
+
+// NewToolbox returns a Toolbox pre-registered with the simulator's built-in
+// tools (dir_tree, read_file, http_get, now, calculator).
+func NewToolbox() *Toolbox {
+	tb := &Toolbox{entries: make(map[string]toolboxEntry)}
+	tb.registerBuiltins()
+	return tb
+}
+
+// Register adds or replaces a tool in the registry.
+func (tb *Toolbox) Register(def ToolDefinition, executor ToolExecutor) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+	tb.entries[def.Function.Name] = toolboxEntry{definition: def, executor: executor}
+}
+
+// Lookup returns the executor registered for name, if any.
+func (tb *Toolbox) Lookup(name string) (ToolExecutor, bool) {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	e, ok := tb.entries[name]
+	return e.executor, ok
+}
+
+// Definitions returns the ToolDefinition for every registered tool, for
+// callers that want to advertise the toolbox's capabilities alongside a
+// request's own `tools`.
+func (tb *Toolbox) Definitions() []ToolDefinition {
+	tb.mu.RLock()
+	defer tb.mu.RUnlock()
+	defs := make([]ToolDefinition, 0, len(tb.entries))
+	for _, e := range tb.entries {
+		defs = append(defs, e.definition)
+	}
+	return defs
+}
@@ -0,0 +1,310 @@
+package generator
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// registerBuiltins wires the simulator's handful of safe, real-Go-backed
+// tools into the toolbox, so agent frameworks can exercise actual tool
+// execution (not just fabricated arguments) without a real model.
+func (tb *Toolbox) registerBuiltins() {
+	tb.Register(dirTreeTool())
+	tb.Register(readFileTool())
+	tb.Register(httpGetTool())
+	tb.Register(nowTool())
+	tb.Register(calculatorTool())
+}
+
+func dirTreeTool() (ToolDefinition, ToolExecutor) {
+	def := ToolDefinition{
+		Type: "function",
+		Function: models.FunctionDefinition{
+			Name:        "dir_tree",
+			Description: "List files and directories under path, up to depth levels deep.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"path":  {"type": "string", "description": "Directory to list."},
+					"depth": {"type": "integer", "description": "Maximum depth to recurse (default 2)."}
+				},
+				"required": ["path"]
+			}`),
+		},
+	}
+
+	exec := ToolExecutorFunc(func(ctx context.Context, raw json.RawMessage) (string, error) {
+		var args struct {
+			Path  string `json:"path"`
+			Depth int    `json:"depth"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if args.Depth <= 0 {
+			args.Depth = 2
+		}
+		var sb strings.Builder
+		if err := writeDirTree(&sb, args.Path, "", args.Depth); err != nil {
+			return "", err
+		}
+		return sb.String(), nil
+	})
+
+	return def, exec
+}
+
+func writeDirTree(sb *strings.Builder, path, prefix string, depth int) error {
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	for _, e := range entries {
+		sb.WriteString(prefix + e.Name())
+		if e.IsDir() {
+			sb.WriteString("/")
+		}
+		sb.WriteString("\n")
+		if e.IsDir() && depth > 0 {
+			_ = writeDirTree(sb, filepath.Join(path, e.Name()), prefix+"  ", depth-1)
+		}
+	}
+	return nil
+}
+
+func readFileTool() (ToolDefinition, ToolExecutor) {
+	def := ToolDefinition{
+		Type: "function",
+		Function: models.FunctionDefinition{
+			Name:        "read_file",
+			Description: "Read a file's contents, truncated to max_bytes.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"path":      {"type": "string", "description": "File to read."},
+					"max_bytes": {"type": "integer", "description": "Maximum bytes to return (default 4096)."}
+				},
+				"required": ["path"]
+			}`),
+		},
+	}
+
+	exec := ToolExecutorFunc(func(ctx context.Context, raw json.RawMessage) (string, error) {
+		var args struct {
+			Path     string `json:"path"`
+			MaxBytes int    `json:"max_bytes"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		if args.MaxBytes <= 0 {
+			args.MaxBytes = 4096
+		}
+
+		f, err := os.Open(args.Path)
+		if err != nil {
+			return "", err
+		}
+		defer f.Close()
+
+		buf := make([]byte, args.MaxBytes)
+		n, err := io.ReadFull(f, buf)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return "", err
+		}
+		return string(buf[:n]), nil
+	})
+
+	return def, exec
+}
+
+// httpGetAllowedHosts bounds http_get to an explicit set of hosts so a
+// simulated tool call can't be used to reach arbitrary internal or
+// external services.
+var httpGetAllowedHosts = map[string]bool{
+	"example.com":    true,
+	"httpbin.org":    true,
+	"api.github.com": true,
+}
+
+func httpGetTool() (ToolDefinition, ToolExecutor) {
+	def := ToolDefinition{
+		Type: "function",
+		Function: models.FunctionDefinition{
+			Name:        "http_get",
+			Description: "Fetch a URL over HTTP GET. Restricted to an allow-list of hosts.",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"url": {"type": "string", "description": "URL to fetch."}
+				},
+				"required": ["url"]
+			}`),
+		},
+	}
+
+	exec := ToolExecutorFunc(func(ctx context.Context, raw json.RawMessage) (string, error) {
+		var args struct {
+			URL string `json:"url"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+
+		u, err := url.Parse(args.URL)
+		if err != nil {
+			return "", fmt.Errorf("invalid url: %w", err)
+		}
+		if !httpGetAllowedHosts[u.Hostname()] {
+			return "", fmt.Errorf("host %q is not in the http_get allow-list", u.Hostname())
+		}
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, args.URL, nil)
+		if err != nil {
+			return "", err
+		}
+		client := &http.Client{Timeout: 10 * time.Second}
+		resp, err := client.Do(req)
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+
+		body, err := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+		if err != nil {
+			return "", err
+		}
+		return string(body), nil
+	})
+
+	return def, exec
+}
+
+func nowTool() (ToolDefinition, ToolExecutor) {
+	def := ToolDefinition{
+		Type: "function",
+		Function: models.FunctionDefinition{
+			Name:        "now",
+			Description: "Return the current UTC time in RFC3339 format.",
+			Parameters:  json.RawMessage(`{"type": "object", "properties": {}}`),
+		},
+	}
+
+	exec := ToolExecutorFunc(func(ctx context.Context, raw json.RawMessage) (string, error) {
+		return time.Now().UTC().Format(time.RFC3339), nil
+	})
+
+	return def, exec
+}
+
+func calculatorTool() (ToolDefinition, ToolExecutor) {
+	def := ToolDefinition{
+		Type: "function",
+		Function: models.FunctionDefinition{
+			Name:        "calculator",
+			Description: "Evaluate a basic arithmetic expression (+, -, *, /, parens).",
+			Parameters: json.RawMessage(`{
+				"type": "object",
+				"properties": {
+					"expr": {"type": "string", "description": "Arithmetic expression, e.g. \"(2 + 3) * 4\"."}
+				},
+				"required": ["expr"]
+			}`),
+		},
+	}
+
+	exec := ToolExecutorFunc(func(ctx context.Context, raw json.RawMessage) (string, error) {
+		var args struct {
+			Expr string `json:"expr"`
+		}
+		if err := json.Unmarshal(raw, &args); err != nil {
+			return "", fmt.Errorf("invalid arguments: %w", err)
+		}
+		result, err := evalArithmetic(args.Expr)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatFloat(result, 'g', -1, 64), nil
+	})
+
+	return def, exec
+}
+
+// evalArithmetic evaluates a basic arithmetic expression by parsing it as a
+// Go expression and walking the resulting AST. This reuses the stdlib
+// parser instead of hand-rolling a tokenizer for +, -, *, /, and parens.
+func evalArithmetic(expr string) (float64, error) {
+	node, err := parser.ParseExpr(expr)
+	if err != nil {
+		return 0, fmt.Errorf("invalid expression: %w", err)
+	}
+	return evalArithmeticNode(node)
+}
+
+func evalArithmeticNode(node ast.Expr) (float64, error) {
+	switch n := node.(type) {
+	case *ast.BasicLit:
+		if n.Kind != token.INT && n.Kind != token.FLOAT {
+			return 0, fmt.Errorf("unsupported literal %q", n.Value)
+		}
+		return strconv.ParseFloat(n.Value, 64)
+	case *ast.ParenExpr:
+		return evalArithmeticNode(n.X)
+	case *ast.UnaryExpr:
+		v, err := evalArithmeticNode(n.X)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case token.SUB:
+			return -v, nil
+		case token.ADD:
+			return v, nil
+		default:
+			return 0, fmt.Errorf("unsupported unary operator %s", n.Op)
+		}
+	case *ast.BinaryExpr:
+		x, err := evalArithmeticNode(n.X)
+		if err != nil {
+			return 0, err
+		}
+		y, err := evalArithmeticNode(n.Y)
+		if err != nil {
+			return 0, err
+		}
+		switch n.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			return x / y, nil
+		default:
+			return 0, fmt.Errorf("unsupported operator %s", n.Op)
+		}
+	default:
+		return 0, fmt.Errorf("unsupported expression")
+	}
+}
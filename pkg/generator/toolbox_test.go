@@ -0,0 +1,101 @@
+package generator
+
+import (
+	"context"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func execute(t *testing.T, tb *Toolbox, name, arguments string) models.ChatCompletionMessage {
+	t.Helper()
+	executor, ok := tb.Lookup(name)
+	require.True(t, ok, "expected %q to be registered", name)
+
+	msg, err := executor.Execute(context.Background(), models.ChatCompletionMessageToolCall{
+		ID:   "call_1",
+		Type: "function",
+		Function: models.ChatCompletionMessageToolCallFunction{
+			Name:      name,
+			Arguments: arguments,
+		},
+	})
+	require.NoError(t, err)
+	require.Equal(t, "tool", msg.Role)
+	require.Equal(t, "call_1", msg.ToolCallID)
+	return msg
+}
+
+func TestToolbox_RegistersBuiltins(t *testing.T) {
+	tb := NewToolbox()
+	names := make(map[string]bool)
+	for _, def := range tb.Definitions() {
+		names[def.Function.Name] = true
+	}
+	for _, want := range []string{"dir_tree", "read_file", "http_get", "now", "calculator"} {
+		require.True(t, names[want], "expected builtin %q", want)
+	}
+}
+
+func TestToolbox_DirTreeLists(t *testing.T) {
+	tb := NewToolbox()
+	msg := execute(t, tb, "dir_tree", `{"path": ".", "depth": 1}`)
+	require.NotEmpty(t, msg.Content)
+}
+
+func TestToolbox_ReadFileTruncates(t *testing.T) {
+	tb := NewToolbox()
+	msg := execute(t, tb, "read_file", `{"path": "toolbox.go", "max_bytes": 10}`)
+	require.Len(t, msg.Content, 10)
+}
+
+func TestToolbox_Now(t *testing.T) {
+	tb := NewToolbox()
+	msg := execute(t, tb, "now", `{}`)
+	require.NotEmpty(t, msg.Content)
+}
+
+func TestToolbox_CalculatorEvaluatesExpression(t *testing.T) {
+	tb := NewToolbox()
+	msg := execute(t, tb, "calculator", `{"expr": "(2 + 3) * 4"}`)
+	require.Equal(t, "20", msg.Content)
+}
+
+func TestToolbox_CalculatorRejectsDivisionByZero(t *testing.T) {
+	tb := NewToolbox()
+	executor, ok := tb.Lookup("calculator")
+	require.True(t, ok)
+
+	_, err := executor.Execute(context.Background(), models.ChatCompletionMessageToolCall{
+		ID:   "call_1",
+		Type: "function",
+		Function: models.ChatCompletionMessageToolCallFunction{
+			Name:      "calculator",
+			Arguments: `{"expr": "1 / 0"}`,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestToolbox_HTTPGetRejectsDisallowedHost(t *testing.T) {
+	tb := NewToolbox()
+	executor, ok := tb.Lookup("http_get")
+	require.True(t, ok)
+
+	_, err := executor.Execute(context.Background(), models.ChatCompletionMessageToolCall{
+		ID:   "call_1",
+		Type: "function",
+		Function: models.ChatCompletionMessageToolCallFunction{
+			Name:      "http_get",
+			Arguments: `{"url": "http://169.254.169.254/latest/meta-data"}`,
+		},
+	})
+	require.Error(t, err)
+}
+
+func TestToolbox_LookupUnknown(t *testing.T) {
+	tb := NewToolbox()
+	_, ok := tb.Lookup("no_such_tool")
+	require.False(t, ok)
+}
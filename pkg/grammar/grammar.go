@@ -0,0 +1,711 @@
+// Package grammar compiles a JSON Schema into an internal BNF-style
+// grammar and generates JSON values from it character-by-character: at
+// every step only the runes the current grammar rule permits are
+// considered, so a value is built up the same way llama.cpp's GBNF-driven
+// sampling constrains token choice. This differs from pkg/schema's
+// Sample, which builds a complete Go value (with ordinary, unconstrained
+// string/number generation) and marshals it in one shot; Sample remains
+// the right tool for tool-call arguments, while this package backs
+// response_format structured output, where an engine that streams valid
+// partial JSON as it goes is the point.
+package grammar
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/schema"
+)
+
+// maxRefDepth bounds recursion through $ref chains so a schema that
+// legitimately refers to itself (e.g. a tree node with `children: [Node]`)
+// terminates instead of generating output forever.
+const maxRefDepth = 6
+
+// Grammar is a JSON Schema compiled into production rules ready to
+// generate matching JSON, either all at once (GenerateString) or
+// incrementally (Generate).
+type Grammar struct {
+	root rule
+	defs map[string]rule
+}
+
+// Compile compiles root into a Grammar, resolving `$ref` against root's
+// own `$defs`/`definitions` (the only scope OpenAI-style response_format
+// schemas use). It fails only when a required field - directly, or
+// through allOf - or a named $defs entry references a `$ref` that doesn't
+// resolve; an optional field with such a $ref is simply omitted from the
+// compiled grammar, mirroring pkg/schema.Sample's handling of the same
+// case.
+func Compile(root schema.Schema) (*Grammar, error) {
+	raw := make(map[string]schema.Schema, len(root.Defs)+len(root.Definitions))
+	for k, v := range root.Defs {
+		raw[k] = v
+	}
+	for k, v := range root.Definitions {
+		raw[k] = v
+	}
+
+	rootRule, err := compileRule(root, raw)
+	if err != nil {
+		return nil, err
+	}
+
+	defs := make(map[string]rule, len(raw))
+	for name, sch := range raw {
+		r, err := compileRule(sch, raw)
+		if err != nil {
+			return nil, fmt.Errorf("$defs %q: %w", name, err)
+		}
+		defs[name] = r
+	}
+	return &Grammar{root: rootRule, defs: defs}, nil
+}
+
+// Generate walks the grammar and invokes emit with each fragment of JSON
+// text as it's produced: whole literals for fixed tokens (`{`, `,`,
+// `true`, an enum member, a quoted key), one rune at a time for string
+// content, so a caller streaming the result can forward fragments as
+// content deltas as soon as they're decided rather than waiting for the
+// complete value. Concatenating every emit call's argument, in order,
+// always yields valid JSON once generation completes. seed reproduces the
+// same output for the same grammar and seed, or draws a time-seeded value
+// when seed is 0.
+func (g *Grammar) Generate(seed int64, emit func(string) error) error {
+	gen := &generator{
+		rng:     rand.New(rand.NewSource(seedOrTime(seed))),
+		defs:    g.defs,
+		visited: make(map[string]int),
+		emit:    emit,
+	}
+	return g.root.generate(gen)
+}
+
+// GenerateString runs Generate, accumulating the emitted fragments into a
+// single string - the non-streaming path's entry point.
+func (g *Grammar) GenerateString(seed int64) (string, error) {
+	var sb strings.Builder
+	err := g.Generate(seed, func(s string) error {
+		sb.WriteString(s)
+		return nil
+	})
+	return sb.String(), err
+}
+
+func seedOrTime(seed int64) int64 {
+	if seed == 0 {
+		return time.Now().UnixNano()
+	}
+	return seed
+}
+
+// generator carries the mutable state one Generate call threads through
+// every rule: the RNG driving every choice point, the compiled $defs a
+// refRule resolves against, and the ref recursion guard visited tracks by
+// name -> the depth it was first entered at along the current path.
+type generator struct {
+	rng     *rand.Rand
+	defs    map[string]rule
+	visited map[string]int
+	depth   int
+	emit    func(string) error
+}
+
+func (g *generator) write(s string) error {
+	return g.emit(s)
+}
+
+// writeJSONChar emits r as valid JSON string content, escaping the
+// characters JSON strings can't contain literally.
+func (g *generator) writeJSONChar(r rune) error {
+	switch r {
+	case '"':
+		return g.write(`\"`)
+	case '\\':
+		return g.write(`\\`)
+	case '\n':
+		return g.write(`\n`)
+	case '\t':
+		return g.write(`\t`)
+	case '\r':
+		return g.write(`\r`)
+	default:
+		if r < 0x20 {
+			return g.write(fmt.Sprintf(`\u%04x`, r))
+		}
+		return g.write(string(r))
+	}
+}
+
+// rule is one production in the compiled grammar.
+type rule interface {
+	generate(g *generator) error
+}
+
+// compileRule compiles sch into a rule, resolving $ref/const/enum/allOf/
+// oneOf/anyOf before falling back to a type-driven rule - the same
+// precedence pkg/schema.sampler.sample uses, so a schema compiles to the
+// grammar equivalent of what Sample would have produced.
+func compileRule(sch schema.Schema, defs map[string]schema.Schema) (rule, error) {
+	if sch.Ref != "" {
+		name := schema.RefName(sch.Ref)
+		if _, ok := defs[name]; !ok {
+			return nil, fmt.Errorf("unresolved $ref %q", sch.Ref)
+		}
+		return refRule{name: name}, nil
+	}
+	if sch.Const != nil {
+		return constRule{value: sch.Const}, nil
+	}
+	if len(sch.Enum) > 0 {
+		return enumRule{values: sch.Enum}, nil
+	}
+	if len(sch.AllOf) > 0 {
+		return compileRule(schema.MergeAllOf(sch), defs)
+	}
+	if len(sch.OneOf) > 0 {
+		return compileAlternation(sch.OneOf, defs)
+	}
+	if len(sch.AnyOf) > 0 {
+		return compileAlternation(sch.AnyOf, defs)
+	}
+
+	switch sch.Type {
+	case "object":
+		return compileObject(sch, defs)
+	case "array":
+		return compileArray(sch, defs)
+	case "string":
+		return stringRule{pattern: sch.Pattern, format: sch.Format, minLength: sch.MinLength, maxLength: sch.MaxLength}, nil
+	case "integer":
+		return numberRule{min: sch.Minimum, max: sch.Maximum, integer: true}, nil
+	case "number":
+		return numberRule{min: sch.Minimum, max: sch.Maximum}, nil
+	case "boolean":
+		return boolRule{}, nil
+	default:
+		if len(sch.Properties) > 0 {
+			return compileObject(sch, defs)
+		}
+		return nullRule{}, nil
+	}
+}
+
+// compileAlternation compiles oneOf/anyOf branches, silently dropping any
+// branch whose schema can't be satisfied (an unresolved $ref) rather than
+// failing the whole alternation - a sibling branch remains available, the
+// same spirit as compileObject omitting an unsatisfiable optional property.
+func compileAlternation(branches []schema.Schema, defs map[string]schema.Schema) (rule, error) {
+	rules := make([]rule, 0, len(branches))
+	for _, b := range branches {
+		r, err := compileRule(b, defs)
+		if err != nil {
+			continue
+		}
+		rules = append(rules, r)
+	}
+	if len(rules) == 0 {
+		return nullRule{}, nil
+	}
+	return alternationRule{branches: rules}, nil
+}
+
+// objectProp is one compiled property of an objectRule.
+type objectProp struct {
+	name     string
+	rule     rule
+	required bool
+}
+
+type objectRule struct {
+	props []objectProp
+}
+
+// compileObject compiles sch's properties, propagating an error only for
+// a required property whose schema can't be satisfied; an optional
+// property in the same situation is simply left out of the compiled
+// grammar, mirroring pkg/schema.sampler.sampleObject's per-attempt skip
+// but decided once, at compile time, since $ref resolvability doesn't
+// depend on the random draw.
+func compileObject(sch schema.Schema, defs map[string]schema.Schema) (rule, error) {
+	required := make(map[string]bool, len(sch.Required))
+	for _, name := range sch.Required {
+		required[name] = true
+	}
+
+	props := make([]objectProp, 0, len(sch.Properties))
+	for name, prop := range sch.Properties {
+		r, err := compileRule(prop, defs)
+		if err != nil {
+			if required[name] {
+				return nil, fmt.Errorf("property %q: %w", name, err)
+			}
+			continue
+		}
+		props = append(props, objectProp{name: name, rule: r, required: required[name]})
+	}
+	// Properties iterate off a map; sort for a deterministic key order so
+	// the same schema and seed always produce byte-identical output.
+	sort.Slice(props, func(i, j int) bool { return props[i].name < props[j].name })
+	return objectRule{props: props}, nil
+}
+
+// optionalPropertyInclusionRate is the chance objectRule.generate includes
+// an optional property, matching pkg/schema.sampler.sampleObject's 80%.
+const optionalPropertyInclusionRate = 0.8
+
+func (o objectRule) generate(g *generator) error {
+	if err := g.write("{"); err != nil {
+		return err
+	}
+	written := 0
+	for _, p := range o.props {
+		if !p.required && g.rng.Float64() > optionalPropertyInclusionRate {
+			continue
+		}
+		if written > 0 {
+			if err := g.write(","); err != nil {
+				return err
+			}
+		}
+		key, _ := json.Marshal(p.name)
+		if err := g.write(string(key) + ":"); err != nil {
+			return err
+		}
+		if err := p.rule.generate(g); err != nil {
+			return err
+		}
+		written++
+	}
+	return g.write("}")
+}
+
+type arrayRule struct {
+	items       rule
+	minItems    int
+	maxItems    int
+	uniqueItems bool
+}
+
+const defaultMinItems = 1
+
+func compileArray(sch schema.Schema, defs map[string]schema.Schema) (rule, error) {
+	minItems := defaultMinItems
+	if sch.MinItems != nil {
+		minItems = *sch.MinItems
+	}
+	maxItems := minItems + 2
+	if sch.MaxItems != nil {
+		maxItems = *sch.MaxItems
+	}
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+
+	itemSchema := schema.Schema{Type: "string"}
+	if sch.Items != nil {
+		itemSchema = *sch.Items
+	}
+	itemRule, err := compileRule(itemSchema, defs)
+	if err != nil {
+		return nil, fmt.Errorf("items: %w", err)
+	}
+	return arrayRule{items: itemRule, minItems: minItems, maxItems: maxItems, uniqueItems: sch.UniqueItems}, nil
+}
+
+const maxUniqueAttempts = 50
+
+func (a arrayRule) generate(g *generator) error {
+	if err := g.write("["); err != nil {
+		return err
+	}
+	n := a.minItems
+	if a.maxItems > a.minItems {
+		n = a.minItems + g.rng.Intn(a.maxItems-a.minItems+1)
+	}
+
+	seen := make(map[string]bool, n)
+	written := 0
+	for attempts := 0; written < n && attempts < maxUniqueAttempts; attempts++ {
+		var text string
+		var err error
+		if a.uniqueItems {
+			// uniqueItems needs to compare an item against everything
+			// already accepted, which a forward-only stream can't do once
+			// it's been emitted - render the candidate to a scratch buffer
+			// first and only forward it once it's accepted.
+			text, err = generateToString(a.items, g)
+			if err != nil {
+				return err
+			}
+			if seen[text] {
+				continue
+			}
+			seen[text] = true
+		}
+
+		if written > 0 {
+			if err := g.write(","); err != nil {
+				return err
+			}
+		}
+		if a.uniqueItems {
+			if err := g.write(text); err != nil {
+				return err
+			}
+		} else if err := a.items.generate(g); err != nil {
+			return err
+		}
+		written++
+	}
+	return g.write("]")
+}
+
+// generateToString runs r against a scratch buffer instead of g's real
+// emit, sharing g's rng/defs/visited/depth so the draw it makes is the
+// same one a direct g.emit call would have made.
+func generateToString(r rule, g *generator) (string, error) {
+	var sb strings.Builder
+	sub := &generator{rng: g.rng, defs: g.defs, visited: g.visited, depth: g.depth, emit: func(s string) error {
+		sb.WriteString(s)
+		return nil
+	}}
+	err := r.generate(sub)
+	return sb.String(), err
+}
+
+type stringRule struct {
+	pattern   string
+	format    string
+	minLength *int
+	maxLength *int
+}
+
+func (s stringRule) generate(g *generator) error {
+	if err := g.write(`"`); err != nil {
+		return err
+	}
+	var err error
+	switch {
+	case s.format != "":
+		err = s.generateFormat(g)
+	case s.pattern != "":
+		err = s.generatePattern(g)
+	default:
+		err = s.generatePlain(g)
+	}
+	if err != nil {
+		return err
+	}
+	return g.write(`"`)
+}
+
+// generateFormat renders the handful of `format` keywords OpenAI-style
+// schemas commonly use, then emits the result rune-by-rune through the
+// grammar's JSON-string escaping; an unrecognized format falls back to
+// generatePlain, matching pkg/schema.sampleFormat's fallback.
+func (s stringRule) generateFormat(g *generator) error {
+	var v string
+	switch s.format {
+	case "date-time":
+		v = time.Unix(g.rng.Int63n(2_000_000_000), 0).UTC().Format(time.RFC3339)
+	case "date":
+		v = time.Unix(g.rng.Int63n(2_000_000_000), 0).UTC().Format("2006-01-02")
+	case "email":
+		v = fmt.Sprintf("user%d@example.com", g.rng.Intn(10000))
+	case "uuid":
+		v = randomUUID(g.rng)
+	default:
+		return s.generatePlain(g)
+	}
+	for _, r := range v {
+		if err := g.writeJSONChar(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func randomUUID(rng *rand.Rand) string {
+	b := make([]byte, 16)
+	_, _ = rng.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// generatePattern compiles pattern's regexp AST and walks it, choosing a
+// rune at each step from exactly the set the current AST node permits -
+// the character-by-character, grammar-constrained generation this
+// package exists for. A pattern this walk can't handle (backreferences,
+// lookaround) falls back to generatePlain, matching pkg/schema's
+// sampleRegex fallback.
+func (s stringRule) generatePattern(g *generator) error {
+	re, err := syntax.Parse(s.pattern, syntax.Perl)
+	if err != nil {
+		return s.generatePlain(g)
+	}
+	if !walkRegex(re, g, 0) {
+		return s.generatePlain(g)
+	}
+	return nil
+}
+
+const maxRegexDepth = 20
+
+// walkRegex emits characters satisfying re by walking its parsed AST,
+// returning false (with no guarantee about partial output already
+// written) if it hits a construct it can't drive generation from.
+func walkRegex(re *syntax.Regexp, g *generator, depth int) bool {
+	if depth > maxRegexDepth {
+		return false
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			if g.writeJSONChar(c) != nil {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		pair := g.rng.Intn(len(re.Rune) / 2)
+		lo, hi := re.Rune[pair*2], re.Rune[pair*2+1]
+		if hi < lo {
+			return false
+		}
+		r := lo + rune(g.rng.Intn(int(hi-lo+1)))
+		return g.writeJSONChar(r) == nil
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		return g.writeJSONChar(rune('a'+g.rng.Intn(26))) == nil
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !walkRegex(sub, g, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCapture:
+		return walkRegex(re.Sub[0], g, depth+1)
+	case syntax.OpStar:
+		n := g.rng.Intn(3)
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], g, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpPlus:
+		n := 1 + g.rng.Intn(3)
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], g, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpQuest:
+		if g.rng.Intn(2) == 0 {
+			return true
+		}
+		return walkRegex(re.Sub[0], g, depth+1)
+	case syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		if max < 0 || max > min+5 {
+			max = min + 3
+		}
+		n := min
+		if max > min {
+			n = min + g.rng.Intn(max-min+1)
+		}
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], g, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		if len(re.Sub) == 0 {
+			return false
+		}
+		return walkRegex(re.Sub[g.rng.Intn(len(re.Sub))], g, depth+1)
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpEmptyMatch:
+		return true
+	default:
+		return false
+	}
+}
+
+var placeholderWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+}
+
+// generatePlain fills a string with no format/pattern from placeholder
+// words, honoring minLength/maxLength, the same shape pkg/schema's
+// randomWords produces.
+func (s stringRule) generatePlain(g *generator) error {
+	minLen := 5
+	if s.minLength != nil {
+		minLen = *s.minLength
+	}
+	maxLen := minLen + 10
+	if s.maxLength != nil {
+		maxLen = *s.maxLength
+	}
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+	target := minLen
+	if maxLen > minLen {
+		target = minLen + g.rng.Intn(maxLen-minLen+1)
+	}
+
+	written := 0
+	for written < target {
+		if written > 0 {
+			if err := g.writeJSONChar(' '); err != nil {
+				return err
+			}
+			written++
+		}
+		word := placeholderWords[g.rng.Intn(len(placeholderWords))]
+		for _, r := range word {
+			if written >= target {
+				break
+			}
+			if err := g.writeJSONChar(r); err != nil {
+				return err
+			}
+			written++
+		}
+	}
+	return nil
+}
+
+type numberRule struct {
+	min     *float64
+	max     *float64
+	integer bool
+}
+
+func (n numberRule) generate(g *generator) error {
+	min := 0.0
+	if n.min != nil {
+		min = *n.min
+	}
+	max := min + 100
+	if n.max != nil {
+		max = *n.max
+	}
+	if max < min {
+		max = min
+	}
+	v := min + g.rng.Float64()*(max-min)
+
+	var text string
+	if n.integer {
+		text = strconv.FormatInt(int64(v), 10)
+	} else {
+		text = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	for _, r := range text {
+		if err := g.write(string(r)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type boolRule struct{}
+
+func (boolRule) generate(g *generator) error {
+	if g.rng.Intn(2) == 0 {
+		return g.write("false")
+	}
+	return g.write("true")
+}
+
+type nullRule struct{}
+
+func (nullRule) generate(g *generator) error {
+	return g.write("null")
+}
+
+type constRule struct {
+	value interface{}
+}
+
+func (c constRule) generate(g *generator) error {
+	b, err := json.Marshal(c.value)
+	if err != nil {
+		return g.write("null")
+	}
+	return g.write(string(b))
+}
+
+type enumRule struct {
+	values []interface{}
+}
+
+func (e enumRule) generate(g *generator) error {
+	v := e.values[g.rng.Intn(len(e.values))]
+	b, err := json.Marshal(v)
+	if err != nil {
+		return g.write("null")
+	}
+	return g.write(string(b))
+}
+
+type alternationRule struct {
+	branches []rule
+}
+
+func (a alternationRule) generate(g *generator) error {
+	return a.branches[g.rng.Intn(len(a.branches))].generate(g)
+}
+
+type refRule struct {
+	name string
+}
+
+// generate resolves the ref against g.defs, guarding against runaway
+// recursion the same way pkg/schema.sampler does: a ref seen again at a
+// depth at or beyond where it was first entered along this path - or
+// recursion past maxRefDepth - renders as `null` instead of recursing
+// forever, and a $ref compileRule couldn't resolve at all (compile
+// already treats this as a hard error for required fields, so this only
+// triggers for values computed on the error-tolerant optional/branch
+// paths that still end up holding a dangling name) does the same.
+func (r refRule) generate(g *generator) error {
+	target, ok := g.defs[r.name]
+	if !ok {
+		return g.write("null")
+	}
+	if seenAt, seen := g.visited[r.name]; seen && seenAt <= g.depth {
+		return g.write("null")
+	}
+	if g.depth > maxRefDepth {
+		return g.write("null")
+	}
+
+	prev, had := g.visited[r.name]
+	g.visited[r.name] = g.depth
+	g.depth++
+	err := target.generate(g)
+	g.depth--
+	if had {
+		g.visited[r.name] = prev
+	} else {
+		delete(g.visited, r.name)
+	}
+	return err
+}
@@ -0,0 +1,183 @@
+package grammar
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateString_ObjectWithRequiredAndOptional(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"name": {Type: "string"},
+			"age":  {Type: "integer"},
+		},
+		Required: []string{"name"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	out, err := gram.GenerateString(1)
+	require.NoError(t, err)
+
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	require.Contains(t, parsed, "name")
+}
+
+func TestGenerateString_ResolvesRef(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Defs: map[string]schema.Schema{
+			"Address": {
+				Type:       "object",
+				Properties: map[string]schema.Schema{"city": {Type: "string"}},
+				Required:   []string{"city"},
+			},
+		},
+		Properties: map[string]schema.Schema{
+			"home": {Ref: "#/$defs/Address"},
+		},
+		Required: []string{"home"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	out, err := gram.GenerateString(1)
+	require.NoError(t, err)
+
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	home, ok := parsed["home"].(map[string]interface{})
+	require.True(t, ok)
+	require.Contains(t, home, "city")
+}
+
+func TestCompile_UnresolvedRequiredRefFails(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"home": {Ref: "#/$defs/Missing"},
+		},
+		Required: []string{"home"},
+	}
+
+	_, err := Compile(root)
+	require.Error(t, err)
+}
+
+func TestGenerateString_EnumPicksDeclaredValue(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"status": {Type: "string", Enum: []interface{}{"active", "inactive"}},
+		},
+		Required: []string{"status"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	out, err := gram.GenerateString(1)
+	require.NoError(t, err)
+
+	var parsed map[string]string
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	require.Contains(t, []string{"active", "inactive"}, parsed["status"])
+}
+
+func TestGenerateString_PatternGeneratesMatchingString(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"code": {Type: "string", Pattern: "^[A-Z]{3}-[0-9]{4}$"},
+		},
+		Required: []string{"code"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	out, err := gram.GenerateString(1)
+	require.NoError(t, err)
+
+	var parsed map[string]string
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	require.Regexp(t, "^[A-Z]{3}-[0-9]{4}$", parsed["code"])
+}
+
+func TestGenerateString_ArrayHonorsMinMaxItems(t *testing.T) {
+	two := 2
+	four := 4
+	root := schema.Schema{
+		Type:     "array",
+		Items:    &schema.Schema{Type: "integer"},
+		MinItems: &two,
+		MaxItems: &four,
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	out, err := gram.GenerateString(1)
+	require.NoError(t, err)
+
+	var parsed []int
+	require.NoError(t, json.Unmarshal([]byte(out), &parsed))
+	require.GreaterOrEqual(t, len(parsed), 2)
+	require.LessOrEqual(t, len(parsed), 4)
+}
+
+func TestGenerate_SameSeedIsReproducible(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"id":    {Type: "string", Format: "uuid"},
+			"score": {Type: "number", Minimum: floatPtr(0), Maximum: floatPtr(1)},
+		},
+		Required: []string{"id", "score"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	first, err := gram.GenerateString(42)
+	require.NoError(t, err)
+	second, err := gram.GenerateString(42)
+	require.NoError(t, err)
+	require.Equal(t, first, second)
+}
+
+func TestGenerate_EmitsCharacterByCharacterAndConcatenatesToValidJSON(t *testing.T) {
+	root := schema.Schema{
+		Type: "object",
+		Properties: map[string]schema.Schema{
+			"name": {Type: "string"},
+		},
+		Required: []string{"name"},
+	}
+
+	gram, err := Compile(root)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	fragments := 0
+	err = gram.Generate(1, func(s string) error {
+		fragments++
+		sb.WriteString(s)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Greater(t, fragments, 1, "generation should emit more than one fragment")
+
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(sb.String()), &parsed))
+}
+
+func floatPtr(v float64) *float64 { return &v }
@@ -47,6 +47,7 @@ type ChatCompletionChunkChoiceDelta struct {
 	Role      string                        `json:"role,omitempty"`
 	Content   string                        `json:"content,omitempty"`
 	ToolCalls []ChatCompletionChunkToolCall `json:"tool_calls,omitempty"`
+	Refusal   string                        `json:"refusal,omitempty"`
 }
 
 // ChatCompletionChunkToolCall represents a tool call in a streaming chunk
@@ -69,6 +70,19 @@ type ChatCompletionMessage struct {
 	Content   string                          `json:"content"`
 	ToolCalls []ChatCompletionMessageToolCall `json:"tool_calls,omitempty"`
 	Refusal   string                          `json:"refusal,omitempty"`
+	Audio     *ChatCompletionAudio            `json:"audio,omitempty"`
+	// ToolCallID identifies the tool call this message answers, set on the
+	// `role: "tool"` result message a ToolExecutor produces.
+	ToolCallID string `json:"tool_call_id,omitempty"`
+}
+
+// ChatCompletionAudio mirrors OpenAI's audio-out shape, returned per choice
+// when the request's `modalities` include `"audio"`.
+type ChatCompletionAudio struct {
+	ID         string `json:"id"`
+	Data       string `json:"data"`
+	Transcript string `json:"transcript"`
+	ExpiresAt  int64  `json:"expires_at"`
 }
 
 // ChatCompletionMessageToolCall represents a tool call made by the model
@@ -134,25 +148,6 @@ type FunctionDefinition struct {
 	Parameters  json.RawMessage `json:"parameters,omitempty"`
 }
 
-// JSONSchema represents a JSON schema
-type JSONSchema struct {
-	Type       string                 `json:"type"`
-	Properties map[string]PropertyDef `json:"properties,omitempty"`
-	Required   []string               `json:"required,omitempty"`
-	Items      *PropertyDef           `json:"items,omitempty"`
-	Enum       []interface{}          `json:"enum,omitempty"`
-}
-
-// PropertyDef represents a property definition in a schema
-type PropertyDef struct {
-	Type        string                 `json:"type"`
-	Description string                 `json:"description,omitempty"`
-	Properties  map[string]PropertyDef `json:"properties,omitempty"`
-	Required    []string               `json:"required,omitempty"`
-	Items       *PropertyDef           `json:"items,omitempty"`
-	Enum        []interface{}          `json:"enum,omitempty"`
-}
-
 // ChatCompletionRequest represents the full request body
 type ChatCompletionRequest struct {
 	Model               string                       `json:"model"`
@@ -175,6 +170,180 @@ type ChatCompletionRequest struct {
 	Modalities          []string                     `json:"modalities,omitempty"`
 	ParallelToolCalls   *bool                        `json:"parallel_tool_calls,omitempty"`
 	ResponseLength      string                       `json:"response_length,omitempty"`
+	Simulator           *SimulatorConfig             `json:"simulator,omitempty"`
+}
+
+// SimulatorConfig lets a caller opt a single chat completion request into
+// the simulator's testing controls, either via this `simulator` request
+// body field or, for the fault-injection fields, the equivalent
+// `X-Simulator-Fault` header (which takes precedence over this field when
+// both are present).
+type SimulatorConfig struct {
+	// Fault-injection fields (see streaming.FaultConfig) reproduce
+	// production failure modes - rate limits, dropped/stalled connections,
+	// malformed chunks, unexpected finish reasons - deterministically.
+	ErrorRate           float64 `json:"error_rate,omitempty"`
+	ErrorCodes          []int   `json:"error_codes,omitempty"`
+	TruncateAfterTokens int     `json:"truncate_after_tokens,omitempty"`
+	MalformedJSONRate   float64 `json:"malformed_json_rate,omitempty"`
+	StallAfterTokens    int     `json:"stall_after_tokens,omitempty"`
+	StallDurationMs     int     `json:"stall_duration_ms,omitempty"`
+	InvalidFinishReason string  `json:"invalid_finish_reason,omitempty"`
+	RateLimitPerMinute  int     `json:"rate_limit_per_minute,omitempty"`
+	RateLimitBurst      int     `json:"rate_limit_burst,omitempty"`
+
+	// ToolStrategy controls whether a completion emits tool_calls when
+	// Tools is non-empty: "always" (the default, matching the simulator's
+	// historical behavior) and "never" are fixed outcomes, "probability"
+	// rolls ToolCallProbability per request so a harness can exercise a
+	// model that sometimes answers in prose instead of calling a tool.
+	ToolStrategy        string  `json:"tool_strategy,omitempty"`
+	ToolCallProbability float64 `json:"tool_call_probability,omitempty"`
+}
+
+// CompletionRequest represents a request to the legacy `/v1/completions`
+// endpoint. Unlike chat completions, the payload is prompt-based rather
+// than message-based, but shares the same streaming/usage conventions.
+type CompletionRequest struct {
+	Model         string         `json:"model"`
+	Prompt        interface{}    `json:"prompt"`
+	Suffix        string         `json:"suffix,omitempty"`
+	MaxTokens     *int64         `json:"max_tokens,omitempty"`
+	Temperature   *float64       `json:"temperature,omitempty"`
+	TopP          *float64       `json:"top_p,omitempty"`
+	N             *int64         `json:"n,omitempty"`
+	Stream        bool           `json:"stream,omitempty"`
+	StreamOptions *StreamOptions `json:"stream_options,omitempty"`
+	Logprobs      *int64         `json:"logprobs,omitempty"`
+	Echo          bool           `json:"echo,omitempty"`
+	Stop          interface{}    `json:"stop,omitempty"`
+	Seed          *int64         `json:"seed,omitempty"`
+	BestOf        *int64         `json:"best_of,omitempty"`
+}
+
+// CompletionResponse represents the response from `/v1/completions` (the
+// legacy `text_completion` object).
+type CompletionResponse struct {
+	ID      string             `json:"id"`
+	Object  string             `json:"object"`
+	Created int64              `json:"created"`
+	Model   string             `json:"model"`
+	Choices []CompletionChoice `json:"choices"`
+	Usage   CompletionUsage    `json:"usage"`
+}
+
+// CompletionChoice represents a single choice of a text completion.
+type CompletionChoice struct {
+	Text         string              `json:"text"`
+	Index        int64               `json:"index"`
+	FinishReason string              `json:"finish_reason"`
+	Logprobs     *CompletionLogprobs `json:"logprobs,omitempty"`
+}
+
+// CompletionLogprobs mirrors the legacy `/v1/completions` logprobs block:
+// parallel arrays keyed by token position, predating the per-token objects
+// the chat completions API uses (see ChatCompletionChoiceLogprobs).
+type CompletionLogprobs struct {
+	Tokens        []string             `json:"tokens"`
+	TokenLogprobs []float64            `json:"token_logprobs"`
+	TopLogprobs   []map[string]float64 `json:"top_logprobs"`
+	TextOffset    []int64              `json:"text_offset"`
+}
+
+// CompletionChunk represents a chunk of a streamed `text_completion.chunk`
+// response, mirroring the shape OpenAI used before chat completions.
+type CompletionChunk struct {
+	ID      string                  `json:"id"`
+	Object  string                  `json:"object"`
+	Created int64                   `json:"created"`
+	Model   string                  `json:"model"`
+	Choices []CompletionChunkChoice `json:"choices"`
+	Usage   *CompletionUsage        `json:"usage,omitempty"`
+}
+
+// CompletionChunkChoice represents one choice's delta within a streamed
+// text completion chunk.
+type CompletionChunkChoice struct {
+	Text         string  `json:"text"`
+	Index        int64   `json:"index"`
+	FinishReason *string `json:"finish_reason"`
+}
+
+// EmbeddingRequest represents a request to `/v1/embeddings`. Input is either
+// a single string or an array of strings, matching OpenAI's accepted
+// shapes.
+type EmbeddingRequest struct {
+	Model          string           `json:"model"`
+	Input          interface{}      `json:"input"`
+	EncodingFormat string           `json:"encoding_format,omitempty"`
+	Dimensions     *int             `json:"dimensions,omitempty"`
+	User           string           `json:"user,omitempty"`
+	Simulator      *SimulatorConfig `json:"simulator,omitempty"`
+}
+
+// EmbeddingResponse represents the response from `/v1/embeddings`.
+type EmbeddingResponse struct {
+	Object string         `json:"object"`
+	Data   []Embedding    `json:"data"`
+	Model  string         `json:"model"`
+	Usage  EmbeddingUsage `json:"usage"`
+}
+
+// Embedding is a single input's embedding vector. Embedding holds a
+// []float64 when EncodingFormat is "float" (the default), or a
+// base64-encoded string of little-endian float32s when it is "base64",
+// matching OpenAI's two supported encodings.
+type Embedding struct {
+	Object    string      `json:"object"`
+	Embedding interface{} `json:"embedding"`
+	Index     int64       `json:"index"`
+}
+
+// EmbeddingUsage mirrors `/v1/embeddings`' usage block, which - unlike chat
+// completions - has no completion_tokens since there is no generation step.
+type EmbeddingUsage struct {
+	PromptTokens int64 `json:"prompt_tokens"`
+	TotalTokens  int64 `json:"total_tokens"`
+}
+
+// SpeechRequest represents a request to `/v1/audio/speech`.
+type SpeechRequest struct {
+	Model          string           `json:"model"`
+	Input          string           `json:"input"`
+	Voice          string           `json:"voice,omitempty"`
+	ResponseFormat string           `json:"response_format,omitempty"`
+	Speed          *float64         `json:"speed,omitempty"`
+	Simulator      *SimulatorConfig `json:"simulator,omitempty"`
+}
+
+// ImageGenerationRequest represents a request to `/v1/images/generations`.
+type ImageGenerationRequest struct {
+	Model          string           `json:"model,omitempty"`
+	Prompt         string           `json:"prompt"`
+	N              *int64           `json:"n,omitempty"`
+	Size           string           `json:"size,omitempty"`
+	ResponseFormat string           `json:"response_format,omitempty"`
+	Quality        string           `json:"quality,omitempty"`
+	Style          string           `json:"style,omitempty"`
+	User           string           `json:"user,omitempty"`
+	Simulator      *SimulatorConfig `json:"simulator,omitempty"`
+}
+
+// ImageGenerationResponse represents the response from
+// `/v1/images/generations`.
+type ImageGenerationResponse struct {
+	Created int64            `json:"created"`
+	Data    []GeneratedImage `json:"data"`
+}
+
+// GeneratedImage is a single generated image, returned either as a URL
+// (ResponseFormat "url", the default) pointing back at this simulator's own
+// image-serving handler, or as an inline base64 PNG (ResponseFormat
+// "b64_json").
+type GeneratedImage struct {
+	URL           string `json:"url,omitempty"`
+	B64JSON       string `json:"b64_json,omitempty"`
+	RevisedPrompt string `json:"revised_prompt,omitempty"`
 }
 
 // StreamOptions represents streaming options
@@ -0,0 +1,618 @@
+package router
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// ProviderAdapter translates an OpenAI-shaped chat completion request into a
+// specific upstream's native schema, sends it, and translates the response
+// back into plain assistant text plus usage.
+type ProviderAdapter interface {
+	// Complete asks the upstream for a complete, non-streaming response and
+	// returns the translated assistant text plus usage.
+	Complete(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (string, models.CompletionUsage, error)
+	// CompleteStream asks the upstream for its own streaming response and
+	// translates each incremental piece as it arrives, so a client that
+	// requested stream:true sees the upstream's real delivery timing
+	// instead of a blocked-then-replayed one. The returned channel is
+	// closed after a StreamDelta with Done set (or one with Err set, on
+	// failure) and must always be drained to completion by the caller.
+	CompleteStream(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (<-chan StreamDelta, error)
+}
+
+// StreamDelta is one incremental piece of a CompleteStream response. Content
+// holds text to append for this step. Usage and Done are only meaningful on
+// the final delta: Done is set once the upstream's stream has ended (with
+// Usage populated if the upstream reported one), and Err is set instead if
+// the stream ended abnormally.
+type StreamDelta struct {
+	Content string
+	Usage   models.CompletionUsage
+	Done    bool
+	Err     error
+}
+
+// sendDelta delivers d to deltas, respecting ctx cancellation, and reports
+// whether the caller should keep reading the upstream stream.
+func sendDelta(ctx context.Context, deltas chan<- StreamDelta, d StreamDelta) bool {
+	select {
+	case deltas <- d:
+		return d.Err == nil && !d.Done
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// NewProviderAdapter returns the ProviderAdapter for kind, or an error if
+// kind is not recognized.
+func NewProviderAdapter(kind ProviderKind) (ProviderAdapter, error) {
+	switch kind {
+	case ProviderOpenAI, ProviderPassthrough:
+		return openAIAdapter{}, nil
+	case ProviderAnthropic:
+		return anthropicAdapter{}, nil
+	case ProviderOllama:
+		return ollamaAdapter{}, nil
+	case ProviderGemini:
+		return geminiAdapter{}, nil
+	default:
+		return nil, fmt.Errorf("router: unknown provider kind %q", kind)
+	}
+}
+
+var httpClient = &http.Client{Timeout: 120 * time.Second}
+
+// doJSON POSTs body to url with route's headers/auth applied and decodes
+// the JSON response into out.
+func doJSON(ctx context.Context, route ProviderRoute, url string, body interface{}, out interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("router: encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("router: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// Gemini authenticates via an API-key query parameter instead of a
+	// bearer token; see geminiAdapter.Complete.
+	if route.APIKey != "" && route.Kind != ProviderGemini {
+		req.Header.Set("Authorization", "Bearer "+route.APIKey)
+	}
+	for k, v := range route.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("router: upstream %s unreachable: %w", route.BaseURL, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("router: read upstream response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("router: upstream %s returned %s: %s", route.BaseURL, resp.Status, string(respBody))
+	}
+	if out != nil {
+		if err := json.Unmarshal(respBody, out); err != nil {
+			return fmt.Errorf("router: decode upstream response: %w", err)
+		}
+	}
+	return nil
+}
+
+// doStream POSTs body to url with route's headers/auth applied, like doJSON,
+// but returns the still-open response body for the caller to parse as the
+// upstream's own streaming format instead of decoding a single JSON object.
+// The caller is responsible for closing the returned body.
+func doStream(ctx context.Context, route ProviderRoute, url string, body interface{}) (io.ReadCloser, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("router: encode request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("router: build request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "text/event-stream")
+	if route.APIKey != "" && route.Kind != ProviderGemini {
+		req.Header.Set("Authorization", "Bearer "+route.APIKey)
+	}
+	for k, v := range route.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("router: upstream %s unreachable: %w", route.BaseURL, err)
+	}
+	if resp.StatusCode >= 300 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("router: upstream %s returned %s: %s", route.BaseURL, resp.Status, string(respBody))
+	}
+	return resp.Body, nil
+}
+
+// sseEvent is one parsed Server-Sent Event ("event: ...\ndata: ...\n\n")
+// from an upstream stream. event is empty for upstreams (OpenAI, Gemini)
+// that only ever send "data:" lines.
+type sseEvent struct {
+	event string
+	data  string
+}
+
+// scanSSE reads body as a stream of Server-Sent Events, calling fn for each
+// complete event until body is exhausted, fn returns false, or a read error
+// occurs.
+func scanSSE(body io.Reader, fn func(sseEvent) bool) error {
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var ev sseEvent
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if ev.data != "" {
+				if !fn(ev) {
+					return nil
+				}
+			}
+			ev = sseEvent{}
+		case strings.HasPrefix(line, "event:"):
+			ev.event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if ev.data != "" {
+				ev.data += "\n"
+			}
+			ev.data += strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+	return scanner.Err()
+}
+
+// modelFor returns route.ModelOverride when set, otherwise the model the
+// client requested.
+func modelFor(route ProviderRoute, requested string) string {
+	if route.ModelOverride != "" {
+		return route.ModelOverride
+	}
+	return requested
+}
+
+// openAIAdapter forwards a request using the OpenAI chat completions schema
+// verbatim; also used for ProviderPassthrough since the simulator's own
+// request/response shape already is that schema.
+type openAIAdapter struct{}
+
+func (openAIAdapter) Complete(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (string, models.CompletionUsage, error) {
+	reqBody := *in
+	reqBody.Model = modelFor(route, in.Model)
+	reqBody.Stream = false
+
+	var out models.ChatCompletion
+	if err := doJSON(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/v1/chat/completions", reqBody, &out); err != nil {
+		return "", models.CompletionUsage{}, err
+	}
+	if len(out.Choices) == 0 {
+		return "", out.Usage, nil
+	}
+	return out.Choices[0].Message.Content, out.Usage, nil
+}
+
+// CompleteStream asks the upstream for its own chat.completion.chunk SSE
+// stream (the same format the simulator itself emits) and relays each
+// chunk's delta as it arrives.
+func (openAIAdapter) CompleteStream(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (<-chan StreamDelta, error) {
+	reqBody := *in
+	reqBody.Model = modelFor(route, in.Model)
+	reqBody.Stream = true
+
+	body, err := doStream(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/v1/chat/completions", reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan StreamDelta)
+	go func() {
+		defer close(deltas)
+		defer body.Close()
+
+		var usage models.CompletionUsage
+		failed := false
+		err := scanSSE(body, func(ev sseEvent) bool {
+			if ev.data == "[DONE]" {
+				return false
+			}
+			var chunk models.ChatCompletionChunk
+			if err := json.Unmarshal([]byte(ev.data), &chunk); err != nil {
+				failed = true
+				return sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: decode upstream chunk: %w", err)})
+			}
+			if chunk.Usage != nil {
+				usage = *chunk.Usage
+			}
+			if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+				return true
+			}
+			return sendDelta(ctx, deltas, StreamDelta{Content: chunk.Choices[0].Delta.Content})
+		})
+		if failed {
+			return
+		}
+		if err != nil {
+			sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: read upstream stream: %w", err)})
+			return
+		}
+		sendDelta(ctx, deltas, StreamDelta{Usage: usage, Done: true})
+	}()
+	return deltas, nil
+}
+
+// anthropicReqMessage/anthropicRequest/anthropicResponse mirror the subset
+// of Anthropic's Messages API this adapter translates.
+type anthropicReqMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type anthropicRequest struct {
+	Model     string                `json:"model"`
+	Messages  []anthropicReqMessage `json:"messages"`
+	System    string                `json:"system,omitempty"`
+	MaxTokens int64                 `json:"max_tokens"`
+	Stream    bool                  `json:"stream,omitempty"`
+}
+
+// anthropicStreamEvent mirrors the subset of Anthropic's Messages API SSE
+// events (message_start, content_block_delta, message_delta, message_stop)
+// needed to relay incremental text and the final token usage.
+type anthropicStreamEvent struct {
+	Type    string `json:"type"`
+	Message struct {
+		Usage struct {
+			InputTokens int64 `json:"input_tokens"`
+		} `json:"usage"`
+	} `json:"message"`
+	Delta struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"delta"`
+	Usage struct {
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicResponse struct {
+	Content []struct {
+		Type string `json:"type"`
+		Text string `json:"text"`
+	} `json:"content"`
+	Usage struct {
+		InputTokens  int64 `json:"input_tokens"`
+		OutputTokens int64 `json:"output_tokens"`
+	} `json:"usage"`
+}
+
+type anthropicAdapter struct{}
+
+func (anthropicAdapter) Complete(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (string, models.CompletionUsage, error) {
+	req := anthropicRequest{Model: modelFor(route, in.Model), MaxTokens: 1024}
+	for _, m := range in.Messages {
+		if m.Role == "system" {
+			req.System = m.Content
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicReqMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var out anthropicResponse
+	if err := doJSON(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/v1/messages", req, &out); err != nil {
+		return "", models.CompletionUsage{}, err
+	}
+
+	var text strings.Builder
+	for _, block := range out.Content {
+		if block.Type == "text" {
+			text.WriteString(block.Text)
+		}
+	}
+	usage := models.CompletionUsage{
+		PromptTokens:     out.Usage.InputTokens,
+		CompletionTokens: out.Usage.OutputTokens,
+		TotalTokens:      out.Usage.InputTokens + out.Usage.OutputTokens,
+	}
+	return text.String(), usage, nil
+}
+
+// CompleteStream asks the upstream for Anthropic's own Messages API SSE
+// stream and relays each content_block_delta's text as it arrives.
+func (anthropicAdapter) CompleteStream(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (<-chan StreamDelta, error) {
+	req := anthropicRequest{Model: modelFor(route, in.Model), MaxTokens: 1024, Stream: true}
+	for _, m := range in.Messages {
+		if m.Role == "system" {
+			req.System = m.Content
+			continue
+		}
+		req.Messages = append(req.Messages, anthropicReqMessage{Role: m.Role, Content: m.Content})
+	}
+
+	body, err := doStream(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/v1/messages", req)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan StreamDelta)
+	go func() {
+		defer close(deltas)
+		defer body.Close()
+
+		var usage models.CompletionUsage
+		failed := false
+		err := scanSSE(body, func(ev sseEvent) bool {
+			var event anthropicStreamEvent
+			if err := json.Unmarshal([]byte(ev.data), &event); err != nil {
+				failed = true
+				return sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: decode upstream event: %w", err)})
+			}
+			switch event.Type {
+			case "message_start":
+				usage.PromptTokens = event.Message.Usage.InputTokens
+				return true
+			case "content_block_delta":
+				if event.Delta.Type != "text_delta" || event.Delta.Text == "" {
+					return true
+				}
+				return sendDelta(ctx, deltas, StreamDelta{Content: event.Delta.Text})
+			case "message_delta":
+				usage.CompletionTokens = event.Usage.OutputTokens
+				return true
+			case "message_stop":
+				return false
+			default:
+				return true
+			}
+		})
+		if failed {
+			return
+		}
+		if err != nil {
+			sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: read upstream stream: %w", err)})
+			return
+		}
+		usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+		sendDelta(ctx, deltas, StreamDelta{Usage: usage, Done: true})
+	}()
+	return deltas, nil
+}
+
+// ollamaMessage/ollamaRequest/ollamaResponse mirror Ollama's `/api/chat`
+// schema.
+type ollamaMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ollamaRequest struct {
+	Model    string          `json:"model"`
+	Messages []ollamaMessage `json:"messages"`
+	Stream   bool            `json:"stream"`
+}
+
+type ollamaResponse struct {
+	Message         ollamaMessage `json:"message"`
+	Done            bool          `json:"done"`
+	PromptEvalCount int64         `json:"prompt_eval_count"`
+	EvalCount       int64         `json:"eval_count"`
+}
+
+type ollamaAdapter struct{}
+
+func (ollamaAdapter) Complete(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (string, models.CompletionUsage, error) {
+	req := ollamaRequest{Model: modelFor(route, in.Model), Stream: false}
+	for _, m := range in.Messages {
+		req.Messages = append(req.Messages, ollamaMessage{Role: m.Role, Content: m.Content})
+	}
+
+	var out ollamaResponse
+	if err := doJSON(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/api/chat", req, &out); err != nil {
+		return "", models.CompletionUsage{}, err
+	}
+
+	usage := models.CompletionUsage{
+		PromptTokens:     out.PromptEvalCount,
+		CompletionTokens: out.EvalCount,
+		TotalTokens:      out.PromptEvalCount + out.EvalCount,
+	}
+	return out.Message.Content, usage, nil
+}
+
+// CompleteStream asks the upstream for Ollama's own `/api/chat` stream,
+// which is newline-delimited JSON objects (not SSE) with each object's
+// message.content holding that step's incremental text.
+func (ollamaAdapter) CompleteStream(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (<-chan StreamDelta, error) {
+	req := ollamaRequest{Model: modelFor(route, in.Model), Stream: true}
+	for _, m := range in.Messages {
+		req.Messages = append(req.Messages, ollamaMessage{Role: m.Role, Content: m.Content})
+	}
+
+	body, err := doStream(ctx, route, strings.TrimSuffix(route.BaseURL, "/")+"/api/chat", req)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan StreamDelta)
+	go func() {
+		defer close(deltas)
+		defer body.Close()
+
+		scanner := bufio.NewScanner(body)
+		scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			line := strings.TrimSpace(scanner.Text())
+			if line == "" {
+				continue
+			}
+			var chunk ollamaResponse
+			if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+				sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: decode upstream chunk: %w", err)})
+				return
+			}
+			if chunk.Done {
+				usage := models.CompletionUsage{
+					PromptTokens:     chunk.PromptEvalCount,
+					CompletionTokens: chunk.EvalCount,
+					TotalTokens:      chunk.PromptEvalCount + chunk.EvalCount,
+				}
+				sendDelta(ctx, deltas, StreamDelta{Usage: usage, Done: true})
+				return
+			}
+			if chunk.Message.Content == "" {
+				continue
+			}
+			if !sendDelta(ctx, deltas, StreamDelta{Content: chunk.Message.Content}) {
+				return
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: read upstream stream: %w", err)})
+		}
+	}()
+	return deltas, nil
+}
+
+// geminiPart/geminiContent/geminiRequest/geminiResponse mirror Google's
+// `generateContent` schema.
+type geminiPart struct {
+	Text string `json:"text"`
+}
+
+type geminiContent struct {
+	Role  string       `json:"role,omitempty"`
+	Parts []geminiPart `json:"parts"`
+}
+
+type geminiRequest struct {
+	Contents []geminiContent `json:"contents"`
+}
+
+type geminiResponse struct {
+	Candidates []struct {
+		Content geminiContent `json:"content"`
+	} `json:"candidates"`
+	UsageMetadata struct {
+		PromptTokenCount     int64 `json:"promptTokenCount"`
+		CandidatesTokenCount int64 `json:"candidatesTokenCount"`
+	} `json:"usageMetadata"`
+}
+
+type geminiAdapter struct{}
+
+func (geminiAdapter) Complete(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (string, models.CompletionUsage, error) {
+	req := geminiRequest{}
+	for _, m := range in.Messages {
+		role := "user"
+		if m.Role == "assistant" {
+			role = "model"
+		}
+		req.Contents = append(req.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+
+	model := modelFor(route, in.Model)
+	url := fmt.Sprintf("%s/v1beta/models/%s:generateContent?key=%s", strings.TrimSuffix(route.BaseURL, "/"), model, route.APIKey)
+
+	var out geminiResponse
+	if err := doJSON(ctx, route, url, req, &out); err != nil {
+		return "", models.CompletionUsage{}, err
+	}
+
+	var text strings.Builder
+	if len(out.Candidates) > 0 {
+		for _, part := range out.Candidates[0].Content.Parts {
+			text.WriteString(part.Text)
+		}
+	}
+	usage := models.CompletionUsage{
+		PromptTokens:     out.UsageMetadata.PromptTokenCount,
+		CompletionTokens: out.UsageMetadata.CandidatesTokenCount,
+		TotalTokens:      out.UsageMetadata.PromptTokenCount + out.UsageMetadata.CandidatesTokenCount,
+	}
+	return text.String(), usage, nil
+}
+
+// CompleteStream asks the upstream for Gemini's `streamGenerateContent` SSE
+// stream (alt=sse) and relays each chunk's text as it arrives.
+func (geminiAdapter) CompleteStream(ctx context.Context, route ProviderRoute, in *models.ChatCompletionRequest) (<-chan StreamDelta, error) {
+	req := geminiRequest{}
+	for _, m := range in.Messages {
+		role := "user"
+		if m.Role == "assistant" {
+			role = "model"
+		}
+		req.Contents = append(req.Contents, geminiContent{Role: role, Parts: []geminiPart{{Text: m.Content}}})
+	}
+
+	model := modelFor(route, in.Model)
+	url := fmt.Sprintf("%s/v1beta/models/%s:streamGenerateContent?alt=sse&key=%s", strings.TrimSuffix(route.BaseURL, "/"), model, route.APIKey)
+
+	body, err := doStream(ctx, route, url, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deltas := make(chan StreamDelta)
+	go func() {
+		defer close(deltas)
+		defer body.Close()
+
+		var usage models.CompletionUsage
+		failed := false
+		err := scanSSE(body, func(ev sseEvent) bool {
+			var chunk geminiResponse
+			if err := json.Unmarshal([]byte(ev.data), &chunk); err != nil {
+				failed = true
+				return sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: decode upstream chunk: %w", err)})
+			}
+			usage = models.CompletionUsage{
+				PromptTokens:     chunk.UsageMetadata.PromptTokenCount,
+				CompletionTokens: chunk.UsageMetadata.CandidatesTokenCount,
+				TotalTokens:      chunk.UsageMetadata.PromptTokenCount + chunk.UsageMetadata.CandidatesTokenCount,
+			}
+			if len(chunk.Candidates) == 0 {
+				return true
+			}
+			var text strings.Builder
+			for _, part := range chunk.Candidates[0].Content.Parts {
+				text.WriteString(part.Text)
+			}
+			if text.Len() == 0 {
+				return true
+			}
+			return sendDelta(ctx, deltas, StreamDelta{Content: text.String()})
+		})
+		if failed {
+			return
+		}
+		if err != nil {
+			sendDelta(ctx, deltas, StreamDelta{Err: fmt.Errorf("router: read upstream stream: %w", err)})
+			return
+		}
+		sendDelta(ctx, deltas, StreamDelta{Usage: usage, Done: true})
+	}()
+	return deltas, nil
+}
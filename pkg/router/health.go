@@ -0,0 +1,99 @@
+package router
+
+import (
+	"sync"
+	"time"
+)
+
+// HealthTracker records upstream failures/successes and latency samples,
+// removing an upstream from rotation after repeated failures and re-adding
+// it once its cooldown has passed or RecordSuccess is called for it. It
+// does not probe upstreams itself.
+type HealthTracker struct {
+	mu sync.Mutex
+
+	maxFailures int
+	cooldown    time.Duration
+
+	failures  map[string]int
+	downUntil map[string]time.Time
+	latencies map[string][]time.Duration
+}
+
+// NewHealthTracker builds a tracker that marks an upstream down after
+// maxFailures consecutive failures, keeping it out of rotation for cooldown
+// before it becomes eligible again.
+func NewHealthTracker(maxFailures int, cooldown time.Duration) *HealthTracker {
+	if maxFailures <= 0 {
+		maxFailures = 3
+	}
+	return &HealthTracker{
+		maxFailures: maxFailures,
+		cooldown:    cooldown,
+		failures:    make(map[string]int),
+		downUntil:   make(map[string]time.Time),
+		latencies:   make(map[string][]time.Duration),
+	}
+}
+
+// RecordSuccess clears the failure count for name and records latency.
+func (h *HealthTracker) RecordSuccess(name string, latency time.Duration) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[name] = 0
+	delete(h.downUntil, name)
+	samples := append(h.latencies[name], latency)
+	if len(samples) > 20 {
+		samples = samples[len(samples)-20:]
+	}
+	h.latencies[name] = samples
+}
+
+// RecordFailure increments the failure count for name, marking it down for
+// cooldown once maxFailures consecutive failures are reached.
+func (h *HealthTracker) RecordFailure(name string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.failures[name]++
+	if h.failures[name] >= h.maxFailures {
+		h.downUntil[name] = time.Now().Add(h.cooldown)
+	}
+}
+
+// IsHealthy reports whether name is currently eligible for traffic.
+func (h *HealthTracker) IsHealthy(name string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	until, down := h.downUntil[name]
+	if !down {
+		return true
+	}
+	return time.Now().After(until)
+}
+
+// AverageLatency returns the mean of recorded latency samples for name, or
+// zero if none have been recorded yet.
+func (h *HealthTracker) AverageLatency(name string) time.Duration {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	samples := h.latencies[name]
+	if len(samples) == 0 {
+		return 0
+	}
+	var total time.Duration
+	for _, s := range samples {
+		total += s
+	}
+	return total / time.Duration(len(samples))
+}
+
+// Filter returns the subset of upstreams currently considered healthy.
+func (h *HealthTracker) Filter(upstreams []Upstream) []Upstream {
+	healthy := make([]Upstream, 0, len(upstreams))
+	for _, u := range upstreams {
+		if h.IsHealthy(u.Name) {
+			healthy = append(healthy, u)
+		}
+	}
+	return healthy
+}
@@ -0,0 +1,111 @@
+package router
+
+import (
+	"fmt"
+	"os"
+	"path"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProviderKind selects which upstream wire schema a ProviderRoute's adapter
+// translates to/from. ProviderPassthrough is distinct from ProviderOpenAI
+// only in intent (an upstream already known to speak the simulator's own
+// schema); both use the same adapter.
+type ProviderKind string
+
+const (
+	ProviderOpenAI      ProviderKind = "openai"
+	ProviderAnthropic   ProviderKind = "anthropic"
+	ProviderOllama      ProviderKind = "ollama"
+	ProviderGemini      ProviderKind = "gemini"
+	ProviderPassthrough ProviderKind = "passthrough"
+)
+
+// ProviderRoute maps a model name glob or alias to a real upstream server,
+// along with everything a ProviderAdapter needs to reach and authenticate
+// against it.
+type ProviderRoute struct {
+	Match         string            `yaml:"match"`
+	Kind          ProviderKind      `yaml:"kind"`
+	BaseURL       string            `yaml:"base_url"`
+	APIKey        string            `yaml:"api_key,omitempty"`
+	ModelOverride string            `yaml:"model_override,omitempty"`
+	Headers       map[string]string `yaml:"headers,omitempty"`
+}
+
+// ProvidersConfig is the top-level YAML document read by
+// LoadProvidersConfig, mapping model name globs/aliases to upstream
+// provider routes:
+//
+//	routes:
+//	  - match: "claude-*"
+//	    kind: anthropic
+//	    base_url: https://api.anthropic.com
+//	    api_key: sk-ant-...
+//	  - match: smollm
+//	    kind: passthrough
+//	    base_url: http://127.0.0.1:8081
+type ProvidersConfig struct {
+	Routes []ProviderRoute `yaml:"routes"`
+}
+
+// LoadProvidersConfig reads and parses a provider-routing YAML config.
+func LoadProvidersConfig(configPath string) (*ProvidersConfig, error) {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read providers config: %w", err)
+	}
+	var cfg ProvidersConfig
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse providers config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// Resolve returns the first route whose Match pattern (an exact model name
+// or a path.Match glob such as "claude-*") matches model, in config order,
+// so an earlier, more specific route can take precedence over a later,
+// broader one.
+func (c *ProvidersConfig) Resolve(model string) (*ProviderRoute, bool) {
+	if c == nil {
+		return nil, false
+	}
+	for i := range c.Routes {
+		route := &c.Routes[i]
+		if route.Match == model {
+			return route, true
+		}
+		if ok, err := path.Match(route.Match, model); err == nil && ok {
+			return route, true
+		}
+	}
+	return nil, false
+}
+
+// Models returns the literal (non-glob) Match entries across all routes,
+// used to list provider-routed models alongside the simulator's built-in
+// ones. A Match pattern containing glob metacharacters names a family of
+// models rather than one concrete model, so it's excluded.
+func (c *ProvidersConfig) Models() []string {
+	if c == nil {
+		return nil
+	}
+	var names []string
+	for _, route := range c.Routes {
+		if isLiteralMatch(route.Match) {
+			names = append(names, route.Match)
+		}
+	}
+	return names
+}
+
+func isLiteralMatch(match string) bool {
+	for _, r := range match {
+		switch r {
+		case '*', '?', '[', '\\':
+			return false
+		}
+	}
+	return true
+}
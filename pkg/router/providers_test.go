@@ -0,0 +1,71 @@
+package router
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvidersConfig_ResolvePrefersEarlierExactOverLaterGlob(t *testing.T) {
+	cfg := &ProvidersConfig{Routes: []ProviderRoute{
+		{Match: "claude-haiku", Kind: ProviderAnthropic, BaseURL: "https://api.anthropic.com"},
+		{Match: "claude-*", Kind: ProviderAnthropic, BaseURL: "https://fallback.example.com"},
+	}}
+
+	route, ok := cfg.Resolve("claude-haiku")
+	require.True(t, ok)
+	require.Equal(t, "https://api.anthropic.com", route.BaseURL)
+
+	route, ok = cfg.Resolve("claude-opus")
+	require.True(t, ok)
+	require.Equal(t, "https://fallback.example.com", route.BaseURL)
+
+	_, ok = cfg.Resolve("gpt-sim-1")
+	require.False(t, ok)
+}
+
+func TestProvidersConfig_ResolveOnNilConfig(t *testing.T) {
+	var cfg *ProvidersConfig
+	_, ok := cfg.Resolve("anything")
+	require.False(t, ok)
+	require.Nil(t, cfg.Models())
+}
+
+func TestProvidersConfig_ModelsExcludesGlobs(t *testing.T) {
+	cfg := &ProvidersConfig{Routes: []ProviderRoute{
+		{Match: "claude-haiku", Kind: ProviderAnthropic},
+		{Match: "claude-*", Kind: ProviderAnthropic},
+		{Match: "smollm", Kind: ProviderPassthrough},
+	}}
+
+	require.ElementsMatch(t, []string{"claude-haiku", "smollm"}, cfg.Models())
+}
+
+func TestLoadProvidersConfig_ParsesYAML(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "providers.yaml")
+	yamlContent := `
+routes:
+  - match: "claude-*"
+    kind: anthropic
+    base_url: https://api.anthropic.com
+    api_key: sk-ant-test
+  - match: smollm
+    kind: passthrough
+    base_url: http://127.0.0.1:8081
+`
+	require.NoError(t, os.WriteFile(path, []byte(yamlContent), 0o644))
+
+	cfg, err := LoadProvidersConfig(path)
+	require.NoError(t, err)
+	require.Len(t, cfg.Routes, 2)
+	require.Equal(t, ProviderAnthropic, cfg.Routes[0].Kind)
+	require.Equal(t, "sk-ant-test", cfg.Routes[0].APIKey)
+}
+
+func TestLoadProvidersConfig_MissingFile(t *testing.T) {
+	_, err := LoadProvidersConfig("/nonexistent/providers.yaml")
+	require.Error(t, err)
+}
@@ -0,0 +1,168 @@
+// Package router provides routing strategies and a HealthTracker for
+// choosing among several real upstream servers the way an LLM gateway
+// would, and per-model provider routes (ProvidersConfig, ProviderAdapter)
+// that pkg/server uses to forward chat completions. The server's chat
+// handler and the `smollm-*` proxy flags in cmd/server do not use the
+// strategies or the HealthTracker.
+package router
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Upstream describes a single backend the router can send traffic to.
+type Upstream struct {
+	Name       string        `json:"name"`
+	BaseURL    string        `json:"base_url"`
+	ModelAlias string        `json:"model_alias,omitempty"`
+	Weight     int           `json:"weight,omitempty"`
+	Priority   int           `json:"priority,omitempty"`
+	Timeout    time.Duration `json:"timeout,omitempty"`
+	HealthPath string        `json:"health_path,omitempty"`
+}
+
+// Config is the top-level routing configuration, typically loaded from a
+// JSON file at startup.
+type Config struct {
+	Strategy  string     `json:"strategy"`
+	Upstreams []Upstream `json:"upstreams"`
+}
+
+// LoadConfig reads and parses a routing configuration file.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read router config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse router config: %w", err)
+	}
+	return &cfg, nil
+}
+
+// RoutingStrategy selects a live upstream from a pool for the next request.
+type RoutingStrategy interface {
+	// Next returns the upstream to use, given the currently healthy subset.
+	Next(healthy []Upstream) (Upstream, error)
+}
+
+// ErrNoHealthyUpstream is returned when every upstream is currently marked
+// down by the HealthTracker.
+var ErrNoHealthyUpstream = fmt.Errorf("router: no healthy upstream available")
+
+// RoundRobin cycles through upstreams in order.
+type RoundRobin struct {
+	mu   sync.Mutex
+	next int
+}
+
+func (r *RoundRobin) Next(healthy []Upstream) (Upstream, error) {
+	if len(healthy) == 0 {
+		return Upstream{}, ErrNoHealthyUpstream
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	u := healthy[r.next%len(healthy)]
+	r.next++
+	return u, nil
+}
+
+// Weighted picks upstreams in proportion to their Weight by stepping a
+// cursor through the total weight, so each full cycle picks an upstream
+// Weight times (upstreams with Weight <= 0 are treated as weight 1).
+type Weighted struct {
+	mu     sync.Mutex
+	cursor int
+}
+
+func (w *Weighted) Next(healthy []Upstream) (Upstream, error) {
+	if len(healthy) == 0 {
+		return Upstream{}, ErrNoHealthyUpstream
+	}
+	total := 0
+	for _, u := range healthy {
+		total += weightOf(u)
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.cursor = (w.cursor + 1) % total
+	pos := w.cursor
+	for _, u := range healthy {
+		pos -= weightOf(u)
+		if pos < 0 {
+			return u, nil
+		}
+	}
+	return healthy[len(healthy)-1], nil
+}
+
+func weightOf(u Upstream) int {
+	if u.Weight <= 0 {
+		return 1
+	}
+	return u.Weight
+}
+
+// LeastLatency picks the upstream with the lowest recorded average latency,
+// as tracked externally by a HealthTracker via RecordSuccess.
+type LeastLatency struct {
+	tracker *HealthTracker
+}
+
+// NewLeastLatency builds a LeastLatency strategy backed by tracker's
+// latency samples.
+func NewLeastLatency(tracker *HealthTracker) *LeastLatency {
+	return &LeastLatency{tracker: tracker}
+}
+
+func (l *LeastLatency) Next(healthy []Upstream) (Upstream, error) {
+	if len(healthy) == 0 {
+		return Upstream{}, ErrNoHealthyUpstream
+	}
+	best := healthy[0]
+	bestLatency := l.tracker.AverageLatency(best.Name)
+	for _, u := range healthy[1:] {
+		if lat := l.tracker.AverageLatency(u.Name); lat < bestLatency {
+			best, bestLatency = u, lat
+		}
+	}
+	return best, nil
+}
+
+// PriorityFailover always prefers the lowest Priority value (0 = most
+// preferred) among healthy upstreams, falling over to the next priority
+// tier only once the preferred ones are all marked down.
+type PriorityFailover struct{}
+
+func (PriorityFailover) Next(healthy []Upstream) (Upstream, error) {
+	if len(healthy) == 0 {
+		return Upstream{}, ErrNoHealthyUpstream
+	}
+	best := healthy[0]
+	for _, u := range healthy[1:] {
+		if u.Priority < best.Priority {
+			best = u
+		}
+	}
+	return best, nil
+}
+
+// StrategyFromName resolves a named strategy from Config.Strategy.
+func StrategyFromName(name string, tracker *HealthTracker) RoutingStrategy {
+	switch name {
+	case "weighted":
+		return &Weighted{}
+	case "least-latency":
+		return NewLeastLatency(tracker)
+	case "priority":
+		return PriorityFailover{}
+	default:
+		return &RoundRobin{}
+	}
+}
@@ -0,0 +1,41 @@
+package router
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRoundRobin_CyclesUpstreams(t *testing.T) {
+	upstreams := []Upstream{{Name: "a"}, {Name: "b"}}
+	rr := &RoundRobin{}
+
+	first, err := rr.Next(upstreams)
+	require.NoError(t, err)
+	second, err := rr.Next(upstreams)
+	require.NoError(t, err)
+	require.NotEqual(t, first.Name, second.Name)
+}
+
+func TestHealthTracker_MarksDownAfterFailuresAndRecovers(t *testing.T) {
+	tracker := NewHealthTracker(2, 10*time.Millisecond)
+	require.True(t, tracker.IsHealthy("upstream-a"))
+
+	tracker.RecordFailure("upstream-a")
+	require.True(t, tracker.IsHealthy("upstream-a"))
+	tracker.RecordFailure("upstream-a")
+	require.False(t, tracker.IsHealthy("upstream-a"))
+
+	time.Sleep(15 * time.Millisecond)
+	require.True(t, tracker.IsHealthy("upstream-a"))
+}
+
+func TestPriorityFailover_PrefersLowestPriority(t *testing.T) {
+	upstreams := []Upstream{{Name: "backup", Priority: 1}, {Name: "primary", Priority: 0}}
+	strategy := PriorityFailover{}
+
+	u, err := strategy.Next(upstreams)
+	require.NoError(t, err)
+	require.Equal(t, "primary", u.Name)
+}
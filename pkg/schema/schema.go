@@ -0,0 +1,249 @@
+// Package schema samples JSON values that satisfy a JSON Schema (draft
+// 2020-12 subset), for use as simulated tool-call arguments and structured
+// outputs. Schema models enough of the spec — $ref, oneOf/anyOf/allOf,
+// enum/const, numeric/string/array bounds — that Sample's output actually
+// validates against the caller's schema.
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Schema is a JSON Schema document or subschema.
+type Schema struct {
+	Type        string            `json:"type,omitempty"`
+	Description string            `json:"description,omitempty"`
+	Properties  map[string]Schema `json:"properties,omitempty"`
+	Required    []string          `json:"required,omitempty"`
+	Items       *Schema           `json:"items,omitempty"`
+
+	Enum  []interface{} `json:"enum,omitempty"`
+	Const interface{}   `json:"const,omitempty"`
+
+	Ref         string            `json:"$ref,omitempty"`
+	Defs        map[string]Schema `json:"$defs,omitempty"`
+	Definitions map[string]Schema `json:"definitions,omitempty"`
+
+	OneOf []Schema `json:"oneOf,omitempty"`
+	AnyOf []Schema `json:"anyOf,omitempty"`
+	AllOf []Schema `json:"allOf,omitempty"`
+
+	Minimum *float64 `json:"minimum,omitempty"`
+	Maximum *float64 `json:"maximum,omitempty"`
+
+	MinLength *int   `json:"minLength,omitempty"`
+	MaxLength *int   `json:"maxLength,omitempty"`
+	Pattern   string `json:"pattern,omitempty"`
+	Format    string `json:"format,omitempty"`
+
+	MinItems    *int `json:"minItems,omitempty"`
+	MaxItems    *int `json:"maxItems,omitempty"`
+	UniqueItems bool `json:"uniqueItems,omitempty"`
+}
+
+// SampleOptions configures Sample's generation.
+type SampleOptions struct {
+	// Seed makes generation reproducible for a given schema; zero draws a
+	// time-seeded value so callers that don't care get varied output.
+	Seed int64
+}
+
+// maxDepth bounds recursion through $ref chains so a schema that
+// legitimately refers to itself (e.g. a tree node with `children: [Node]`)
+// terminates instead of generating output forever.
+const maxDepth = 6
+
+// Sample generates a JSON value satisfying root, resolving `$ref` pointers
+// against root's own `$defs`/`definitions` (the only scope OpenAI-style
+// tool parameter and response_format schemas use).
+func Sample(root Schema, opts SampleOptions) (json.RawMessage, error) {
+	s := &sampler{rng: newRNG(opts.Seed), defs: mergedDefs(root), visited: make(map[string]int)}
+	v, err := s.sample(root, 0)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(v)
+}
+
+func mergedDefs(root Schema) map[string]Schema {
+	defs := make(map[string]Schema, len(root.Defs)+len(root.Definitions))
+	for k, v := range root.Defs {
+		defs[k] = v
+	}
+	for k, v := range root.Definitions {
+		defs[k] = v
+	}
+	return defs
+}
+
+type sampler struct {
+	rng     *rng
+	defs    map[string]Schema
+	visited map[string]int
+}
+
+func (s *sampler) sample(sch Schema, depth int) (interface{}, error) {
+	if depth > maxDepth {
+		return nil, nil
+	}
+
+	if sch.Ref != "" {
+		if seenAt, seen := s.visited[sch.Ref]; seen && seenAt <= depth {
+			return nil, nil // cycle: bail out rather than recurse forever
+		}
+		s.visited[sch.Ref] = depth
+
+		name := RefName(sch.Ref)
+		resolved, ok := s.defs[name]
+		if !ok {
+			return nil, fmt.Errorf("schema: unresolved $ref %q", sch.Ref)
+		}
+		return s.sample(resolved, depth+1)
+	}
+
+	if sch.Const != nil {
+		return sch.Const, nil
+	}
+	if len(sch.Enum) > 0 {
+		return sch.Enum[s.rng.intn(len(sch.Enum))], nil
+	}
+
+	if len(sch.AllOf) > 0 {
+		return s.sample(MergeAllOf(sch), depth)
+	}
+	if len(sch.OneOf) > 0 {
+		return s.sample(sch.OneOf[s.rng.intn(len(sch.OneOf))], depth+1)
+	}
+	if len(sch.AnyOf) > 0 {
+		return s.sample(sch.AnyOf[s.rng.intn(len(sch.AnyOf))], depth+1)
+	}
+
+	switch sch.Type {
+	case "object":
+		return s.sampleObject(sch, depth)
+	case "array":
+		return s.sampleArray(sch, depth)
+	case "string":
+		return s.sampleString(sch), nil
+	case "integer":
+		return s.sampleNumber(sch, true), nil
+	case "number":
+		return s.sampleNumber(sch, false), nil
+	case "boolean":
+		return s.rng.intn(2) == 0, nil
+	default:
+		if len(sch.Properties) > 0 {
+			return s.sampleObject(sch, depth)
+		}
+		return nil, nil
+	}
+}
+
+// MergeAllOf flattens allOf branches' properties/required into sch itself,
+// draft-2020-12 style (a value must satisfy every branch simultaneously).
+// Exported so pkg/grammar's schema compiler can apply the same flattening
+// before compiling a grammar rule for an allOf schema.
+func MergeAllOf(sch Schema) Schema {
+	merged := sch
+	merged.AllOf = nil
+	if merged.Type == "" {
+		merged.Type = "object"
+	}
+	if merged.Properties == nil {
+		merged.Properties = map[string]Schema{}
+	}
+	for _, branch := range sch.AllOf {
+		for name, prop := range branch.Properties {
+			merged.Properties[name] = prop
+		}
+		merged.Required = append(merged.Required, branch.Required...)
+	}
+	return merged
+}
+
+// sampleObject generates every declared property, honoring required (and
+// randomly including ~80% of optional ones for varied output). A required
+// property that fails to generate (typically an unresolved $ref) fails the
+// whole object so callers can surface a refusal instead of silently
+// emitting an incomplete value.
+func (s *sampler) sampleObject(sch Schema, depth int) (interface{}, error) {
+	required := make(map[string]bool, len(sch.Required))
+	for _, name := range sch.Required {
+		required[name] = true
+	}
+
+	obj := make(map[string]interface{}, len(sch.Properties))
+	for name, prop := range sch.Properties {
+		if !required[name] && s.rng.float64() <= 0.2 {
+			continue
+		}
+		v, err := s.sample(prop, depth+1)
+		if err != nil {
+			if required[name] {
+				return nil, fmt.Errorf("property %q: %w", name, err)
+			}
+			continue
+		}
+		obj[name] = v
+	}
+	return obj, nil
+}
+
+const (
+	defaultMinItems   = 1
+	maxUniqueAttempts = 50
+)
+
+func (s *sampler) sampleArray(sch Schema, depth int) (interface{}, error) {
+	minItems := defaultMinItems
+	if sch.MinItems != nil {
+		minItems = *sch.MinItems
+	}
+	maxItems := minItems + 2
+	if sch.MaxItems != nil {
+		maxItems = *sch.MaxItems
+	}
+	if maxItems < minItems {
+		maxItems = minItems
+	}
+	n := minItems
+	if maxItems > minItems {
+		n = minItems + s.rng.intn(maxItems-minItems+1)
+	}
+
+	itemSchema := sch.Items
+	if itemSchema == nil {
+		itemSchema = &Schema{Type: "string"}
+	}
+
+	items := make([]interface{}, 0, n)
+	seen := make(map[string]bool, n)
+	for attempts := 0; len(items) < n && attempts < maxUniqueAttempts; attempts++ {
+		v, err := s.sample(*itemSchema, depth+1)
+		if err != nil {
+			return nil, err
+		}
+		if sch.UniqueItems {
+			key := fmt.Sprintf("%v", v)
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+		}
+		items = append(items, v)
+	}
+	return items, nil
+}
+
+// RefName returns the final path segment of a `$ref` like `#/$defs/Node`,
+// the name under which the target is stored in Defs/Definitions. Exported
+// so pkg/grammar's schema compiler resolves $ref the same way Sample does.
+func RefName(ref string) string {
+	for i := len(ref) - 1; i >= 0; i-- {
+		if ref[i] == '/' {
+			return ref[i+1:]
+		}
+	}
+	return ref
+}
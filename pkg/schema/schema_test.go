@@ -0,0 +1,135 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestSample_ObjectWithRequiredAndOptional(t *testing.T) {
+	root := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"name": {Type: "string"},
+			"age":  {Type: "integer"},
+		},
+		Required: []string{"name"},
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &out))
+	require.Contains(t, out, "name")
+}
+
+func TestSample_ResolvesRef(t *testing.T) {
+	root := Schema{
+		Type: "object",
+		Defs: map[string]Schema{
+			"Address": {
+				Type:       "object",
+				Properties: map[string]Schema{"city": {Type: "string"}},
+				Required:   []string{"city"},
+			},
+		},
+		Properties: map[string]Schema{
+			"home": {Ref: "#/$defs/Address"},
+		},
+		Required: []string{"home"},
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &out))
+	home, ok := out["home"].(map[string]interface{})
+	require.True(t, ok)
+	require.Contains(t, home, "city")
+}
+
+func TestSample_UnresolvedRequiredRefFails(t *testing.T) {
+	root := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"home": {Ref: "#/$defs/Missing"},
+		},
+		Required: []string{"home"},
+	}
+
+	_, err := Sample(root, SampleOptions{Seed: 1})
+	require.Error(t, err)
+}
+
+func TestSample_EnumPicksDeclaredValue(t *testing.T) {
+	root := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"status": {Type: "string", Enum: []interface{}{"active", "inactive"}},
+		},
+		Required: []string{"status"},
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out map[string]string
+	require.NoError(t, json.Unmarshal(raw, &out))
+	require.Contains(t, []string{"active", "inactive"}, out["status"])
+}
+
+func TestSample_ArrayHonorsMinMaxItems(t *testing.T) {
+	two := 2
+	four := 4
+	root := Schema{
+		Type:     "array",
+		Items:    &Schema{Type: "integer"},
+		MinItems: &two,
+		MaxItems: &four,
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out []int
+	require.NoError(t, json.Unmarshal(raw, &out))
+	require.GreaterOrEqual(t, len(out), 2)
+	require.LessOrEqual(t, len(out), 4)
+}
+
+func TestSample_AllOfMergesBranches(t *testing.T) {
+	root := Schema{
+		AllOf: []Schema{
+			{Type: "object", Properties: map[string]Schema{"a": {Type: "string"}}, Required: []string{"a"}},
+			{Type: "object", Properties: map[string]Schema{"b": {Type: "string"}}, Required: []string{"b"}},
+		},
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out map[string]interface{}
+	require.NoError(t, json.Unmarshal(raw, &out))
+	require.Contains(t, out, "a")
+	require.Contains(t, out, "b")
+}
+
+func TestSample_PatternGeneratesMatchingString(t *testing.T) {
+	root := Schema{
+		Type: "object",
+		Properties: map[string]Schema{
+			"code": {Type: "string", Pattern: "^[A-Z]{3}-[0-9]{4}$"},
+		},
+		Required: []string{"code"},
+	}
+
+	raw, err := Sample(root, SampleOptions{Seed: 1})
+	require.NoError(t, err)
+
+	var out map[string]string
+	require.NoError(t, json.Unmarshal(raw, &out))
+	require.Regexp(t, "^[A-Z]{3}-[0-9]{4}$", out["code"])
+}
@@ -0,0 +1,238 @@
+package schema
+
+import (
+	"fmt"
+	"math/rand"
+	"regexp/syntax"
+	"strings"
+	"time"
+)
+
+// rng wraps math/rand.Rand so Sample's seeding policy (explicit seed, or a
+// time-seeded fallback) lives in one place.
+type rng struct {
+	*rand.Rand
+}
+
+func newRNG(seed int64) *rng {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &rng{rand.New(rand.NewSource(seed))}
+}
+
+func (r *rng) intn(n int) int {
+	if n <= 0 {
+		return 0
+	}
+	return r.Intn(n)
+}
+
+func (r *rng) float64() float64 {
+	return r.Float64()
+}
+
+var placeholderWords = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+}
+
+// sampleString generates a string honoring format, pattern, and
+// minLength/maxLength, in that order of precedence (format and pattern are
+// the more specific constraints; a schema author who sets both minLength
+// and pattern almost always means the pattern to win).
+func (s *sampler) sampleString(sch Schema) string {
+	if sch.Format != "" {
+		if v, ok := sampleFormat(sch.Format, s.rng); ok {
+			return v
+		}
+	}
+	if sch.Pattern != "" {
+		if v, ok := sampleRegex(sch.Pattern, s.rng); ok {
+			return v
+		}
+	}
+
+	minLen := 5
+	if sch.MinLength != nil {
+		minLen = *sch.MinLength
+	}
+	maxLen := minLen + 10
+	if sch.MaxLength != nil {
+		maxLen = *sch.MaxLength
+	}
+	if maxLen < minLen {
+		maxLen = minLen
+	}
+	length := minLen
+	if maxLen > minLen {
+		length = minLen + s.rng.intn(maxLen-minLen+1)
+	}
+	return randomWords(s.rng, length)
+}
+
+func randomWords(r *rng, targetLen int) string {
+	if targetLen <= 0 {
+		return ""
+	}
+	var sb strings.Builder
+	for sb.Len() < targetLen {
+		if sb.Len() > 0 {
+			sb.WriteByte(' ')
+		}
+		sb.WriteString(placeholderWords[r.intn(len(placeholderWords))])
+	}
+	out := sb.String()
+	if len(out) > targetLen {
+		out = out[:targetLen]
+	}
+	return out
+}
+
+// sampleFormat produces a value for the handful of `format` keywords
+// OpenAI-style tool schemas commonly use; unrecognized formats fall back
+// to the plain string generator.
+func sampleFormat(format string, r *rng) (string, bool) {
+	switch format {
+	case "date-time":
+		t := time.Unix(r.Int63n(2_000_000_000), 0).UTC()
+		return t.Format(time.RFC3339), true
+	case "date":
+		t := time.Unix(r.Int63n(2_000_000_000), 0).UTC()
+		return t.Format("2006-01-02"), true
+	case "email":
+		return fmt.Sprintf("user%d@example.com", r.intn(10000)), true
+	case "uuid":
+		return randomUUID(r), true
+	default:
+		return "", false
+	}
+}
+
+func randomUUID(r *rng) string {
+	b := make([]byte, 16)
+	r.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40 // version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// sampleRegex walks a parsed regexp AST to build a string that matches
+// pattern. It handles the constructs that show up in practice for
+// schema-validated fields (literals, character classes, repetition,
+// alternation) and reports ok=false for anything else (backreferences,
+// lookaround) so the caller can fall back to the plain string generator.
+func sampleRegex(pattern string, r *rng) (string, bool) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", false
+	}
+	var sb strings.Builder
+	if !walkRegex(re, r, &sb, 0) {
+		return "", false
+	}
+	return sb.String(), true
+}
+
+const maxRegexDepth = 20
+
+func walkRegex(re *syntax.Regexp, r *rng, sb *strings.Builder, depth int) bool {
+	if depth > maxRegexDepth {
+		return false
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, c := range re.Rune {
+			sb.WriteRune(c)
+		}
+		return true
+	case syntax.OpCharClass:
+		if len(re.Rune) == 0 {
+			return false
+		}
+		pair := r.intn(len(re.Rune) / 2)
+		lo, hi := re.Rune[pair*2], re.Rune[pair*2+1]
+		if hi < lo {
+			return false
+		}
+		sb.WriteRune(lo + rune(r.intn(int(hi-lo+1))))
+		return true
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		sb.WriteRune(rune('a' + r.intn(26)))
+		return true
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			if !walkRegex(sub, r, sb, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpCapture:
+		return walkRegex(re.Sub[0], r, sb, depth+1)
+	case syntax.OpStar:
+		n := r.intn(3)
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], r, sb, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpPlus:
+		n := 1 + r.intn(3)
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], r, sb, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpQuest:
+		if r.intn(2) == 0 {
+			return true
+		}
+		return walkRegex(re.Sub[0], r, sb, depth+1)
+	case syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		if max < 0 || max > min+5 {
+			max = min + 3
+		}
+		n := min
+		if max > min {
+			n = min + r.intn(max-min+1)
+		}
+		for i := 0; i < n; i++ {
+			if !walkRegex(re.Sub[0], r, sb, depth+1) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpAlternate:
+		if len(re.Sub) == 0 {
+			return false
+		}
+		return walkRegex(re.Sub[r.intn(len(re.Sub))], r, sb, depth+1)
+	case syntax.OpBeginLine, syntax.OpEndLine, syntax.OpBeginText, syntax.OpEndText, syntax.OpEmptyMatch:
+		return true
+	default:
+		return false
+	}
+}
+
+// sampleNumber honors minimum/maximum, defaulting to [0,100].
+func (s *sampler) sampleNumber(sch Schema, integer bool) interface{} {
+	min := 0.0
+	if sch.Minimum != nil {
+		min = *sch.Minimum
+	}
+	max := min + 100
+	if sch.Maximum != nil {
+		max = *sch.Maximum
+	}
+	if max < min {
+		max = min
+	}
+	v := min + s.rng.float64()*(max-min)
+	if integer {
+		return int64(v)
+	}
+	return v
+}
@@ -0,0 +1,250 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// estimatedBytesPerSecond approximates PCM16 mono audio at a modest sample
+// rate; used only to guess a transcript/word-timing duration from an
+// uploaded file's size when no real decoder is available.
+const estimatedBytesPerSecond = 32000
+
+// transcriptionWord is a single word-level timestamp in a verbose
+// transcription response.
+type transcriptionWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+}
+
+// transcriptionResponse is the plain `/v1/audio/transcriptions` JSON shape.
+type transcriptionResponse struct {
+	Text string `json:"text"`
+}
+
+// verboseTranscriptionResponse adds duration, language, and word timings.
+type verboseTranscriptionResponse struct {
+	Task     string              `json:"task"`
+	Language string              `json:"language"`
+	Duration float64             `json:"duration"`
+	Text     string              `json:"text"`
+	Words    []transcriptionWord `json:"words"`
+	Segments []transcriptionWord `json:"segments,omitempty"`
+}
+
+// transcriptionsHandler simulates `/v1/audio/transcriptions`: it accepts a
+// multipart upload, synthesizes plausible transcript text, and distributes
+// fake word-level timestamps across a duration estimated from the upload's
+// byte size (no real audio decoder is available in the simulator). A
+// multipart upload has no JSON body to carry a `simulator` field, so
+// fault-injection opts in only via the `X-Simulator-Fault` header, merged
+// through sseHandler like every other endpoint.
+func transcriptionsHandler(sseHandler *streaming.SSEStreamHandler, rateLimiter *streaming.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		reqFaults := sseHandler.MergedFaults(faultConfigFromRequest(r, nil))
+		if rejection, reject := streaming.EvaluateFaults(reqFaults, rateLimiter, apiKeyFromRequest(r), 0); reject {
+			writeFaultRejection(w, rejection)
+			return
+		}
+
+		if err := r.ParseMultipartForm(32 << 20); err != nil {
+			http.Error(w, fmt.Sprintf("invalid multipart upload: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		file, header, err := r.FormFile("file")
+		if err != nil {
+			http.Error(w, "missing required 'file' field", http.StatusBadRequest)
+			return
+		}
+		defer file.Close()
+
+		if !streaming.SimulateLatency(r.Context(), sseHandler.Defaults()) {
+			return
+		}
+
+		responseFormat := r.FormValue("response_format")
+		if responseFormat == "" {
+			responseFormat = "json"
+		}
+
+		duration := float64(header.Size) / estimatedBytesPerSecond
+		text := generator.NewCoherentTextGenerator().GenerateText(r.Context(), 30, 140)
+		words := distributeWordTimestamps(text, duration)
+
+		switch responseFormat {
+		case "text":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, text)
+		case "srt":
+			w.Header().Set("Content-Type", "text/plain")
+			fmt.Fprint(w, wordsToSRT(words))
+		case "vtt":
+			w.Header().Set("Content-Type", "text/vtt")
+			fmt.Fprint(w, wordsToVTT(words))
+		case "verbose_json":
+			resp := verboseTranscriptionResponse{
+				Task:     "transcribe",
+				Language: "en",
+				Duration: duration,
+				Text:     text,
+				Words:    words,
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(resp)
+		default: // "json"
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(transcriptionResponse{Text: text})
+		}
+	}
+}
+
+// speechContentTypes maps `/v1/audio/speech`'s `response_format` values to
+// their wire Content-Type, mirroring OpenAI's supported encodings.
+var speechContentTypes = map[string]string{
+	"mp3":  "audio/mpeg",
+	"opus": "audio/opus",
+	"aac":  "audio/aac",
+	"flac": "audio/flac",
+	"wav":  "audio/wav",
+	"pcm":  "audio/pcm",
+}
+
+// speechHandler simulates `/v1/audio/speech`: it returns a small synthesized
+// audio payload sized to roughly match `input`'s length at a plausible
+// speaking rate. Only "wav" and "mp3" are backed by real (silent) encoders;
+// every other supported format falls back to the wav payload under its own
+// Content-Type, since no real audio codec is available in the simulator.
+func speechHandler(sseHandler *streaming.SSEStreamHandler, rateLimiter *streaming.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in models.SpeechRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if in.Input == "" {
+			http.Error(w, "input is required", http.StatusBadRequest)
+			return
+		}
+
+		reqFaults := sseHandler.MergedFaults(faultConfigFromRequest(r, in.Simulator))
+		if rejection, reject := streaming.EvaluateFaults(reqFaults, rateLimiter, apiKeyFromRequest(r), 0); reject {
+			writeFaultRejection(w, rejection)
+			return
+		}
+		if !streaming.SimulateLatency(r.Context(), sseHandler.Defaults()) {
+			return
+		}
+
+		responseFormat := in.ResponseFormat
+		if responseFormat == "" {
+			responseFormat = "mp3"
+		}
+		contentType, ok := speechContentTypes[responseFormat]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unsupported response_format %q", responseFormat), http.StatusBadRequest)
+			return
+		}
+
+		var payload []byte
+		if responseFormat == "mp3" {
+			payload = utils.SilentMP3()
+		} else {
+			words := strings.Fields(in.Input)
+			duration := float64(len(words)) / 2.5 // ~150wpm speaking rate
+			payload = utils.SilentWAV(duration)
+		}
+
+		w.Header().Set("Content-Type", contentType)
+		_, _ = w.Write(payload)
+	}
+}
+
+// distributeWordTimestamps spreads text's words evenly across duration.
+func distributeWordTimestamps(text string, duration float64) []transcriptionWord {
+	words := strings.Fields(text)
+	if len(words) == 0 || duration <= 0 {
+		return nil
+	}
+	perWord := duration / float64(len(words))
+	timed := make([]transcriptionWord, len(words))
+	for i, word := range words {
+		timed[i] = transcriptionWord{
+			Word:  word,
+			Start: float64(i) * perWord,
+			End:   float64(i+1) * perWord,
+		}
+	}
+	return timed
+}
+
+func wordsToSRT(words []transcriptionWord) string {
+	var sb strings.Builder
+	for i, w := range words {
+		fmt.Fprintf(&sb, "%d\n%s --> %s\n%s\n\n", i+1, srtTimestamp(w.Start), srtTimestamp(w.End), w.Word)
+	}
+	return sb.String()
+}
+
+func wordsToVTT(words []transcriptionWord) string {
+	var sb strings.Builder
+	sb.WriteString("WEBVTT\n\n")
+	for _, w := range words {
+		fmt.Fprintf(&sb, "%s --> %s\n%s\n\n", vttTimestamp(w.Start), vttTimestamp(w.End), w.Word)
+	}
+	return sb.String()
+}
+
+func srtTimestamp(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	return fmt.Sprintf("%02d:%02d:%02d,%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+func vttTimestamp(seconds float64) string {
+	d := time.Duration(seconds * float64(time.Second))
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60, d.Milliseconds()%1000)
+}
+
+// audioForModalities builds the `audio` object added to a chat completion
+// message when the request's `modalities` include "audio": a silent WAV of
+// a plausible length alongside the matching transcript text.
+func audioForModalities(modalities []string, text string) *models.ChatCompletionAudio {
+	hasAudio := false
+	for _, m := range modalities {
+		if m == "audio" {
+			hasAudio = true
+			break
+		}
+	}
+	if !hasAudio {
+		return nil
+	}
+
+	words := strings.Fields(text)
+	duration := float64(len(words)) / 2.5 // ~150wpm speaking rate
+	return &models.ChatCompletionAudio{
+		ID:         utils.NewIDGenerator().GenerateID(),
+		Data:       utils.SilentWAVBase64(duration),
+		Transcript: text,
+		ExpiresAt:  time.Now().Add(24 * time.Hour).Unix(),
+	}
+}
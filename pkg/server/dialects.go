@@ -0,0 +1,87 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// anthropicMessage is the subset of Anthropic's Messages API request shape
+// the simulator understands: a flat role/content pair per turn.
+type anthropicMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// anthropicMessagesRequest models `/anthropic/v1/messages`, which shapes
+// its request body differently from OpenAI (no top-level `tools`/
+// `tool_choice` support here; `system` is a sibling of `messages` rather
+// than a message with role "system").
+type anthropicMessagesRequest struct {
+	Model     string             `json:"model"`
+	Messages  []anthropicMessage `json:"messages"`
+	System    string             `json:"system,omitempty"`
+	MaxTokens int64              `json:"max_tokens"`
+	Stream    bool               `json:"stream,omitempty"`
+}
+
+// anthropicMessagesHandler simulates Anthropic's `/v1/messages` endpoint so
+// client libraries written against that wire format can point at this
+// simulator; streaming requests reuse the SSE handler's `anthropic` dialect.
+func anthropicMessagesHandler(sseHandler *streaming.SSEStreamHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in anthropicMessagesRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		messages := make([]models.ChatCompletionMessage, 0, len(in.Messages)+1)
+		if in.System != "" {
+			messages = append(messages, models.ChatCompletionMessage{Role: "system", Content: in.System})
+		}
+		for _, m := range in.Messages {
+			messages = append(messages, models.ChatCompletionMessage{Role: m.Role, Content: m.Content})
+		}
+
+		if in.Stream {
+			req := &streaming.ChatCompletionRequest{Model: in.Model, Messages: messages, MaxTokens: in.MaxTokens}
+			_ = sseHandler.StreamCompletion(r.Context(), w, req, streaming.StreamOptions{ChunkSize: 3, Dialect: streaming.DialectAnthropic})
+			return
+		}
+
+		minLen, maxLen := streaming.MapResponseLengthToRangeForMessages("", messages)
+		text := generator.NewCoherentTextGenerator().GenerateText(r.Context(), minLen, maxLen)
+		inputTokens := utils.EstimateTokens(in.System)
+		for _, m := range in.Messages {
+			inputTokens += utils.EstimateTokens(m.Content)
+		}
+
+		resp := map[string]interface{}{
+			"id":    utils.NewIDGenerator().GenerateID(),
+			"type":  "message",
+			"role":  "assistant",
+			"model": in.Model,
+			"content": []map[string]interface{}{
+				{"type": "text", "text": text},
+			},
+			"stop_reason": "end_turn",
+			"usage": map[string]interface{}{
+				"input_tokens":  inputTokens,
+				"output_tokens": utils.EstimateTokens(text),
+			},
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
@@ -0,0 +1,100 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/internal/nanochat"
+)
+
+// NewRouterWithDownloadStatus wraps base with two read-only endpoints
+// backed by registry:
+//
+//	GET /v1/nanochat/download/status  - a JSON snapshot of every file
+//	                                     registry has heard about
+//	GET /v1/nanochat/download/events  - the same updates streamed live as
+//	                                     Server-Sent Events
+//
+// registry is typically the ProgressReporter a nanochat.ModelManager was
+// built with via nanochat.WithProgressReporter; a nil registry reports an
+// always-empty snapshot and a stream that never emits.
+func NewRouterWithDownloadStatus(base http.Handler, registry *nanochat.StatusRegistry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/nanochat/download/status", downloadStatusHandler(registry))
+	mux.HandleFunc("/v1/nanochat/download/events", downloadEventsHandler(registry))
+	mux.Handle("/", base)
+	return mux
+}
+
+func downloadStatusHandler(registry *nanochat.StatusRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var files []nanochat.FileStatus
+		if registry != nil {
+			files = registry.Snapshot()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"files": files})
+	}
+}
+
+func downloadEventsHandler(registry *nanochat.StatusRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+
+		if registry == nil {
+			<-r.Context().Done()
+			return
+		}
+
+		updates := make(chan nanochat.FileStatus, 16)
+		cancel := registry.Subscribe(updates)
+		defer cancel()
+
+		for _, status := range registry.Snapshot() {
+			writeStatusEvent(w, status)
+		}
+		flusher.Flush()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case status := <-updates:
+				writeStatusEvent(w, status)
+				flusher.Flush()
+			case <-time.After(15 * time.Second):
+				fmt.Fprint(w, ": keep-alive\n\n")
+				flusher.Flush()
+			}
+		}
+	}
+}
+
+func writeStatusEvent(w http.ResponseWriter, status nanochat.FileStatus) {
+	data, err := json.Marshal(status)
+	if err != nil {
+		return
+	}
+	fmt.Fprintf(w, "data: %s\n\n", data)
+}
@@ -0,0 +1,73 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/internal/nanochat"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDownloadStatusHandler_ReturnsSnapshot(t *testing.T) {
+	registry := nanochat.NewStatusRegistry()
+	registry.OnStart("model.pt", 1000)
+	registry.OnProgress("model.pt", 500, 1000, 100)
+
+	s := httptest.NewServer(NewRouterWithDownloadStatus(NewRouter(), registry))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/v1/nanochat/download/status")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out struct {
+		Files []nanochat.FileStatus `json:"files"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out.Files, 1)
+	require.Equal(t, "model.pt", out.Files[0].File)
+	require.Equal(t, nanochat.DownloadStateRunning, out.Files[0].State)
+}
+
+func TestDownloadStatusHandler_FallsThroughToBaseRouter(t *testing.T) {
+	s := httptest.NewServer(NewRouterWithDownloadStatus(NewRouter(), nil))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestDownloadEventsHandler_StreamsUpdates(t *testing.T) {
+	registry := nanochat.NewStatusRegistry()
+
+	s := httptest.NewServer(NewRouterWithDownloadStatus(NewRouter(), registry))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/v1/nanochat/download/events")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	registry.OnStart("model.pt", 1000)
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+		var status nanochat.FileStatus
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &status))
+		require.Equal(t, "model.pt", status.File)
+		require.Equal(t, nanochat.DownloadStatePending, status.State)
+		return
+	}
+	t.Fatal("stream closed before receiving an event")
+}
@@ -0,0 +1,119 @@
+package server
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
+)
+
+// embeddingsHandler simulates `/v1/embeddings`: each input string gets a
+// deterministic, L2-normalized pseudo-embedding derived from a hash of the
+// string (see generator.GenerateEmbedding), so repeated calls with the same
+// input are stable - critical for testing vector-store integrations -
+// without needing a real embedding model. It honors the same fault-injection
+// and pacing controls chat completions does, merged through sseHandler.
+func embeddingsHandler(tokRouter *tokenizer.Router, sseHandler *streaming.SSEStreamHandler, rateLimiter *streaming.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in models.EmbeddingRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		reqFaults := sseHandler.MergedFaults(faultConfigFromRequest(r, in.Simulator))
+		if rejection, reject := streaming.EvaluateFaults(reqFaults, rateLimiter, apiKeyFromRequest(r), 0); reject {
+			writeFaultRejection(w, rejection)
+			return
+		}
+		if !streaming.SimulateLatency(r.Context(), sseHandler.Defaults()) {
+			return
+		}
+
+		inputs := embeddingInputsFromRequest(in.Input)
+		if len(inputs) == 0 {
+			http.Error(w, "input must be a non-empty string or array of strings", http.StatusBadRequest)
+			return
+		}
+
+		dimensions := generator.EmbeddingDimensions(in.Model)
+		if in.Dimensions != nil && *in.Dimensions > 0 {
+			dimensions = *in.Dimensions
+		}
+
+		tok := tokRouter.Resolve(in.Model)
+		encodingFormat := in.EncodingFormat
+		if encodingFormat == "" {
+			encodingFormat = "float"
+		}
+
+		data := make([]models.Embedding, len(inputs))
+		var promptTokens int64
+		for i, input := range inputs {
+			vec := generator.GenerateEmbedding(input, dimensions)
+			promptTokens += tok.Count(input)
+
+			var embedding interface{}
+			if encodingFormat == "base64" {
+				embedding = encodeEmbeddingBase64(vec)
+			} else {
+				embedding = vec
+			}
+			data[i] = models.Embedding{Object: "embedding", Embedding: embedding, Index: int64(i)}
+		}
+
+		resp := models.EmbeddingResponse{
+			Object: "list",
+			Data:   data,
+			Model:  in.Model,
+			Usage:  models.EmbeddingUsage{PromptTokens: promptTokens, TotalTokens: promptTokens},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// embeddingInputsFromRequest normalizes EmbeddingRequest.Input, which per
+// OpenAI's API may be a single string or an array of strings, into a flat
+// slice.
+func embeddingInputsFromRequest(input interface{}) []string {
+	switch v := input.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		inputs := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				inputs = append(inputs, s)
+			}
+		}
+		return inputs
+	default:
+		return nil
+	}
+}
+
+// encodeEmbeddingBase64 renders vec as OpenAI's "base64" encoding_format
+// does: little-endian float32 values, base64-encoded.
+func encodeEmbeddingBase64(vec []float64) string {
+	buf := make([]byte, 4*len(vec))
+	for i, v := range vec {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(float32(v)))
+	}
+	return base64.StdEncoding.EncodeToString(buf)
+}
@@ -0,0 +1,72 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+)
+
+// faultConfigFromRequest resolves the streaming.FaultConfig a single request
+// opts into, for chaos-testing OpenAI clients against production-like
+// failure modes. The `X-Simulator-Fault` header, when present, is a
+// JSON-encoded models.SimulatorConfig and takes precedence over an
+// equivalent `simulator` request body field (cfg), so a client can override
+// fault injection without modifying its request body. Every endpoint that
+// accepts a `simulator` field - chat completions, embeddings, audio, images -
+// resolves it the same way by passing that field here.
+func faultConfigFromRequest(r *http.Request, cfg *models.SimulatorConfig) streaming.FaultConfig {
+	if header := r.Header.Get("X-Simulator-Fault"); header != "" {
+		var parsed models.SimulatorConfig
+		if err := json.Unmarshal([]byte(header), &parsed); err == nil {
+			cfg = &parsed
+		}
+	}
+	if cfg == nil {
+		return streaming.FaultConfig{}
+	}
+	return streaming.FaultConfig{
+		ErrorRate:           cfg.ErrorRate,
+		ErrorCodes:          cfg.ErrorCodes,
+		TruncateAfterTokens: cfg.TruncateAfterTokens,
+		MalformedJSONRate:   cfg.MalformedJSONRate,
+		StallAfterTokens:    cfg.StallAfterTokens,
+		StallDuration:       time.Duration(cfg.StallDurationMs) * time.Millisecond,
+		InvalidFinishReason: cfg.InvalidFinishReason,
+		RateLimitPerMinute:  cfg.RateLimitPerMinute,
+		RateLimitBurst:      cfg.RateLimitBurst,
+	}
+}
+
+// apiKeyFromRequest extracts the bearer token clients send as their API
+// key, falling back to a shared bucket for unauthenticated requests so
+// rate-limit fault injection still has a key to track.
+func apiKeyFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	return "anonymous"
+}
+
+// writeFaultRejection renders a FaultRejection as an OpenAI-shaped error
+// response, the same `error.{message,type,code}` envelope real provider
+// errors use, so a client's error-handling path sees a familiar body.
+func writeFaultRejection(w http.ResponseWriter, rejection streaming.FaultRejection) {
+	if rejection.RetryAfter > 0 {
+		w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(rejection.RetryAfter.Seconds()))))
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(rejection.StatusCode)
+	_ = json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]interface{}{
+			"message": fmt.Sprintf("simulated upstream fault: HTTP %d", rejection.StatusCode),
+			"type":    "simulator_fault",
+			"code":    rejection.StatusCode,
+		},
+	})
+}
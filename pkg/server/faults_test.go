@@ -0,0 +1,88 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_SimulatorFaultHeaderForcesErrorCode(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}},
+	}
+	body, _ := json.Marshal(payload)
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/v1/chat/completions", bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Simulator-Fault", `{"error_rate":1,"error_codes":[503]}`)
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusServiceUnavailable, resp.StatusCode)
+
+	var out map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	errObj, ok := out["error"].(map[string]interface{})
+	require.True(t, ok)
+	require.Equal(t, "simulator_fault", errObj["type"])
+}
+
+func TestRouter_SimulatorBodyFieldForcesRateLimitRetryAfter(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}},
+		Simulator: &models.SimulatorConfig{
+			RateLimitPerMinute: 60,
+			RateLimitBurst:     1,
+		},
+	}
+	body, _ := json.Marshal(payload)
+
+	resp1, err := http.Post(s.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp1.Body.Close()
+	require.Equal(t, http.StatusOK, resp1.StatusCode)
+
+	resp2, err := http.Post(s.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp2.Body.Close()
+	require.Equal(t, http.StatusTooManyRequests, resp2.StatusCode)
+	require.NotEmpty(t, resp2.Header.Get("Retry-After"))
+}
+
+func TestRouter_SimulatorInvalidFinishReasonAppliesToNonStreaming(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}},
+		Simulator: &models.SimulatorConfig{
+			InvalidFinishReason: "content_moderation",
+		},
+	}
+	body, _ := json.Marshal(payload)
+
+	resp, err := http.Post(s.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out models.ChatCompletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "content_moderation", out.Choices[0].FinishReason)
+}
@@ -0,0 +1,156 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"image/color"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// defaultImageSize is used for any request that omits `size` or sends an
+// unparseable one.
+const defaultImageSize = "1024x1024"
+
+// imagesGenerationsHandler simulates `/v1/images/generations`: each image is
+// a solid-color PNG of the requested size, its color derived from a hash of
+// the prompt (and each image's index, so `n>1` still yields distinct
+// colors), so repeated calls with the same prompt are stable. It honors the
+// same fault-injection and pacing controls chat completions does, merged
+// through sseHandler.
+func imagesGenerationsHandler(sseHandler *streaming.SSEStreamHandler, rateLimiter *streaming.RateLimiter) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in models.ImageGenerationRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+		if in.Prompt == "" {
+			http.Error(w, "prompt is required", http.StatusBadRequest)
+			return
+		}
+
+		reqFaults := sseHandler.MergedFaults(faultConfigFromRequest(r, in.Simulator))
+		if rejection, reject := streaming.EvaluateFaults(reqFaults, rateLimiter, apiKeyFromRequest(r), 0); reject {
+			writeFaultRejection(w, rejection)
+			return
+		}
+		if !streaming.SimulateLatency(r.Context(), sseHandler.Defaults()) {
+			return
+		}
+
+		n := int64(1)
+		if in.N != nil && *in.N > 0 {
+			n = *in.N
+		}
+		width, height := parseImageSize(in.Size)
+		responseFormat := in.ResponseFormat
+		if responseFormat == "" {
+			responseFormat = "url"
+		}
+
+		data := make([]models.GeneratedImage, n)
+		for i := int64(0); i < n; i++ {
+			c := colorForPrompt(fmt.Sprintf("%s#%d", in.Prompt, i))
+			image := models.GeneratedImage{}
+			if responseFormat == "b64_json" {
+				b64, err := utils.SolidColorPNGBase64(width, height, c)
+				if err != nil {
+					http.Error(w, fmt.Sprintf("failed to render image: %v", err), http.StatusInternalServerError)
+					return
+				}
+				image.B64JSON = b64
+			} else {
+				image.URL = fmt.Sprintf("/v1/images/generated/%s-%dx%d.png", hexColor(c), width, height)
+			}
+			data[i] = image
+		}
+
+		resp := models.ImageGenerationResponse{Created: time.Now().Unix(), Data: data}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// imagesGeneratedHandler serves the PNG a `url`-format imagesGenerationsHandler
+// response points at. The color and size are both encoded in the URL path
+// itself (see hexColor/parseImageSize), so no server-side storage is needed
+// to "remember" an image between the generation call and this fetch.
+func imagesGeneratedHandler(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Path, "/v1/images/generated/"), ".png")
+	parts := strings.SplitN(name, "-", 2)
+	if len(parts) != 2 {
+		http.NotFound(w, r)
+		return
+	}
+	c, ok := colorFromHex(parts[0])
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	width, height := parseImageSize(parts[1])
+
+	png, err := utils.SolidColorPNG(width, height, c)
+	if err != nil {
+		http.Error(w, fmt.Sprintf("failed to render image: %v", err), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(png)
+}
+
+// parseImageSize parses a `{width}x{height}` size string (OpenAI's
+// "1024x1024", "1792x1024", etc.), falling back to defaultImageSize for
+// anything it can't parse.
+func parseImageSize(size string) (width, height int) {
+	w, h, ok := strings.Cut(size, "x")
+	if ok {
+		if wi, err := strconv.Atoi(w); err == nil {
+			if hi, err := strconv.Atoi(h); err == nil {
+				return wi, hi
+			}
+		}
+	}
+	w, h, _ = strings.Cut(defaultImageSize, "x")
+	wi, _ := strconv.Atoi(w)
+	hi, _ := strconv.Atoi(h)
+	return wi, hi
+}
+
+// colorForPrompt derives a deterministic RGB color from a hash of input, so
+// identical prompts (and, within one request, distinct image indices) yield
+// stable, distinguishable placeholder colors.
+func colorForPrompt(input string) color.RGBA {
+	sum := sha256.Sum256([]byte(input))
+	return color.RGBA{R: sum[0], G: sum[1], B: sum[2], A: 0xFF}
+}
+
+// hexColor renders c's RGB channels as a 6-hex-digit string for embedding in
+// a generated image's URL.
+func hexColor(c color.RGBA) string {
+	return fmt.Sprintf("%02x%02x%02x", c.R, c.G, c.B)
+}
+
+// colorFromHex parses hexColor's output back into a color.RGBA.
+func colorFromHex(hex string) (color.RGBA, bool) {
+	if len(hex) != 6 {
+		return color.RGBA{}, false
+	}
+	v, err := strconv.ParseUint(hex, 16, 32)
+	if err != nil {
+		return color.RGBA{}, false
+	}
+	return color.RGBA{R: uint8(v >> 16), G: uint8(v >> 8), B: uint8(v), A: 0xFF}, true
+}
@@ -0,0 +1,110 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/quantalogic/openai-api-simulator/internal/nanochat"
+)
+
+// NewRouterWithModelRegistry wraps base with admin endpoints for a
+// nanochat.ModelRegistry:
+//
+//	POST   /v1/admin/models/{id}/pull  - start (or rejoin) a background
+//	                                      download, returning a job id.
+//	                                      Progress is observed through
+//	                                      whatever ProgressReporter
+//	                                      registry was built with, e.g.
+//	                                      NewRouterWithDownloadStatus.
+//	DELETE /v1/admin/models/{id}       - remove the model's cached files.
+//
+// It also augments GET /v1/models with registry's model aliases, merged
+// alongside base's own listing, so a client can discover models that
+// have been pulled at runtime without a server restart.
+func NewRouterWithModelRegistry(base http.Handler, registry *nanochat.ModelRegistry) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/v1/admin/models/", adminModelsHandler(registry))
+	if registry != nil {
+		mux.HandleFunc("/v1/models", modelsWithRegistryHandler(base, registry))
+	}
+	mux.Handle("/", base)
+	return mux
+}
+
+// adminModelsHandler dispatches POST .../{id}/pull and DELETE .../{id}
+// by hand, matching this package's existing convention of checking
+// r.Method inside a single handler rather than relying on mux-level
+// method routing.
+func adminModelsHandler(registry *nanochat.ModelRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if registry == nil {
+			http.NotFound(w, r)
+			return
+		}
+
+		rest := strings.TrimPrefix(r.URL.Path, "/v1/admin/models/")
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(rest, "/pull"):
+			id := strings.TrimSuffix(rest, "/pull")
+			jobID, err := registry.Pull(id)
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			_ = json.NewEncoder(w).Encode(map[string]string{"job_id": jobID, "model_id": id})
+
+		case r.Method == http.MethodDelete && rest != "" && !strings.Contains(rest, "/"):
+			if err := registry.Delete(rest); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			http.NotFound(w, r)
+		}
+	}
+}
+
+// modelsWithRegistryHandler runs base's own /v1/models handler and
+// appends one entry per registry model alias to its "data" list, so
+// runtime-pulled models show up without duplicating base's existing
+// static model list.
+func modelsWithRegistryHandler(base http.Handler, registry *nanochat.ModelRegistry) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		rec := httptest.NewRecorder()
+		base.ServeHTTP(rec, r)
+
+		var payload map[string]interface{}
+		if err := json.Unmarshal(rec.Body.Bytes(), &payload); err != nil {
+			// base didn't return JSON (e.g. a non-GET method); pass its
+			// response through unmodified.
+			for k, vs := range rec.Header() {
+				w.Header()[k] = vs
+			}
+			w.WriteHeader(rec.Code)
+			_, _ = w.Write(rec.Body.Bytes())
+			return
+		}
+
+		data, _ := payload["data"].([]interface{})
+		for _, id := range registry.IDs() {
+			spec, _ := registry.Spec(id)
+			alias := spec.Alias
+			if alias == "" {
+				alias = id
+			}
+			data = append(data, map[string]interface{}{"id": alias, "object": "model", "owned_by": "nanochat"})
+		}
+		payload["data"] = data
+
+		for k, vs := range rec.Header() {
+			w.Header()[k] = vs
+		}
+		w.WriteHeader(rec.Code)
+		_ = json.NewEncoder(w).Encode(payload)
+	}
+}
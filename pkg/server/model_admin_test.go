@@ -0,0 +1,95 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/internal/nanochat"
+	"github.com/stretchr/testify/require"
+)
+
+func testAdminRegistry(t *testing.T) (*nanochat.ModelRegistry, *httptest.Server) {
+	t.Helper()
+	content := []byte("weights")
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Length", "7")
+		w.WriteHeader(http.StatusOK)
+		w.Write(content)
+	}))
+	t.Cleanup(upstream.Close)
+
+	spec := nanochat.ModelSpec{
+		ID:     "demo-model",
+		Alias:  "demo",
+		Source: upstream.URL,
+		Files:  []nanochat.ModelFile{{Name: "weights.bin"}},
+	}
+	registry := nanochat.NewModelRegistry(t.TempDir(), []nanochat.ModelSpec{spec}, nil)
+	return registry, upstream
+}
+
+func TestAdminModelsHandler_PullReturnsJobID(t *testing.T) {
+	registry, _ := testAdminRegistry(t)
+	s := httptest.NewServer(NewRouterWithModelRegistry(NewRouter(), registry))
+	defer s.Close()
+
+	resp, err := http.Post(s.URL+"/v1/admin/models/demo-model/pull", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out map[string]string
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "demo-model", out["model_id"])
+	require.NotEmpty(t, out["job_id"])
+}
+
+func TestAdminModelsHandler_PullUnknownModelReturns404(t *testing.T) {
+	registry, _ := testAdminRegistry(t)
+	s := httptest.NewServer(NewRouterWithModelRegistry(NewRouter(), registry))
+	defer s.Close()
+
+	resp, err := http.Post(s.URL+"/v1/admin/models/does-not-exist/pull", "application/json", nil)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestAdminModelsHandler_DeleteCleansCache(t *testing.T) {
+	registry, _ := testAdminRegistry(t)
+	s := httptest.NewServer(NewRouterWithModelRegistry(NewRouter(), registry))
+	defer s.Close()
+
+	req, err := http.NewRequest(http.MethodDelete, s.URL+"/v1/admin/models/demo-model", nil)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusNoContent, resp.StatusCode)
+}
+
+func TestModelsEndpoint_MergesRegistryAliases(t *testing.T) {
+	registry, _ := testAdminRegistry(t)
+	s := httptest.NewServer(NewRouterWithModelRegistry(NewRouter(), registry))
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/v1/models")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+
+	var found bool
+	for _, m := range out.Data {
+		if m["id"] == "demo" {
+			found = true
+		}
+	}
+	require.True(t, found, "expected registry alias %q in /v1/models, got %v", "demo", out.Data)
+}
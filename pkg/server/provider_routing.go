@@ -0,0 +1,102 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/router"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// serveRoutedChatCompletion handles a chat completion whose model matched a
+// router.ProviderRoute: it forwards the request through the route's
+// ProviderAdapter and renders the translated text back in OpenAI's
+// chat.completion (or chat.completion.chunk, for in.Stream) shape. When
+// in.Stream is set, it uses the adapter's CompleteStream so the client sees
+// the upstream's own incremental delivery instead of a blocked-then-chunked
+// replay.
+func serveRoutedChatCompletion(w http.ResponseWriter, r *http.Request, route router.ProviderRoute, in models.ChatCompletionRequest) {
+	adapter, err := router.NewProviderAdapter(route.Kind)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	if !in.Stream {
+		text, usage, err := adapter.Complete(r.Context(), route, &in)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadGateway)
+			return
+		}
+		resp := models.ChatCompletion{
+			ID:      utils.NewIDGenerator().GenerateID(),
+			Object:  "chat.completion",
+			Created: time.Now().Unix(),
+			Model:   in.Model,
+			Choices: []models.ChatCompletionChoice{{
+				Index:        0,
+				Message:      models.ChatCompletionMessage{Role: "assistant", Content: text},
+				FinishReason: "stop",
+			}},
+			Usage: usage,
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+		return
+	}
+
+	deltas, err := adapter.CompleteStream(r.Context(), route, &in)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+
+	completionID := utils.NewIDGenerator().GenerateID()
+	created := time.Now().Unix()
+	sawFirst := false
+	for d := range deltas {
+		if d.Err != nil {
+			log.Printf("router: upstream stream for %s failed mid-response: %v", in.Model, d.Err)
+			break
+		}
+		if d.Done {
+			break
+		}
+		delta := models.ChatCompletionChunkChoiceDelta{Content: d.Content}
+		if !sawFirst {
+			delta.Role = "assistant"
+			sawFirst = true
+		}
+		writeRoutedChunk(w, flusher, completionID, created, in.Model, delta, nil)
+	}
+	finishReason := "stop"
+	writeRoutedChunk(w, flusher, completionID, created, in.Model, models.ChatCompletionChunkChoiceDelta{}, &finishReason)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+}
+
+func writeRoutedChunk(w http.ResponseWriter, flusher http.Flusher, completionID string, created int64, model string, delta models.ChatCompletionChunkChoiceDelta, finishReason *string) {
+	chunk := models.ChatCompletionChunk{
+		ID:      completionID,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []models.ChatCompletionChunkChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
+	}
+	data, _ := json.Marshal(chunk)
+	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	flusher.Flush()
+}
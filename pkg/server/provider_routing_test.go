@@ -0,0 +1,144 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRouter_RoutesConfiguredModelToUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req models.ChatCompletionRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		resp := models.ChatCompletion{
+			ID:     "real-upstream-1",
+			Object: "chat.completion",
+			Model:  req.Model,
+			Choices: []models.ChatCompletionChoice{{
+				Index:        0,
+				Message:      models.ChatCompletionMessage{Role: "assistant", Content: "hello from upstream"},
+				FinishReason: "stop",
+			}},
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "providers.yaml")
+	yamlContent := "routes:\n  - match: real-model\n    kind: passthrough\n    base_url: " + upstream.URL + "\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0o644))
+
+	handler := NewRouterWithOptions(streaming.StreamOptions{}, "", false, "", defaultMaxClientBatchSize, false, "", configPath)
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:    "real-model",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}},
+	}
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/chat/completions", "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out models.ChatCompletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "hello from upstream", out.Choices[0].Message.Content)
+}
+
+func TestRouter_StreamsRoutedCompletionIncrementallyFromUpstream(t *testing.T) {
+	upstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher := w.(http.Flusher)
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, word := range []string{"hello", " from", " upstream"} {
+			chunk := models.ChatCompletionChunk{
+				Object:  "chat.completion.chunk",
+				Choices: []models.ChatCompletionChunkChoice{{Delta: models.ChatCompletionChunkChoiceDelta{Content: word}}},
+			}
+			data, _ := json.Marshal(chunk)
+			fmt.Fprintf(w, "data: %s\n\n", data)
+			flusher.Flush()
+		}
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		flusher.Flush()
+	}))
+	defer upstream.Close()
+
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "providers.yaml")
+	yamlContent := "routes:\n  - match: real-model\n    kind: passthrough\n    base_url: " + upstream.URL + "\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0o644))
+
+	handler := NewRouterWithOptions(streaming.StreamOptions{}, "", false, "", defaultMaxClientBatchSize, false, "", configPath)
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:    "real-model",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}},
+		Stream:   true,
+	}
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/chat/completions", "application/json", strings.NewReader(string(body)))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	raw, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+
+	var gotWords []string
+	for _, line := range strings.Split(string(raw), "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok || data == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		require.NoError(t, json.Unmarshal([]byte(data), &chunk))
+		if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" {
+			gotWords = append(gotWords, chunk.Choices[0].Delta.Content)
+		}
+	}
+	require.Equal(t, []string{"hello", " from", " upstream"}, gotWords)
+}
+
+func TestRouter_ModelsListIncludesProviderRoutes(t *testing.T) {
+	dir := t.TempDir()
+	configPath := filepath.Join(dir, "providers.yaml")
+	yamlContent := "routes:\n  - match: claude-haiku\n    kind: anthropic\n    base_url: https://api.anthropic.com\n"
+	require.NoError(t, os.WriteFile(configPath, []byte(yamlContent), 0o644))
+
+	handler := NewRouterWithOptions(streaming.StreamOptions{}, "", false, "", defaultMaxClientBatchSize, false, "", configPath)
+	s := httptest.NewServer(handler)
+	defer s.Close()
+
+	resp, err := http.Get(s.URL + "/v1/models")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out struct {
+		Data []map[string]interface{} `json:"data"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+
+	var found bool
+	for _, m := range out.Data {
+		if m["id"] == "claude-haiku" {
+			found = true
+		}
+	}
+	require.True(t, found, "expected claude-haiku in models list, got %v", out.Data)
+}
@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// responsesRequest models the subset of the OpenAI Responses API
+// (`/v1/responses`) request body the simulator understands: `input` accepts
+// either a bare string or the full array-of-items shape parsed by
+// parseResponsesInput.
+type responsesRequest struct {
+	Model  string          `json:"model"`
+	Input  json.RawMessage `json:"input"`
+	Stream bool            `json:"stream,omitempty"`
+}
+
+// responsesInputMessage is one normalized turn extracted from `input`: Role
+// defaults to "user" for a bare string or a content-only item, and Text is
+// the concatenation of that turn's input_text parts (input_image/
+// input_audio parts contribute no text; see parseResponsesContentItem).
+type responsesInputMessage struct {
+	Role string
+	Text string
+}
+
+// responsesInputAnalysis is placeholder metadata synthesized for a single
+// non-text input item, echoed back on the response object's
+// `input_analysis` field so client SDKs exercising multi-modal input can
+// inspect a plausible per-item result without a real vision/audio model
+// behind the simulator.
+type responsesInputAnalysis struct {
+	Type       string `json:"type"`
+	Width      int    `json:"width,omitempty"`
+	Height     int    `json:"height,omitempty"`
+	Transcript string `json:"transcript,omitempty"`
+}
+
+// responsesHandler simulates the OpenAI Responses API (`/v1/responses`): it
+// accepts the unified `input` shape (a string, or an array mixing message
+// objects and typed input_text/input_image/input_audio content items),
+// synthesizes text via `generator` the same way chat completions does, and
+// returns placeholder metadata for any input_image/input_audio items
+// alongside the generated `response` object. Streaming requests reuse the
+// SSE handler's `openai-responses` dialect.
+func responsesHandler(sseHandler *streaming.SSEStreamHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in responsesRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		messages, analysis, err := parseResponsesInput(r.Context(), in.Input)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		streamingMessages := make([]models.ChatCompletionMessage, len(messages))
+		for i, m := range messages {
+			streamingMessages[i] = models.ChatCompletionMessage{Role: m.Role, Content: m.Text}
+		}
+
+		if in.Stream {
+			req := &streaming.ChatCompletionRequest{Model: in.Model, Messages: streamingMessages}
+			_ = sseHandler.StreamCompletion(r.Context(), w, req, streaming.StreamOptions{ChunkSize: 3, Dialect: streaming.DialectOpenAIResponses})
+			return
+		}
+
+		minLen, maxLen := streaming.MapResponseLengthToRangeForMessages("", streamingMessages)
+		text := generator.NewCoherentTextGenerator().GenerateText(r.Context(), minLen, maxLen)
+
+		var inputTexts []string
+		for _, m := range messages {
+			inputTexts = append(inputTexts, m.Text)
+		}
+		inputTokens := utils.EstimateTokens(strings.Join(inputTexts, " "))
+		outputTokens := utils.EstimateTokens(text)
+		id := utils.NewIDGenerator().GenerateID()
+
+		resp := map[string]interface{}{
+			"id":         id,
+			"object":     "response",
+			"created_at": time.Now().Unix(),
+			"status":     "completed",
+			"model":      in.Model,
+			"output": []map[string]interface{}{
+				{
+					"id":     "msg_" + id,
+					"type":   "message",
+					"status": "completed",
+					"role":   "assistant",
+					"content": []map[string]interface{}{
+						{"type": "output_text", "text": text, "annotations": []interface{}{}},
+					},
+				},
+			},
+			"output_text": text,
+			"usage": map[string]interface{}{
+				"input_tokens":  inputTokens,
+				"output_tokens": outputTokens,
+				"total_tokens":  inputTokens + outputTokens,
+			},
+		}
+		if len(analysis) > 0 {
+			resp["input_analysis"] = analysis
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// parseResponsesInput normalizes the Responses API's `input` field, which
+// per the spec may be a bare string (a single user turn) or an array mixing
+// full message objects (`{"role":...,"content":[...]}`) and bare content
+// items (collected into one implicit user turn). Each input_image/
+// input_audio item encountered also yields a placeholder
+// responsesInputAnalysis entry.
+func parseResponsesInput(ctx context.Context, raw json.RawMessage) ([]responsesInputMessage, []responsesInputAnalysis, error) {
+	if len(raw) == 0 {
+		return nil, nil, fmt.Errorf("input is required")
+	}
+
+	var asString string
+	if err := json.Unmarshal(raw, &asString); err == nil {
+		return []responsesInputMessage{{Role: "user", Text: asString}}, nil, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return nil, nil, fmt.Errorf("input must be a string or an array: %w", err)
+	}
+
+	var messages []responsesInputMessage
+	var analysis []responsesInputAnalysis
+	var implicitUser strings.Builder
+	for _, item := range items {
+		var withRole struct {
+			Role    string          `json:"role"`
+			Content json.RawMessage `json:"content"`
+		}
+		if err := json.Unmarshal(item, &withRole); err == nil && withRole.Role != "" {
+			text, a := parseResponsesContent(ctx, withRole.Content)
+			messages = append(messages, responsesInputMessage{Role: withRole.Role, Text: text})
+			analysis = append(analysis, a...)
+			continue
+		}
+
+		// No role: a bare content item belonging to one implicit user turn.
+		text, a := parseResponsesContentItem(ctx, item)
+		if implicitUser.Len() > 0 && text != "" {
+			implicitUser.WriteString(" ")
+		}
+		implicitUser.WriteString(text)
+		analysis = append(analysis, a...)
+	}
+	if implicitUser.Len() > 0 {
+		messages = append(messages, responsesInputMessage{Role: "user", Text: implicitUser.String()})
+	}
+	return messages, analysis, nil
+}
+
+// parseResponsesContent normalizes a message's `content`, which may be a
+// bare string or an array of typed content items.
+func parseResponsesContent(ctx context.Context, raw json.RawMessage) (string, []responsesInputAnalysis) {
+	if len(raw) == 0 {
+		return "", nil
+	}
+
+	var asString string
+	if err := json.Unmarshal(raw, &asString); err == nil {
+		return asString, nil
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(raw, &items); err != nil {
+		return "", nil
+	}
+
+	var text strings.Builder
+	var analysis []responsesInputAnalysis
+	for _, item := range items {
+		itemText, a := parseResponsesContentItem(ctx, item)
+		if text.Len() > 0 && itemText != "" {
+			text.WriteString(" ")
+		}
+		text.WriteString(itemText)
+		analysis = append(analysis, a...)
+	}
+	return text.String(), analysis
+}
+
+// parseResponsesContentItem handles a single typed content item: input_text
+// contributes its text verbatim, while input_image and input_audio have no
+// real decoder behind them, so the simulator synthesizes placeholder
+// metadata (image dimensions, a fabricated transcript) instead of text.
+func parseResponsesContentItem(ctx context.Context, raw json.RawMessage) (string, []responsesInputAnalysis) {
+	var item struct {
+		Type     string `json:"type"`
+		Text     string `json:"text"`
+		ImageURL string `json:"image_url"`
+	}
+	if err := json.Unmarshal(raw, &item); err != nil {
+		return "", nil
+	}
+
+	switch item.Type {
+	case "input_text":
+		return item.Text, nil
+	case "input_image":
+		width, height := estimateImageDimensions(item.ImageURL)
+		return "", []responsesInputAnalysis{{Type: item.Type, Width: width, Height: height}}
+	case "input_audio":
+		transcript := generator.NewCoherentTextGenerator().GenerateText(ctx, 10, 40)
+		return "", []responsesInputAnalysis{{Type: item.Type, Transcript: transcript}}
+	default:
+		return "", nil
+	}
+}
+
+// commonImageResolutions lists plausible dimensions to pick from when
+// synthesizing input_image placeholder metadata; real dimensions aren't
+// knowable without a decoder, but a varied, bounded set reads more
+// plausibly to a client than one hardcoded size.
+var commonImageResolutions = [][2]int{
+	{512, 512}, {768, 768}, {1024, 768}, {1024, 1024}, {1920, 1080},
+}
+
+// estimateImageDimensions picks a plausible width/height pair for an
+// input_image item, keyed off ref (its image_url) so the same
+// reference always yields the same placeholder dimensions.
+func estimateImageDimensions(ref string) (int, int) {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(ref))
+	res := commonImageResolutions[int(h.Sum32())%len(commonImageResolutions)]
+	return res[0], res[1]
+}
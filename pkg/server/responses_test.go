@@ -0,0 +1,95 @@
+package server
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestResponsesHandler_StringInputReturnsOutputText(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body := `{"model":"gpt-sim-1","input":"tell me a story"}`
+	resp, err := http.Post(s.URL+"/v1/responses", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "response", out["object"])
+	require.Equal(t, "completed", out["status"])
+	require.NotEmpty(t, out["output_text"])
+	require.NotEmpty(t, out["output"])
+}
+
+func TestResponsesHandler_MultiModalInputYieldsPlaceholderAnalysis(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body := `{
+		"model": "gpt-sim-1",
+		"input": [
+			{"role": "user", "content": [
+				{"type": "input_text", "text": "describe this"},
+				{"type": "input_image", "image_url": "https://example.com/cat.png"},
+				{"type": "input_audio", "audio_data": "base64stub"}
+			]}
+		]
+	}`
+	resp, err := http.Post(s.URL+"/v1/responses", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var out map[string]interface{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+
+	analysis, ok := out["input_analysis"].([]interface{})
+	require.True(t, ok, "expected input_analysis in response, got %v", out)
+	require.Len(t, analysis, 2)
+}
+
+func TestResponsesHandler_StreamEmitsNamedEvents(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body := `{"model":"gpt-sim-1","input":"tell me a story","stream":true}`
+	resp, err := http.Post(s.URL+"/v1/responses", "application/json", strings.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var sawCreated, sawDelta, sawCompleted bool
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case strings.Contains(line, "response.created"):
+			sawCreated = true
+		case strings.Contains(line, "response.output_text.delta"):
+			sawDelta = true
+		case strings.Contains(line, "response.completed"):
+			sawCompleted = true
+		}
+	}
+	require.True(t, sawCreated, "expected a response.created event")
+	require.True(t, sawDelta, "expected at least one response.output_text.delta event")
+	require.True(t, sawCompleted, "expected a response.completed event")
+}
+
+func TestResponsesHandler_MissingInputReturns400(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	resp, err := http.Post(s.URL+"/v1/responses", "application/json", strings.NewReader(`{"model":"gpt-sim-1"}`))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
@@ -2,20 +2,31 @@ package server
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"log"
+	"math/rand"
 	"net/http"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/quantalogic/openai-api-simulator/pkg/generator"
 	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/router"
 	"github.com/quantalogic/openai-api-simulator/pkg/streaming"
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
 	"github.com/quantalogic/openai-api-simulator/pkg/utils"
 )
 
+// defaultMaxClientBatchSize bounds /v1/batch/completions when callers use
+// NewRouterWithStreamDefaults (which predates the batch endpoint and has no
+// way to configure it); NewRouterWithOptions exposes an explicit override.
+const defaultMaxClientBatchSize = 32
+
 // Map incoming request types to streaming request types.
 func toStreamingRequest(in models.ChatCompletionRequest) *streaming.ChatCompletionRequest {
 	req := &streaming.ChatCompletionRequest{
@@ -24,10 +35,18 @@ func toStreamingRequest(in models.ChatCompletionRequest) *streaming.ChatCompleti
 
 	// Convert messages
 	for _, m := range in.Messages {
-		req.Messages = append(req.Messages, models.ChatCompletionMessage{
-			Role:    m.Role,
-			Content: m.Content,
-		})
+		msg := models.ChatCompletionMessage{
+			Role:       m.Role,
+			Content:    m.Content,
+			ToolCallID: m.ToolCallID,
+		}
+		for _, raw := range m.ToolCalls {
+			var call models.ChatCompletionMessageToolCall
+			if err := json.Unmarshal(raw, &call); err == nil {
+				msg.ToolCalls = append(msg.ToolCalls, call)
+			}
+		}
+		req.Messages = append(req.Messages, msg)
 	}
 
 	// Tools: convert models.Tool -> generator.ToolDefinition
@@ -44,6 +63,17 @@ func toStreamingRequest(in models.ChatCompletionRequest) *streaming.ChatCompleti
 	}
 
 	req.ResponseLength = in.ResponseLength
+	req.ResponseFormat = in.ResponseFormat
+	req.ToolChoice = in.ToolChoice
+	if in.ParallelToolCalls != nil {
+		req.ParallelToolCalls = *in.ParallelToolCalls
+	}
+	req.ToolStrategy, req.ToolCallProbability = toolStrategyFromRequest(in)
+	if in.MaxCompletionTokens != nil {
+		req.MaxTokens = *in.MaxCompletionTokens
+	} else if in.MaxTokens != nil {
+		req.MaxTokens = *in.MaxTokens
+	}
 	return req
 }
 
@@ -56,9 +86,64 @@ func NewRouter() http.Handler {
 // defaults when an incoming request does not supply `stream_options`.
 // If smollmEnabled is true, smollm requests will be proxied to smollmUpstreamURL.
 func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultResponseLength string, smollmEnabled bool, smollmUpstreamURL string) http.Handler {
+	return NewRouterWithOptions(defaults, defaultResponseLength, smollmEnabled, smollmUpstreamURL, defaultMaxClientBatchSize, false, "", "")
+}
+
+// NewRouterWithOptions is the full-featured router constructor; maxClientBatchSize
+// bounds how many requests a single POST to /v1/batch/completions may contain.
+// toolsEnabled gates the built-in tool executor toolbox (dir_tree, read_file,
+// http_get, now, calculator): when false, tool calls are always fabricated,
+// matching the simulator's previous behavior. backendsConfigPath, when
+// non-empty, names a YAML ModelsConfig (see generator.LoadModelsConfig)
+// routing streamed chat completions for specific models to a real gRPC
+// backend instead of the built-in simulator; a load failure is logged and
+// the router falls back to simulating every model, the same degrade-instead
+// of fail posture BackendTextGenerator already uses for an unreachable
+// backend. providersConfigPath, when non-empty, names a YAML
+// router.ProvidersConfig mapping model name globs/aliases to real upstream
+// providers (OpenAI, Anthropic, Ollama, Gemini, or a passthrough); a chat
+// completion for a matching model is forwarded and translated through that
+// provider instead of being simulated. The smollm check runs first, so a
+// request for "smollm" while smollmEnabled is proxied to smollmUpstreamURL
+// even when a provider route matches it. As with
+// backendsConfigPath, a load failure is logged and the router falls back to
+// simulating every model.
+func NewRouterWithOptions(defaults streaming.StreamOptions, defaultResponseLength string, smollmEnabled bool, smollmUpstreamURL string, maxClientBatchSize int, toolsEnabled bool, backendsConfigPath string, providersConfigPath string) http.Handler {
 	mux := http.NewServeMux()
 
 	sseHandler := streaming.NewSSEStreamHandlerWithDefaults(defaults)
+	if toolsEnabled {
+		sseHandler.SetToolbox(generator.NewToolbox())
+	}
+	// tokRouter resolves the per-model Tokenizer used for usage accounting on
+	// the non-streaming path below; the streaming path resolves its own copy
+	// through sseHandler. Both read the same backendsConfigPath YAML, so one
+	// `models.yaml` selects a model's backend and its tokenizer together.
+	tokRouter := tokenizer.NewRouter()
+	if backendsConfigPath != "" {
+		if cfg, err := generator.LoadModelsConfig(backendsConfigPath); err != nil {
+			log.Printf("backends config %s: %v; serving every model from the built-in simulator", backendsConfigPath, err)
+		} else {
+			if backendRouter, err := generator.NewBackendRouterFromConfig(cfg); err != nil {
+				log.Printf("backends config %s: %v; serving every model from the built-in simulator", backendsConfigPath, err)
+			} else {
+				sseHandler.SetBackendRouter(backendRouter)
+			}
+			tokRouter = generator.NewTokenizerRouterFromConfig(cfg)
+		}
+	}
+	sseHandler.SetTokenizerRouter(tokRouter)
+
+	var providersCfg *router.ProvidersConfig
+	if providersConfigPath != "" {
+		if cfg, err := router.LoadProvidersConfig(providersConfigPath); err != nil {
+			log.Printf("providers config %s: %v; serving every model from the built-in simulator", providersConfigPath, err)
+		} else {
+			providersCfg = cfg
+		}
+	}
+
+	rateLimiter := streaming.NewRateLimiter()
 
 	// Chat completion handler: register both the OpenAI v1 path and the older
 	// base path that some UIs (like Open Web UI) use. This ensures the
@@ -82,18 +167,40 @@ func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultRespon
 			return
 		}
 
+		// Chaos-testing fault injection: reject the request outright (rate
+		// limit or simulated error code) before doing any other work. The
+		// streaming-specific faults (truncation, malformed chunks, stalls,
+		// invalid finish reasons) are applied later, inside the stream/
+		// non-stream branches below.
+		reqFaults := faultConfigFromRequest(r, in.Simulator)
+		var seed int64
+		if in.Seed != nil {
+			seed = *in.Seed
+		}
+		if rejection, reject := streaming.EvaluateFaults(sseHandler.MergedFaults(reqFaults), rateLimiter, apiKeyFromRequest(r), seed); reject {
+			writeFaultRejection(w, rejection)
+			return
+		}
+
 		// If smollm is enabled and the request is for smollm, proxy to upstream
 		if smollmEnabled && in.Model == "smollm" {
 			proxyToLlamaCpp(w, r, smollmUpstreamURL, bodyBytes)
 			return
 		}
 
+		// If the model matches a configured provider route, forward and
+		// translate through that real upstream instead of simulating it.
+		if route, ok := providersCfg.Resolve(in.Model); ok {
+			serveRoutedChatCompletion(w, r, *route, in)
+			return
+		}
+
 		if in.Stream {
 			parallel := false
 			if in.ParallelToolCalls != nil {
 				parallel = *in.ParallelToolCalls
 			}
-			streamOpts := streaming.StreamOptions{IncludeUsage: false, ChunkSize: 3, ParallelToolCalls: parallel}
+			streamOpts := streaming.StreamOptions{IncludeUsage: false, ChunkSize: 3, ParallelToolCalls: parallel, Faults: reqFaults, Seed: seed}
 			if in.StreamOptions != nil {
 				streamOpts.IncludeUsage = in.StreamOptions.IncludeUsage
 				// map jitter/delay range
@@ -111,82 +218,194 @@ func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultRespon
 			return
 		}
 
-		// Non-streaming: support structured outputs or plain text
-		// Parse response_format for JSON schema
-		var structured string
-		if in.ResponseFormat != nil {
-			// try to treat as map[string]interface{}
-			if rf, ok := in.ResponseFormat.(map[string]interface{}); ok {
-				if rf["type"] == "json_schema" {
-					// try to extract 'json_schema' property
-					if js, ok := rf["json_schema"]; ok {
-						// re-marshal and decode into models.JSONSchema
-						b, _ := json.Marshal(js)
-						var schema models.JSONSchema
-						if err := json.Unmarshal(b, &schema); err == nil {
-							gen := generator.NewToolCallGenerator()
-							if out, err := gen.GenerateStructuredOutput(schema); err == nil {
-								structured = out
-							}
-						}
-					}
+		resp := buildChatCompletion(r.Context(), in, defaultResponseLength, tokRouter.Resolve(in.Model), seed)
+		if reqFaults.InvalidFinishReason != "" && len(resp.Choices) > 0 {
+			resp.Choices[0].FinishReason = reqFaults.InvalidFinishReason
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}
+	mux.HandleFunc("/v1/chat/completions", chatHandler)
+	mux.HandleFunc("/chat/completions", chatHandler)
+
+	// Batched chat completions: accept an array of requests in one POST and
+	// return a parallel array of responses, fanning out across a bounded
+	// worker pool so large batches don't serialize behind one slow request.
+	batchHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var batch []models.ChatCompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&batch); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		if len(batch) > maxClientBatchSize {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{
+				"error": map[string]interface{}{
+					"message": fmt.Sprintf("batch size %d exceeds max_client_batch_size %d", len(batch), maxClientBatchSize),
+					"type":    "invalid_request_error",
+					"code":    "batch_size_exceeded",
+				},
+			})
+			return
+		}
+
+		responses := make([]models.ChatCompletion, len(batch))
+		const maxWorkers = 8
+		sem := make(chan struct{}, maxWorkers)
+		var wg sync.WaitGroup
+		for i, in := range batch {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, in models.ChatCompletionRequest) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				var seed int64
+				if in.Seed != nil {
+					seed = *in.Seed
 				}
-			}
+				responses[i] = buildChatCompletion(r.Context(), in, defaultResponseLength, tokRouter.Resolve(in.Model), seed)
+			}(i, in)
+		}
+		wg.Wait()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(responses)
+	}
+	mux.HandleFunc("/v1/batch/completions", batchHandler)
+
+	// Legacy text completions handler: older SDKs and eval harnesses (LangChain
+	// legacy chains, TGI clients) still target this endpoint.
+	completionsHandler := func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+
+		var in models.CompletionRequest
+		if err := json.NewDecoder(r.Body).Decode(&in); err != nil {
+			http.Error(w, fmt.Sprintf("invalid request: %v", err), http.StatusBadRequest)
+			return
+		}
+
+		prompts := promptsFromRequest(in.Prompt)
+		n := int64(1)
+		if in.N != nil && *in.N > 0 {
+			n = *in.N
+		}
+		stops := stopSequencesFromRequest(in.Stop)
+
+		maxTokens := int64(0)
+		if in.MaxTokens != nil {
+			maxTokens = *in.MaxTokens
 		}
 
-		text := structured
-		if text == "" {
-			// Convert to streaming request so we can use the same message
-			// normalization heuristics for response length (chosen using
-			// `response_length` or inferred from the input messages).
-			sreq := toStreamingRequest(in)
-			// If a default response length is configured and the client did
-			// not specify one, set it so the streaming generator uses the
-			// configured default.
-			if sreq.ResponseLength == "" && defaultResponseLength != "" {
-				sreq.ResponseLength = defaultResponseLength
+		if in.Stream {
+			if in.BestOf != nil && *in.BestOf > n {
+				http.Error(w, "best_of is not supported with stream", http.StatusBadRequest)
+				return
 			}
-			// If a default response length is configured and the client did
-			// not specify one, use that default; otherwise fall back to
-			// inferred length.
-			profile := in.ResponseLength
-			if profile == "" && defaultResponseLength != "" {
-				profile = defaultResponseLength
+			// Expand to n choices per prompt, matching OpenAI's semantics for `n`.
+			expanded := make([]string, 0, len(prompts)*int(n))
+			for _, p := range prompts {
+				for i := int64(0); i < n; i++ {
+					expanded = append(expanded, p)
+				}
 			}
-			minLen, maxLen := streaming.MapResponseLengthToRangeForMessages(profile, sreq.Messages)
-			text = generator.NewCoherentTextGenerator().GenerateText(r.Context(), minLen, maxLen)
+
+			streamOpts := streaming.StreamOptions{ChunkSize: 3}
+			if in.StreamOptions != nil {
+				streamOpts.IncludeUsage = in.StreamOptions.IncludeUsage
+				if in.StreamOptions.DelayMinMs > 0 {
+					streamOpts.DelayMin = time.Duration(in.StreamOptions.DelayMinMs) * time.Millisecond
+				}
+				if in.StreamOptions.DelayMaxMs > 0 {
+					streamOpts.DelayMax = time.Duration(in.StreamOptions.DelayMaxMs) * time.Millisecond
+				}
+				if in.StreamOptions.TokensPerSecond > 0 {
+					streamOpts.TokensPerSecond = in.StreamOptions.TokensPerSecond
+				}
+			}
+			_ = sseHandler.StreamLegacyCompletion(r.Context(), w, in.Model, expanded, maxTokens, in.Echo, in.Suffix, stops, streamOpts)
+			return
 		}
-		id := utils.NewIDGenerator().GenerateID()
-		created := int64(0)
 
-		if in.MaxTokens != nil {
-			_ = *in.MaxTokens
+		textGen := generator.NewCoherentTextGenerator()
+		minLen, maxLen := streaming.MapResponseLengthToRange("")
+		bestOf := n
+		if in.BestOf != nil && *in.BestOf > bestOf {
+			bestOf = *in.BestOf
 		}
 
-		// Build minimal ChatCompletion
-		choice := models.ChatCompletionChoice{
-			Index: 0,
-			Message: models.ChatCompletionMessage{
-				Role:    "assistant",
-				Content: text,
-			},
-			FinishReason: "stop",
+		choices := make([]models.CompletionChoice, 0, len(prompts)*int(n))
+		var completionTokens int64
+		var idx int64
+		for _, prompt := range prompts {
+			candidates := make([]legacyCandidate, 0, bestOf)
+			for i := int64(0); i < bestOf; i++ {
+				text := textGen.GenerateText(r.Context(), minLen, maxLen)
+				finishReason := "stop"
+				if truncated, stopped := utils.TruncateAtStop(text, stops); stopped {
+					text = truncated
+				} else if maxTokens > 0 && utils.EstimateTokens(text) >= maxTokens {
+					finishReason = "length"
+				}
+				candidates = append(candidates, legacyCandidate{text: text, finishReason: finishReason})
+			}
+			// best_of has no real log-probability signal to rank by in a
+			// simulator; preferring the longer candidates approximates OpenAI's
+			// "pick the best" behavior while staying deterministic.
+			sort.Slice(candidates, func(i, j int) bool { return len(candidates[i].text) > len(candidates[j].text) })
+
+			for i := int64(0); i < n; i++ {
+				c := candidates[i]
+				text := c.text
+				if in.Echo {
+					text = prompt + text
+				}
+				if in.Suffix != "" {
+					text += in.Suffix
+				}
+				completionTokens += utils.EstimateTokens(text)
+
+				choice := models.CompletionChoice{
+					Text:         text,
+					Index:        idx,
+					FinishReason: c.finishReason,
+				}
+				if in.Logprobs != nil {
+					choice.Logprobs = legacyLogprobs(text, *in.Logprobs)
+				}
+				choices = append(choices, choice)
+				idx++
+			}
 		}
 
-		resp := models.ChatCompletion{
-			ID:      id,
-			Object:  "chat.completion",
-			Created: created,
+		promptTokens := utils.EstimateTokens(strings.Join(prompts, " "))
+		resp := models.CompletionResponse{
+			ID:      utils.NewIDGenerator().GenerateID(),
+			Object:  "text_completion",
+			Created: time.Now().Unix(),
 			Model:   in.Model,
-			Choices: []models.ChatCompletionChoice{choice},
-			Usage:   models.CompletionUsage{PromptTokens: 0, CompletionTokens: 0, TotalTokens: 0},
+			Choices: choices,
+			Usage: models.CompletionUsage{
+				PromptTokens:     promptTokens,
+				CompletionTokens: completionTokens,
+				TotalTokens:      promptTokens + completionTokens,
+			},
 		}
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(resp)
 	}
-	mux.HandleFunc("/v1/chat/completions", chatHandler)
-	mux.HandleFunc("/chat/completions", chatHandler)
+	mux.HandleFunc("/v1/completions", completionsHandler)
+	mux.HandleFunc("/completions", completionsHandler)
 
 	// Quick model listing endpoint
 	modelsHandler := func(w http.ResponseWriter, r *http.Request) {
@@ -202,6 +421,9 @@ func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultRespon
 		if smollmEnabled {
 			modelsList = append(modelsList, map[string]interface{}{"id": "smollm", "object": "model", "owned_by": "smollm"})
 		}
+		for _, name := range providersCfg.Models() {
+			modelsList = append(modelsList, map[string]interface{}{"id": name, "object": "model", "owned_by": "provider-routed"})
+		}
 
 		w.Header().Set("Content-Type", "application/json")
 		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": modelsList})
@@ -209,6 +431,29 @@ func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultRespon
 	mux.HandleFunc("/v1/models", modelsHandler)
 	mux.HandleFunc("/models", modelsHandler)
 
+	// Audio transcription and speech synthesis endpoints: simulate
+	// Whisper-style uploads and TTS respectively.
+	mux.HandleFunc("/v1/audio/transcriptions", transcriptionsHandler(sseHandler, rateLimiter))
+	mux.HandleFunc("/v1/audio/speech", speechHandler(sseHandler, rateLimiter))
+
+	// Embeddings endpoint: deterministic pseudo-embeddings for testing
+	// vector-store integrations without a real embedding model.
+	mux.HandleFunc("/v1/embeddings", embeddingsHandler(tokRouter, sseHandler, rateLimiter))
+
+	// Image generation endpoints: solid-color placeholder PNGs, returned
+	// either inline (b64_json) or via a URL served by the second handler.
+	mux.HandleFunc("/v1/images/generations", imagesGenerationsHandler(sseHandler, rateLimiter))
+	mux.HandleFunc("/v1/images/generated/", imagesGeneratedHandler)
+
+	// Anthropic-dialect endpoint: lets client libraries written against
+	// Anthropic's Messages API point at this simulator.
+	mux.HandleFunc("/anthropic/v1/messages", anthropicMessagesHandler(sseHandler))
+
+	// Responses API endpoint: lets client libraries that have migrated to
+	// OpenAI's newer unified `input`/`output` surface point at this
+	// simulator alongside the existing chat completions handler.
+	mux.HandleFunc("/v1/responses", responsesHandler(sseHandler))
+
 	// Root/home endpoint and health check. This makes it easier to confirm the
 	// simulator is up when browsing directly or when other services probe
 	// the host root (Open Web UI may hit root for diagnostics).
@@ -242,6 +487,210 @@ func NewRouterWithStreamDefaults(defaults streaming.StreamOptions, defaultRespon
 	})
 }
 
+// buildChatCompletion runs the non-streaming chat completion path: it
+// resolves `response_format` (structured output or refusal) if present,
+// otherwise generates prose text, and assembles the final ChatCompletion.
+// tok counts tokens for `usage` and `max_tokens`/`max_completion_tokens`
+// enforcement; callers resolve it per in.Model via a tokenizer.Router.
+// Shared by the single-request and batched endpoints.
+func buildChatCompletion(ctx context.Context, in models.ChatCompletionRequest, defaultResponseLength string, tok tokenizer.Tokenizer, seed int64) models.ChatCompletion {
+	// Resolve response_format (structured output or refusal), shared with
+	// the streaming path so both honor it identically.
+	var structured, refusal string
+	if in.ResponseFormat != nil {
+		structured, refusal = generator.NewToolCallGenerator().ResolveResponseFormat(in.ResponseFormat)
+	}
+
+	text := structured
+	var toolCalls []models.ChatCompletionMessageToolCall
+	finishReason := "stop"
+	if text == "" && refusal == "" {
+		// Convert to streaming request so we can use the same message
+		// normalization heuristics for response length (chosen using
+		// `response_length` or inferred from the input messages) and tool
+		// definitions.
+		sreq := toStreamingRequest(in)
+
+		if reply, ok := generator.ToolResultReply(sreq.Messages); ok {
+			// A follow-up request supplying role:"tool" messages - the
+			// client executed our prior tool_calls and is reporting their
+			// outputs - takes priority over generating a fresh tool call.
+			text = reply
+		} else if len(sreq.Tools) > 0 && generator.ShouldEmitToolCalls(sreq.ToolStrategy, sreq.ToolCallProbability, generator.NewSeededRand(seed)) {
+			calls, err := generator.NewToolCallGenerator().GenerateToolCallsForChoice(ctx, sreq.Tools, sreq.ToolChoice, generator.StrategyRandom, sreq.ParallelToolCalls, sreq.Messages)
+			if err == nil && len(calls) > 0 {
+				toolCalls = calls
+				finishReason = "tool_calls"
+			}
+		}
+
+		if text == "" && len(toolCalls) == 0 {
+			profile := in.ResponseLength
+			if profile == "" && defaultResponseLength != "" {
+				profile = defaultResponseLength
+			}
+			minLen, maxLen := streaming.MapResponseLengthToRangeForMessages(profile, sreq.Messages)
+			text = generator.NewCoherentTextGenerator().GenerateText(ctx, minLen, maxLen)
+		}
+	}
+
+	// max_tokens/max_completion_tokens only bounds generated prose; a
+	// request that got tool_calls or a response_format refusal instead
+	// isn't cut short by it. max_completion_tokens is OpenAI's newer name
+	// for the same limit and takes precedence when both are set.
+	maxTokens := int64(0)
+	if in.MaxCompletionTokens != nil {
+		maxTokens = *in.MaxCompletionTokens
+	} else if in.MaxTokens != nil {
+		maxTokens = *in.MaxTokens
+	}
+	if len(toolCalls) == 0 && refusal == "" {
+		if truncated, cut := tokenizer.Truncate(tok, text, maxTokens); cut {
+			text = truncated
+			finishReason = "length"
+		}
+	}
+
+	message := models.ChatCompletionMessage{Role: "assistant", Content: text, ToolCalls: toolCalls}
+	if refusal != "" {
+		message.Content = ""
+		message.Refusal = refusal
+	} else if len(toolCalls) == 0 {
+		message.Audio = audioForModalities(in.Modalities, text)
+	}
+	choice := models.ChatCompletionChoice{
+		Index:        0,
+		Message:      message,
+		FinishReason: finishReason,
+	}
+
+	completionTokens := tok.Count(message.Content) + tok.Count(message.Refusal)
+	for _, call := range toolCalls {
+		completionTokens += tok.Count(call.Function.Name + call.Function.Arguments)
+	}
+	promptTokens := tok.Count(promptTokenText(in.Messages, in.Tools))
+
+	return models.ChatCompletion{
+		ID:      utils.NewIDGenerator().GenerateID(),
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   in.Model,
+		Choices: []models.ChatCompletionChoice{choice},
+		Usage: models.CompletionUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}
+}
+
+// promptTokenText concatenates message content with a rendering of the
+// available tool definitions, so prompt token accounting charges for tools
+// the same way a real provider bills them as part of the prompt.
+func promptTokenText(messages []models.ChatCompletionMessageParam, tools []models.Tool) string {
+	parts := make([]string, 0, len(messages)+len(tools))
+	for _, m := range messages {
+		if m.Content != "" {
+			parts = append(parts, m.Content)
+		}
+	}
+	for _, t := range tools {
+		if t.Function != nil {
+			parts = append(parts, t.Function.Name, t.Function.Description, string(t.Function.Parameters))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// promptsFromRequest normalizes the legacy `prompt` field, which per the
+// OpenAI spec may be a single string or an array of strings.
+func promptsFromRequest(prompt interface{}) []string {
+	switch v := prompt.(type) {
+	case string:
+		return []string{v}
+	case []interface{}:
+		prompts := make([]string, 0, len(v))
+		for _, p := range v {
+			if s, ok := p.(string); ok {
+				prompts = append(prompts, s)
+			}
+		}
+		return prompts
+	default:
+		return []string{""}
+	}
+}
+
+// stopSequencesFromRequest normalizes the legacy `stop` field, which per the
+// OpenAI spec may be a single string or an array of up to 4 strings.
+func stopSequencesFromRequest(stop interface{}) []string {
+	switch v := stop.(type) {
+	case string:
+		if v == "" {
+			return nil
+		}
+		return []string{v}
+	case []interface{}:
+		stops := make([]string, 0, len(v))
+		for _, s := range v {
+			if str, ok := s.(string); ok {
+				stops = append(stops, str)
+			}
+		}
+		return stops
+	default:
+		return nil
+	}
+}
+
+// legacyCandidate is one internally-generated completion considered for a
+// `best_of` selection before the top `n` are kept.
+type legacyCandidate struct {
+	text         string
+	finishReason string
+}
+
+// legacyLogprobs synthesizes a plausible `logprobs` block for the legacy
+// completions endpoint: real tokens from utils.TokenizeText, paired with
+// fabricated log probabilities and alternatives drawn from the simulator's
+// word bank, so SDKs that inspect token_logprobs/top_logprobs see a
+// believable shape rather than an empty one.
+func legacyLogprobs(text string, topLogprobs int64) *models.CompletionLogprobs {
+	tokens := utils.TokenizeText(text)
+	if len(tokens) == 0 {
+		return nil
+	}
+	if topLogprobs <= 0 {
+		topLogprobs = 1
+	}
+
+	wb := utils.NewWordBank()
+	vocab := append(append(append([]string{}, wb.Nouns...), wb.Verbs...), wb.Adjectives...)
+
+	lp := &models.CompletionLogprobs{
+		Tokens:        make([]string, len(tokens)),
+		TokenLogprobs: make([]float64, len(tokens)),
+		TopLogprobs:   make([]map[string]float64, len(tokens)),
+		TextOffset:    make([]int64, len(tokens)),
+	}
+
+	var offset int64
+	for i, tok := range tokens {
+		logprob := -(0.01 + rand.Float64()*2.5)
+		lp.Tokens[i] = tok
+		lp.TokenLogprobs[i] = logprob
+		lp.TextOffset[i] = offset
+		offset += int64(len(tok)) + 1 // +1 for the joining space
+
+		alts := map[string]float64{tok: logprob}
+		for len(alts) < int(topLogprobs)+1 && len(alts) < len(vocab) {
+			alts[utils.RandomString(vocab)] = logprob - rand.Float64()*3
+		}
+		lp.TopLogprobs[i] = alts
+	}
+	return lp
+}
+
 // proxyToLlamaCpp proxies requests to the upstream llama.cpp server
 func proxyToLlamaCpp(w http.ResponseWriter, r *http.Request, upstreamURL string, bodyBytes []byte) {
 	// Build the upstream URL
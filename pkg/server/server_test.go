@@ -4,6 +4,8 @@ import (
 	"bufio"
 	"bytes"
 	"encoding/json"
+	"io"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -17,7 +19,7 @@ import (
 
 func TestRouter_UsesStreamDefaults(t *testing.T) {
 	defaults := streaming.StreamOptions{DelayMin: time.Millisecond, DelayMax: 2 * time.Millisecond, TokensPerSecond: 1000}
-	s := httptest.NewServer(NewRouterWithStreamDefaults(defaults))
+	s := httptest.NewServer(NewRouterWithStreamDefaults(defaults, "", false, ""))
 	defer s.Close()
 
 	payload := models.ChatCompletionRequest{
@@ -46,3 +48,327 @@ func TestRouter_UsesStreamDefaults(t *testing.T) {
 	}
 	require.Greater(t, chunkCount, 0)
 }
+
+func TestRouter_BatchCompletions(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	batch := []models.ChatCompletionRequest{
+		{Model: "gpt-sim-1", Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}}},
+		{Model: "gpt-sim-1", Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hello"}}},
+	}
+
+	body, _ := json.Marshal(batch)
+	resp, err := http.Post(s.URL+"/v1/batch/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out []models.ChatCompletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out, 2)
+	for _, c := range out {
+		require.NotEmpty(t, c.Choices[0].Message.Content)
+	}
+}
+
+func TestRouter_BatchCompletions_RejectsOversizedBatch(t *testing.T) {
+	s := httptest.NewServer(NewRouterWithOptions(streaming.StreamOptions{}, "", false, "", 1, false, "", ""))
+	defer s.Close()
+
+	batch := []models.ChatCompletionRequest{
+		{Model: "gpt-sim-1", Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hi"}}},
+		{Model: "gpt-sim-1", Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "Hello"}}},
+	}
+
+	body, _ := json.Marshal(batch)
+	resp, err := http.Post(s.URL+"/v1/batch/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRouter_LegacyCompletions(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := models.CompletionRequest{
+		Model:  "gpt-sim-1",
+		Prompt: []interface{}{"Once upon a time", "In a galaxy far away"},
+		N:      func() *int64 { n := int64(2); return &n }(),
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.CompletionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "text_completion", out.Object)
+	require.Len(t, out.Choices, 4) // 2 prompts * n=2
+	for _, c := range out.Choices {
+		require.NotEmpty(t, c.Text)
+		require.Equal(t, "stop", c.FinishReason)
+	}
+}
+
+func TestRouter_LegacyCompletionsEchoSuffixAndLogprobs(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	topLogprobs := int64(2)
+	payload := models.CompletionRequest{
+		Model:    "gpt-sim-1",
+		Prompt:   "Once upon a time",
+		Echo:     true,
+		Suffix:   "THE END",
+		Logprobs: &topLogprobs,
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.CompletionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out.Choices, 1)
+
+	choice := out.Choices[0]
+	require.True(t, strings.HasPrefix(choice.Text, "Once upon a time"))
+	require.True(t, strings.HasSuffix(choice.Text, "THE END"))
+
+	require.NotNil(t, choice.Logprobs)
+	require.NotEmpty(t, choice.Logprobs.Tokens)
+	require.Len(t, choice.Logprobs.TokenLogprobs, len(choice.Logprobs.Tokens))
+	require.Len(t, choice.Logprobs.TextOffset, len(choice.Logprobs.Tokens))
+	for _, alts := range choice.Logprobs.TopLogprobs {
+		require.GreaterOrEqual(t, len(alts), int(topLogprobs))
+	}
+}
+
+func TestRouter_LegacyCompletionsBestOf(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	n := int64(1)
+	bestOf := int64(3)
+	payload := models.CompletionRequest{
+		Model:  "gpt-sim-1",
+		Prompt: "Once upon a time",
+		N:      &n,
+		BestOf: &bestOf,
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.CompletionResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out.Choices, 1)
+	require.NotEmpty(t, out.Choices[0].Text)
+}
+
+func TestRouter_LegacyCompletionsBestOfRejectedWithStream(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	bestOf := int64(3)
+	payload := models.CompletionRequest{
+		Model:  "gpt-sim-1",
+		Prompt: "Once upon a time",
+		Stream: true,
+		BestOf: &bestOf,
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestRouter_ChatCompletionAudioModality(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := models.ChatCompletionRequest{
+		Model:      "gpt-sim-1",
+		Messages:   []models.ChatCompletionMessageParam{{Role: "user", Content: "Hello"}},
+		Modalities: []string{"audio"},
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/v1/chat/completions", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.ChatCompletion
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.NotNil(t, out.Choices[0].Message.Audio)
+	require.NotEmpty(t, out.Choices[0].Message.Audio.Data)
+	require.Equal(t, out.Choices[0].Message.Content, out.Choices[0].Message.Audio.Transcript)
+}
+
+func TestRouter_AudioTranscriptions(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("file", "speech.wav")
+	require.NoError(t, err)
+	_, err = part.Write(make([]byte, 32000)) // ~1s of 16kHz 16-bit mono silence
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteField("model", "whisper-sim-1"))
+	require.NoError(t, writer.Close())
+
+	req, err := http.NewRequest(http.MethodPost, s.URL+"/v1/audio/transcriptions", &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out struct {
+		Text string `json:"text"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.NotEmpty(t, out.Text)
+}
+
+func TestRouter_Embeddings(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"model": "text-embedding-3-small",
+		"input": "hello world",
+	})
+	resp, err := http.Post(s.URL+"/v1/embeddings", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.EmbeddingResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "list", out.Object)
+	require.Len(t, out.Data, 1)
+	vec, ok := out.Data[0].Embedding.([]interface{})
+	require.True(t, ok)
+	require.Len(t, vec, 1536)
+	require.Greater(t, out.Usage.PromptTokens, int64(0))
+}
+
+func TestRouter_EmbeddingsAreDeterministicForSameInput(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{"model": "text-embedding-3-small", "input": "stable vector"})
+
+	post := func() models.EmbeddingResponse {
+		resp, err := http.Post(s.URL+"/v1/embeddings", "application/json", bytes.NewReader(body))
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		var out models.EmbeddingResponse
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+		return out
+	}
+
+	first := post()
+	second := post()
+	require.Equal(t, first.Data[0].Embedding, second.Data[0].Embedding)
+}
+
+func TestRouter_AudioSpeech(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"model":           "tts-sim-1",
+		"input":           "Hello from the simulator",
+		"response_format": "wav",
+	})
+	resp, err := http.Post(s.URL+"/v1/audio/speech", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	require.Equal(t, "audio/wav", resp.Header.Get("Content-Type"))
+	data, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NotEmpty(t, data)
+	require.Equal(t, "RIFF", string(data[:4]))
+}
+
+func TestRouter_ImagesGenerations(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"prompt": "a red apple on a table",
+		"size":   "64x64",
+	})
+	resp, err := http.Post(s.URL+"/v1/images/generations", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.ImageGenerationResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out.Data, 1)
+	require.NotEmpty(t, out.Data[0].URL)
+
+	imgResp, err := http.Get(s.URL + out.Data[0].URL)
+	require.NoError(t, err)
+	defer imgResp.Body.Close()
+	require.Equal(t, "image/png", imgResp.Header.Get("Content-Type"))
+	png, err := io.ReadAll(imgResp.Body)
+	require.NoError(t, err)
+	require.Equal(t, "\x89PNG", string(png[:4]))
+}
+
+func TestRouter_ImagesGenerationsB64JSON(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"prompt":          "a blue sky",
+		"response_format": "b64_json",
+	})
+	resp, err := http.Post(s.URL+"/v1/images/generations", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out models.ImageGenerationResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Len(t, out.Data, 1)
+	require.NotEmpty(t, out.Data[0].B64JSON)
+	require.Empty(t, out.Data[0].URL)
+}
+
+func TestRouter_AnthropicMessages(t *testing.T) {
+	s := httptest.NewServer(NewRouter())
+	defer s.Close()
+
+	payload := anthropicMessagesRequest{
+		Model:     "claude-sim-1",
+		Messages:  []anthropicMessage{{Role: "user", Content: "Hello"}},
+		MaxTokens: 256,
+	}
+
+	body, _ := json.Marshal(payload)
+	resp, err := http.Post(s.URL+"/anthropic/v1/messages", "application/json", bytes.NewReader(body))
+	require.NoError(t, err)
+	defer resp.Body.Close()
+
+	var out struct {
+		Type    string `json:"type"`
+		Content []struct {
+			Text string `json:"text"`
+		} `json:"content"`
+	}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&out))
+	require.Equal(t, "message", out.Type)
+	require.NotEmpty(t, out.Content[0].Text)
+}
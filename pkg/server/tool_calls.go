@@ -0,0 +1,19 @@
+package server
+
+import (
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// toolStrategyFromRequest resolves the "always|never|probability" strategy
+// and probability a request opts into via its `simulator` body field,
+// defaulting to "always" (the simulator's historical behavior: emit
+// tool_calls whenever Tools is non-empty) when unset.
+func toolStrategyFromRequest(in models.ChatCompletionRequest) (strategy string, probability float64) {
+	if in.Simulator == nil {
+		return "always", 0
+	}
+	if in.Simulator.ToolStrategy == "" {
+		return "always", in.Simulator.ToolCallProbability
+	}
+	return in.Simulator.ToolStrategy, in.Simulator.ToolCallProbability
+}
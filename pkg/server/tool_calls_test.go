@@ -0,0 +1,101 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
+	"github.com/stretchr/testify/require"
+)
+
+func toolDefinitionRequest(simulator *models.SimulatorConfig) models.ChatCompletionRequest {
+	return models.ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "What's the weather in Paris?"}},
+		Tools: []models.Tool{{
+			Type: "function",
+			Function: &models.FunctionDefinition{
+				Name:       "get_weather",
+				Parameters: json.RawMessage(`{"type":"object","properties":{"city":{"type":"string"}},"required":["city"]}`),
+			},
+		}},
+		Simulator: simulator,
+	}
+}
+
+func TestBuildChatCompletion_EmitsToolCallsWhenToolsProvided(t *testing.T) {
+	resp := buildChatCompletion(context.Background(), toolDefinitionRequest(nil), "", tokenizer.NewBPETokenizer(), 0)
+	require.Equal(t, "tool_calls", resp.Choices[0].FinishReason)
+	require.NotEmpty(t, resp.Choices[0].Message.ToolCalls)
+	require.Equal(t, "get_weather", resp.Choices[0].Message.ToolCalls[0].Function.Name)
+}
+
+func TestBuildChatCompletion_NeverStrategySuppressesToolCalls(t *testing.T) {
+	in := toolDefinitionRequest(&models.SimulatorConfig{ToolStrategy: "never"})
+	resp := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), 0)
+	require.Empty(t, resp.Choices[0].Message.ToolCalls)
+	require.Equal(t, "stop", resp.Choices[0].FinishReason)
+}
+
+func TestBuildChatCompletion_ReportsNonZeroPromptAndCompletionTokenUsage(t *testing.T) {
+	in := models.ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{{Role: "user", Content: "What's the weather in Paris?"}},
+	}
+
+	resp := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), 0)
+	require.Greater(t, resp.Usage.PromptTokens, int64(0))
+	require.Greater(t, resp.Usage.CompletionTokens, int64(0))
+	require.Equal(t, resp.Usage.PromptTokens+resp.Usage.CompletionTokens, resp.Usage.TotalTokens)
+}
+
+func TestBuildChatCompletion_MaxTokensTruncatesAndSetsLengthFinishReason(t *testing.T) {
+	maxTokens := int64(2)
+	in := models.ChatCompletionRequest{
+		Model:          "gpt-sim-1",
+		Messages:       []models.ChatCompletionMessageParam{{Role: "user", Content: "Tell me a long story"}},
+		ResponseLength: "long",
+		MaxTokens:      &maxTokens,
+	}
+
+	resp := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), 0)
+	require.Equal(t, "length", resp.Choices[0].FinishReason)
+	require.LessOrEqual(t, resp.Usage.CompletionTokens, maxTokens)
+}
+
+func TestBuildChatCompletion_GeneratesReplyFromToolResultMessages(t *testing.T) {
+	toolCall, _ := json.Marshal(models.ChatCompletionMessageToolCall{
+		ID:   "call_1",
+		Type: "function",
+		Function: models.ChatCompletionMessageToolCallFunction{
+			Name:      "get_weather",
+			Arguments: `{"city":"Paris"}`,
+		},
+	})
+	in := models.ChatCompletionRequest{
+		Model: "gpt-sim-1",
+		Messages: []models.ChatCompletionMessageParam{
+			{Role: "user", Content: "What's the weather in Paris?"},
+			{Role: "assistant", ToolCalls: []json.RawMessage{toolCall}},
+			{Role: "tool", ToolCallID: "call_1", Content: "72F and sunny"},
+		},
+	}
+
+	resp := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), 0)
+	require.Empty(t, resp.Choices[0].Message.ToolCalls)
+	require.Equal(t, "stop", resp.Choices[0].FinishReason)
+	require.Contains(t, resp.Choices[0].Message.Content, "get_weather returned: 72F and sunny")
+}
+
+func TestBuildChatCompletion_ProbabilityStrategyIsReproducibleForSameSeed(t *testing.T) {
+	in := toolDefinitionRequest(&models.SimulatorConfig{ToolStrategy: "probability", ToolCallProbability: 0.5})
+
+	const seed = int64(42)
+	first := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), seed)
+	for i := 0; i < 5; i++ {
+		again := buildChatCompletion(context.Background(), in, "", tokenizer.NewBPETokenizer(), seed)
+		require.Equal(t, len(first.Choices[0].Message.ToolCalls) > 0, len(again.Choices[0].Message.ToolCalls) > 0)
+	}
+}
@@ -0,0 +1,150 @@
+package streaming
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+)
+
+// frameBatcher coalesces consecutive sendChunk deltas into a single SSE
+// frame while the pending frame's marshaled JSON stays under
+// StreamOptions.MaxFrameBytes, flushing early when StreamOptions.BatchWindow
+// has elapsed or the next delta would overflow the budget. It models the
+// batching a gateway or proxy in front of a real provider often applies
+// under load, cutting per-chunk JSON/marshal overhead. newFrameBatcher
+// returns nil when MaxFrameBytes is 0, so sendChunk falls back to today's
+// one-delta-per-frame behavior.
+type frameBatcher struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	opts    StreamOptions
+
+	pending      *models.ChatCompletionChunk
+	pendingSince time.Time
+}
+
+// newFrameBatcher returns a frameBatcher for opts, or nil if
+// opts.MaxFrameBytes is not set, in which case callers should send each
+// chunk directly.
+func newFrameBatcher(w http.ResponseWriter, flusher http.Flusher, opts StreamOptions) *frameBatcher {
+	if opts.MaxFrameBytes <= 0 {
+		return nil
+	}
+	return &frameBatcher{w: w, flusher: flusher, opts: opts}
+}
+
+// add folds choice (and usage, if any) into the pending frame, merging
+// Delta.Content and per-index tool-call argument fragments into whatever is
+// already buffered for the same choice index. It flushes the pending frame
+// first if BatchWindow has already elapsed, and flushes (then starts a new
+// pending frame with choice alone) if merging choice in would overflow
+// MaxFrameBytes.
+func (b *frameBatcher) add(completionID string, created int64, model string, choice models.ChatCompletionChunkChoice, usage *models.CompletionUsage) {
+	if b.pending != nil && b.opts.BatchWindow > 0 && time.Since(b.pendingSince) >= b.opts.BatchWindow {
+		b.flush()
+	}
+
+	if b.pending == nil {
+		b.openPending(completionID, created, model, choice, usage)
+		return
+	}
+
+	candidate := *b.pending
+	candidate.Choices = mergeChunkChoice(b.pending.Choices, choice)
+	if usage != nil {
+		candidate.Usage = usage
+	}
+
+	data, err := json.Marshal(candidate)
+	if err != nil || len(data) > b.opts.MaxFrameBytes {
+		b.flush()
+		b.openPending(completionID, created, model, choice, usage)
+		return
+	}
+	b.pending = &candidate
+}
+
+// openPending starts a fresh pending frame containing only choice, used
+// both for the first delta of a stream and right after a flush forced by an
+// overflowing merge.
+func (b *frameBatcher) openPending(completionID string, created int64, model string, choice models.ChatCompletionChunkChoice, usage *models.CompletionUsage) {
+	b.pending = &models.ChatCompletionChunk{
+		ID:      completionID,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []models.ChatCompletionChunkChoice{choice},
+		Usage:   usage,
+	}
+	b.pendingSince = time.Now()
+}
+
+// flush writes the pending frame, if any, and clears it.
+func (b *frameBatcher) flush() {
+	if b.pending == nil {
+		return
+	}
+	data, _ := json.Marshal(b.pending)
+	fmt.Fprintf(b.w, "data: %s\n\n", string(data))
+	b.flusher.Flush()
+	b.pending = nil
+}
+
+// mergeChunkChoice folds choice into existing, matching it against an
+// existing entry with the same Index (appending a new entry if none
+// matches). Content is concatenated; ToolCalls fragments are merged by
+// their own Index the same way, so a multi-chunk tool call argument stays
+// one growing string rather than one entry per chunk. A non-nil
+// FinishReason always overwrites.
+func mergeChunkChoice(existing []models.ChatCompletionChunkChoice, choice models.ChatCompletionChunkChoice) []models.ChatCompletionChunkChoice {
+	for i := range existing {
+		if existing[i].Index != choice.Index {
+			continue
+		}
+		merged := existing[i]
+		merged.Delta.Content += choice.Delta.Content
+		if choice.Delta.Role != "" {
+			merged.Delta.Role = choice.Delta.Role
+		}
+		merged.Delta.ToolCalls = mergeToolCallFragments(merged.Delta.ToolCalls, choice.Delta.ToolCalls)
+		if choice.FinishReason != nil {
+			merged.FinishReason = choice.FinishReason
+		}
+		existing[i] = merged
+		return existing
+	}
+	return append(existing, choice)
+}
+
+// mergeToolCallFragments folds incoming tool-call delta fragments into
+// existing ones, matching by Index and concatenating Arguments so a tool
+// call's arguments accumulate into one string across batched chunks.
+func mergeToolCallFragments(existing, incoming []models.ChatCompletionChunkToolCall) []models.ChatCompletionChunkToolCall {
+	for _, in := range incoming {
+		matched := false
+		for i := range existing {
+			if existing[i].Index != in.Index {
+				continue
+			}
+			existing[i].Function.Arguments += in.Function.Arguments
+			if in.ID != "" {
+				existing[i].ID = in.ID
+			}
+			if in.Type != "" {
+				existing[i].Type = in.Type
+			}
+			if in.Function.Name != "" {
+				existing[i].Function.Name = in.Function.Name
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			existing = append(existing, in)
+		}
+	}
+	return existing
+}
@@ -0,0 +1,123 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+const batchingTestText = "one two three four five six seven eight nine ten eleven twelve thirteen fourteen fifteen"
+
+func TestStreamTextChunks_MaxFrameBytesCoalescesChunksIntoFewerFrames(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	fwUnbatched := &fakeFlusher{}
+	ok := handler.streamTextChunks(context.Background(), fwUnbatched, fwUnbatched, "chatcmpl-1", 0, "gpt-sim-1",
+		batchingTestText, StreamOptions{ChunkSize: 1}, newStreamRand(1), nil, nil)
+	require.True(t, ok)
+
+	fwBatched := &fakeFlusher{}
+	opts := StreamOptions{ChunkSize: 1, MaxFrameBytes: 4096}
+	batcher := newFrameBatcher(fwBatched, fwBatched, opts)
+	ok = handler.streamTextChunks(context.Background(), fwBatched, fwBatched, "chatcmpl-1", 0, "gpt-sim-1",
+		batchingTestText, opts, newStreamRand(1), nil, batcher)
+	require.True(t, ok)
+	batcher.flush()
+
+	unbatchedFrames := countDataFrames(fwUnbatched.String())
+	batchedFrames := countDataFrames(fwBatched.String())
+	require.Less(t, batchedFrames, unbatchedFrames)
+
+	// Batching changes framing, not content: reassembling the batched
+	// frames' deltas must reproduce the same text as the unbatched stream.
+	require.Equal(t, reassembleContent(t, fwUnbatched.String()), reassembleContent(t, fwBatched.String()))
+}
+
+func TestStreamTextChunks_MaxFrameBytesOverflowFlushesEarly(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	fwUnbatched := &fakeFlusher{}
+	ok := handler.streamTextChunks(context.Background(), fwUnbatched, fwUnbatched, "chatcmpl-1", 0, "gpt-sim-1",
+		batchingTestText, StreamOptions{ChunkSize: 1}, newStreamRand(1), nil, nil)
+	require.True(t, ok)
+
+	// A budget too small to hold even two chunks forces a flush after every
+	// delta, matching the unbatched frame count.
+	fwTiny := &fakeFlusher{}
+	opts := StreamOptions{ChunkSize: 1, MaxFrameBytes: 1}
+	batcher := newFrameBatcher(fwTiny, fwTiny, opts)
+	ok = handler.streamTextChunks(context.Background(), fwTiny, fwTiny, "chatcmpl-1", 0, "gpt-sim-1",
+		batchingTestText, opts, newStreamRand(1), nil, batcher)
+	require.True(t, ok)
+	batcher.flush()
+
+	require.Equal(t, countDataFrames(fwUnbatched.String()), countDataFrames(fwTiny.String()))
+}
+
+func TestStreamToolCallChunks_MaxFrameBytesMergesArgumentFragments(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+
+	opts := StreamOptions{MaxFrameBytes: 4096}
+	batcher := newFrameBatcher(fw, fw, opts)
+	ok := handler.streamToolCallChunks(context.Background(), fw, fw, "chatcmpl-1", 0, "gpt-sim-1",
+		twoToolCalls(), opts, newStreamRand(1), nil, batcher)
+	require.True(t, ok)
+	batcher.flush()
+
+	var lastChunk models.ChatCompletionChunk
+	for _, line := range strings.Split(fw.String(), "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &lastChunk))
+	}
+
+	// Both tool calls' full argument strings must survive the merge, one
+	// per choice index, rather than being fragmented across frames.
+	var gotNow, gotWeather string
+	for _, choice := range lastChunk.Choices {
+		for _, call := range choice.Delta.ToolCalls {
+			switch call.Index {
+			case 0:
+				gotNow += call.Function.Arguments
+			case 1:
+				gotWeather += call.Function.Arguments
+			}
+		}
+	}
+	require.Contains(t, gotNow, `"timezone":"America/New_York"`)
+	require.Contains(t, gotWeather, `"city":"San Francisco"`)
+}
+
+func countDataFrames(out string) int {
+	count := 0
+	for _, line := range strings.Split(out, "\n") {
+		if strings.HasPrefix(line, "data: ") {
+			count++
+		}
+	}
+	return count
+}
+
+func reassembleContent(t *testing.T, out string) string {
+	t.Helper()
+	var content strings.Builder
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			continue
+		}
+		for _, choice := range chunk.Choices {
+			content.WriteString(choice.Delta.Content)
+		}
+	}
+	return content.String()
+}
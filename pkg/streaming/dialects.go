@@ -0,0 +1,389 @@
+package streaming
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// Dialect selects which provider's wire format StreamCompletion emits.
+// The simulator always generates the same underlying text/tool-call
+// content; Dialect only changes how that content is framed on the wire,
+// so client libraries written against a specific provider's streaming
+// protocol can be exercised against this simulator.
+type Dialect string
+
+const (
+	DialectOpenAI          Dialect = "openai"
+	DialectAnthropic       Dialect = "anthropic"
+	DialectCohere          Dialect = "cohere"
+	DialectAzureOpenAI     Dialect = "azure-openai"
+	DialectOpenAIResponses Dialect = "openai-responses"
+)
+
+// contentTypeForDialect returns the Content-Type header each dialect's
+// clients expect: Cohere's chat-stream is newline-delimited JSON rather
+// than true SSE, while the others use text/event-stream.
+func contentTypeForDialect(d Dialect) string {
+	if d == DialectCohere {
+		return "application/x-ndjson"
+	}
+	return "text/event-stream"
+}
+
+// streamAnthropicCompletion emits Anthropic Messages API SSE events
+// (message_start, content_block_start/delta/stop per block, message_delta,
+// message_stop) for the given text and/or tool calls, chunked the same way
+// as the OpenAI dialect. Text is framed as a single text content block;
+// each tool call becomes its own tool_use block whose arguments stream as
+// input_json_delta, mirroring how streamToolCallChunks fragments OpenAI
+// tool call arguments.
+func (h *SSEStreamHandler) streamAnthropicCompletion(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	model string,
+	text string,
+	toolCalls []models.ChatCompletionMessageToolCall,
+	opts StreamOptions,
+	rng *streamRand,
+) {
+	promptTokens := utils.EstimateTokens(text) // best-effort; no separate prompt text is threaded here
+	sendAnthropicEvent(w, flusher, "message_start", map[string]interface{}{
+		"type": "message_start",
+		"message": map[string]interface{}{
+			"id":      completionID,
+			"type":    "message",
+			"role":    "assistant",
+			"model":   model,
+			"content": []interface{}{},
+			"usage":   map[string]interface{}{"input_tokens": promptTokens, "output_tokens": 0},
+		},
+	})
+
+	var completionTokens int64
+	index := 0
+
+	if text != "" {
+		sendAnthropicEvent(w, flusher, "content_block_start", map[string]interface{}{
+			"type":  "content_block_start",
+			"index": index,
+			"content_block": map[string]interface{}{
+				"type": "text",
+				"text": "",
+			},
+		})
+
+		words := strings.Fields(text)
+		for i := 0; i < len(words); i += opts.ChunkSize {
+			end := i + opts.ChunkSize
+			if end > len(words) {
+				end = len(words)
+			}
+			chunkText := strings.Join(words[i:end], " ")
+			if end < len(words) {
+				chunkText += " "
+			}
+			completionTokens += utils.EstimateTokens(chunkText)
+
+			sendAnthropicEvent(w, flusher, "content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": index,
+				"delta": map[string]interface{}{"type": "text_delta", "text": chunkText},
+			})
+			if !sleepForThrottle(ctx, opts, rng, chunkText) {
+				return
+			}
+		}
+
+		sendAnthropicEvent(w, flusher, "content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": index,
+		})
+		index++
+	}
+
+	for _, call := range toolCalls {
+		sendAnthropicEvent(w, flusher, "content_block_start", map[string]interface{}{
+			"type":  "content_block_start",
+			"index": index,
+			"content_block": map[string]interface{}{
+				"type":  "tool_use",
+				"id":    call.ID,
+				"name":  call.Function.Name,
+				"input": map[string]interface{}{},
+			},
+		})
+
+		args := call.Function.Arguments
+		for j := 0; j < len(args); j += 20 {
+			end := j + 20
+			if end > len(args) {
+				end = len(args)
+			}
+			partial := args[j:end]
+			completionTokens += utils.EstimateTokens(partial)
+
+			sendAnthropicEvent(w, flusher, "content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": index,
+				"delta": map[string]interface{}{"type": "input_json_delta", "partial_json": partial},
+			})
+			if !sleepForThrottle(ctx, opts, rng, partial) {
+				return
+			}
+		}
+
+		sendAnthropicEvent(w, flusher, "content_block_stop", map[string]interface{}{
+			"type":  "content_block_stop",
+			"index": index,
+		})
+		index++
+	}
+
+	stopReason := "end_turn"
+	if len(toolCalls) > 0 {
+		stopReason = "tool_use"
+	}
+	sendAnthropicEvent(w, flusher, "message_delta", map[string]interface{}{
+		"type":  "message_delta",
+		"delta": map[string]interface{}{"stop_reason": stopReason},
+		"usage": map[string]interface{}{"output_tokens": completionTokens},
+	})
+	sendAnthropicEvent(w, flusher, "message_stop", map[string]interface{}{"type": "message_stop"})
+}
+
+func sendAnthropicEvent(w http.ResponseWriter, flusher http.Flusher, eventType string, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", eventType, string(data))
+	flusher.Flush()
+}
+
+// streamOpenAIResponsesCompletion emits the OpenAI Responses API's SSE
+// events (response.created, response.output_text.delta, response.completed)
+// for the given text, chunked the same way as the OpenAI chat-completions
+// dialect. Like the Cohere and Azure dialects (and unlike the Anthropic
+// one), it does not model tool calls; reasoning output blocks are not
+// modeled either. Only a single message/output_text block is produced.
+func (h *SSEStreamHandler) streamOpenAIResponsesCompletion(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	text string,
+	opts StreamOptions,
+	rng *streamRand,
+) {
+	sendAnthropicEvent(w, flusher, "response.created", map[string]interface{}{
+		"type":     "response.created",
+		"response": responsesObject(completionID, created, model, "in_progress", ""),
+	})
+
+	var built strings.Builder
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i += opts.ChunkSize {
+		end := i + opts.ChunkSize
+		if end > len(words) {
+			end = len(words)
+		}
+		chunkText := strings.Join(words[i:end], " ")
+		if end < len(words) {
+			chunkText += " "
+		}
+		built.WriteString(chunkText)
+
+		sendAnthropicEvent(w, flusher, "response.output_text.delta", map[string]interface{}{
+			"type":         "response.output_text.delta",
+			"item_id":      "msg_" + completionID,
+			"output_index": 0,
+			"delta":        chunkText,
+		})
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return
+		}
+	}
+
+	sendAnthropicEvent(w, flusher, "response.completed", map[string]interface{}{
+		"type":     "response.completed",
+		"response": responsesObject(completionID, created, model, "completed", built.String()),
+	})
+}
+
+// responsesObject builds the Responses API `response` object for the given
+// status: while "in_progress" no output has been generated yet, and while
+// "completed" output carries the single generated message/output_text
+// block along with the output_text convenience field.
+func responsesObject(id string, created int64, model string, status string, text string) map[string]interface{} {
+	resp := map[string]interface{}{
+		"id":         id,
+		"object":     "response",
+		"created_at": created,
+		"status":     status,
+		"model":      model,
+		"output":     []interface{}{},
+	}
+	if status == "completed" {
+		resp["output"] = []interface{}{
+			map[string]interface{}{
+				"id":     "msg_" + id,
+				"type":   "message",
+				"status": "completed",
+				"role":   "assistant",
+				"content": []interface{}{
+					map[string]interface{}{"type": "output_text", "text": text, "annotations": []interface{}{}},
+				},
+			},
+		}
+		resp["output_text"] = text
+	}
+	return resp
+}
+
+// streamCohereCompletion emits Cohere's line-delimited JSON chat-stream
+// events (stream-start, text-generation, stream-end), accumulating the
+// full response text into stream-end's response.text field.
+func (h *SSEStreamHandler) streamCohereCompletion(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	text string,
+	opts StreamOptions,
+	rng *streamRand,
+) {
+	sendCohereEvent(w, flusher, map[string]interface{}{
+		"event_type":    "stream-start",
+		"generation_id": completionID,
+	})
+
+	var cumulative strings.Builder
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i += opts.ChunkSize {
+		end := i + opts.ChunkSize
+		if end > len(words) {
+			end = len(words)
+		}
+		chunkText := strings.Join(words[i:end], " ")
+		if end < len(words) {
+			chunkText += " "
+		}
+		cumulative.WriteString(chunkText)
+
+		sendCohereEvent(w, flusher, map[string]interface{}{
+			"event_type": "text-generation",
+			"text":       cumulative.String(),
+		})
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return
+		}
+	}
+
+	sendCohereEvent(w, flusher, map[string]interface{}{
+		"event_type": "stream-end",
+		"response": map[string]interface{}{
+			"text":          cumulative.String(),
+			"generation_id": completionID,
+		},
+		"finish_reason": "COMPLETE",
+	})
+}
+
+func sendCohereEvent(w http.ResponseWriter, flusher http.Flusher, payload interface{}) {
+	data, _ := json.Marshal(payload)
+	fmt.Fprintf(w, "%s\n", string(data))
+	flusher.Flush()
+}
+
+// azureChunkChoice mirrors models.ChatCompletionChunkChoice but adds the
+// Azure-specific content_filter_results block Azure OpenAI attaches to
+// every streamed choice.
+type azureChunkChoice struct {
+	models.ChatCompletionChunkChoice
+	ContentFilterResults azureContentFilterResults `json:"content_filter_results"`
+}
+
+type azureContentFilterResults struct {
+	Hate     azureFilterCategory `json:"hate"`
+	SelfHarm azureFilterCategory `json:"self_harm"`
+	Sexual   azureFilterCategory `json:"sexual"`
+	Violence azureFilterCategory `json:"violence"`
+}
+
+type azureFilterCategory struct {
+	Filtered bool   `json:"filtered"`
+	Severity string `json:"severity"`
+}
+
+// azureChatCompletionChunk is the Azure-flavored streaming chunk shape:
+// identical to models.ChatCompletionChunk except each choice carries a
+// content_filter_results block.
+type azureChatCompletionChunk struct {
+	ID      string                  `json:"id"`
+	Object  string                  `json:"object"`
+	Created int64                   `json:"created"`
+	Model   string                  `json:"model"`
+	Choices []azureChunkChoice      `json:"choices"`
+	Usage   *models.CompletionUsage `json:"usage,omitempty"`
+}
+
+// streamAzureTextChunks mirrors streamTextChunks but wraps each chunk in
+// the Azure chunk shape and sets the apim-request-id header Azure OpenAI
+// deployments add to every response.
+func (h *SSEStreamHandler) streamAzureTextChunks(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	text string,
+	opts StreamOptions,
+	rng *streamRand,
+) {
+	w.Header().Set("apim-request-id", completionID)
+
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i += opts.ChunkSize {
+		end := i + opts.ChunkSize
+		if end > len(words) {
+			end = len(words)
+		}
+		chunkText := strings.Join(words[i:end], " ")
+		if end < len(words) {
+			chunkText += " "
+		}
+		sendAzureChunk(w, flusher, completionID, created, model, azureChunkChoice{
+			ChatCompletionChunkChoice: models.ChatCompletionChunkChoice{
+				Index: 0,
+				Delta: models.ChatCompletionChunkChoiceDelta{
+					Role:    "assistant",
+					Content: chunkText,
+				},
+			},
+		})
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return
+		}
+	}
+}
+
+func sendAzureChunk(w http.ResponseWriter, flusher http.Flusher, completionID string, created int64, model string, choice azureChunkChoice) {
+	chunk := azureChatCompletionChunk{
+		ID:      completionID,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []azureChunkChoice{choice},
+	}
+	data, _ := json.Marshal(chunk)
+	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	flusher.Flush()
+}
@@ -0,0 +1,198 @@
+package streaming
+
+import (
+	"net/http"
+	"sync"
+	"time"
+)
+
+// FaultConfig describes failure modes to inject into a chat completion, so
+// SDK authors can reproduce production failure modes deterministically
+// (via StreamOptions.Seed) instead of waiting for them to happen against a
+// real provider. Each field is independent and inert at its zero value.
+type FaultConfig struct {
+	// ErrorRate, in [0,1], is the probability a request is rejected
+	// outright with one of ErrorCodes instead of being served at all.
+	ErrorRate  float64
+	ErrorCodes []int
+
+	// TruncateAfterTokens, when >0, stops a streaming response after this
+	// many content chunks, leaving out the finish_reason chunk and the
+	// terminating "[DONE]" — simulating a connection dropped mid-stream.
+	TruncateAfterTokens int
+
+	// MalformedJSONRate, in [0,1], is the probability any single content
+	// chunk's SSE data frame is corrupted (truncated mid-JSON) before
+	// being written, instead of sent well-formed.
+	MalformedJSONRate float64
+
+	// StallAfterTokens/StallDuration: once this many content chunks have
+	// been sent, pause for StallDuration before sending the next one,
+	// simulating a stalled connection. Ignored when either is zero.
+	StallAfterTokens int
+	StallDuration    time.Duration
+
+	// InvalidFinishReason, when non-empty, replaces the completion's
+	// normal finish_reason ("stop", "tool_calls", ...) with this value,
+	// exercising a client's handling of an unrecognized finish reason.
+	InvalidFinishReason string
+
+	// RateLimitPerMinute/RateLimitBurst, when RateLimitPerMinute > 0,
+	// enforce a token-bucket rate limit per API key (see RateLimiter)
+	// before serving the request. RateLimitBurst <= 0 defaults to
+	// RateLimitPerMinute.
+	RateLimitPerMinute int
+	RateLimitBurst     int
+}
+
+// mergeFaults fills any zero-value field of opts from defaults, the same
+// field-by-field precedence StreamCompletion already applies to jitter and
+// token-rate options. FaultConfig isn't comparable as a whole (ErrorCodes
+// is a slice), so this can't reuse the `opts.X == (T{})` one-liner those
+// fields use.
+func mergeFaults(opts, defaults FaultConfig) FaultConfig {
+	if opts.ErrorRate == 0 {
+		opts.ErrorRate = defaults.ErrorRate
+	}
+	if len(opts.ErrorCodes) == 0 {
+		opts.ErrorCodes = defaults.ErrorCodes
+	}
+	if opts.TruncateAfterTokens == 0 {
+		opts.TruncateAfterTokens = defaults.TruncateAfterTokens
+	}
+	if opts.MalformedJSONRate == 0 {
+		opts.MalformedJSONRate = defaults.MalformedJSONRate
+	}
+	if opts.StallAfterTokens == 0 {
+		opts.StallAfterTokens = defaults.StallAfterTokens
+	}
+	if opts.StallDuration == 0 {
+		opts.StallDuration = defaults.StallDuration
+	}
+	if opts.InvalidFinishReason == "" {
+		opts.InvalidFinishReason = defaults.InvalidFinishReason
+	}
+	if opts.RateLimitPerMinute == 0 {
+		opts.RateLimitPerMinute = defaults.RateLimitPerMinute
+	}
+	if opts.RateLimitBurst == 0 {
+		opts.RateLimitBurst = defaults.RateLimitBurst
+	}
+	return opts
+}
+
+// RateLimiter enforces a token bucket per key (typically an API key),
+// shared across requests for as long as its owner keeps it (pkg/server
+// creates one per router; SSEStreamHandler does not hold one).
+type RateLimiter struct {
+	mu      sync.Mutex
+	buckets map[string]*tokenBucket
+}
+
+type tokenBucket struct {
+	tokens     float64
+	capacity   float64
+	refillRate float64 // tokens per second
+	last       time.Time
+}
+
+// NewRateLimiter returns an empty RateLimiter.
+func NewRateLimiter() *RateLimiter {
+	return &RateLimiter{buckets: make(map[string]*tokenBucket)}
+}
+
+// Allow reports whether a request for key is within its token-bucket
+// budget, consuming one token if so. ratePerMinute and burst configure
+// key's bucket the first time it's seen; later calls for the same key
+// reuse that bucket and ignore a differing rate/burst, so a per-request
+// fault config can't reset or thrash a key's shared budget.
+func (l *RateLimiter) Allow(key string, ratePerMinute, burst int) bool {
+	if ratePerMinute <= 0 {
+		return true
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	b, ok := l.buckets[key]
+	if !ok {
+		if burst <= 0 {
+			burst = ratePerMinute
+		}
+		b = &tokenBucket{tokens: float64(burst), capacity: float64(burst), refillRate: float64(ratePerMinute) / 60.0, last: time.Now()}
+		l.buckets[key] = b
+	}
+
+	now := time.Now()
+	b.tokens += now.Sub(b.last).Seconds() * b.refillRate
+	b.last = now
+	if b.tokens > b.capacity {
+		b.tokens = b.capacity
+	}
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+// MergedFaults applies h's server-wide default FaultConfig (set via
+// NewSSEStreamHandlerWithDefaults) to request, filling any field request
+// left unset — the same merge StreamCompletion performs internally before
+// streaming. Callers that need to evaluate pre-stream triggers (see
+// EvaluateFaults) before calling StreamCompletion can resolve the fully
+// merged config with this first.
+func (h *SSEStreamHandler) MergedFaults(request FaultConfig) FaultConfig {
+	if h.defaults == nil {
+		return request
+	}
+	return mergeFaults(request, h.defaults.Faults)
+}
+
+// Defaults returns the server-wide default StreamOptions h was constructed
+// with (see NewSSEStreamHandlerWithDefaults), or the zero value if none were
+// set. Non-streaming endpoints that want to honor the same pacing controls
+// (see SimulateLatency) without opening an SSE session read this instead of
+// threading their own copy of the defaults through.
+func (h *SSEStreamHandler) Defaults() StreamOptions {
+	if h.defaults == nil {
+		return StreamOptions{}
+	}
+	return *h.defaults
+}
+
+// FaultRejection is the pre-response rejection EvaluateFaults decided to
+// apply instead of letting a request proceed.
+type FaultRejection struct {
+	StatusCode int
+	RetryAfter time.Duration
+}
+
+// EvaluateFaults applies cfg's pre-response triggers — per-key rate
+// limiting (when limiter is non-nil and cfg.RateLimitPerMinute > 0) and
+// probabilistic error-code injection — and reports whether the caller
+// should reject the request instead of serving it. seed reproduces the
+// ErrorRate draw the same way StreamOptions.Seed reproduces jitter; pass a
+// request's seed here before calling StreamCompletion to keep the
+// rejection decision and the stream itself tied to one reproducible run.
+// Rate limiting is checked first since it depends on shared, cross-request
+// state rather than this draw.
+func EvaluateFaults(cfg FaultConfig, limiter *RateLimiter, apiKey string, seed int64) (FaultRejection, bool) {
+	if limiter != nil && cfg.RateLimitPerMinute > 0 && !limiter.Allow(apiKey, cfg.RateLimitPerMinute, cfg.RateLimitBurst) {
+		retryAfter := time.Minute / time.Duration(cfg.RateLimitPerMinute)
+		return FaultRejection{StatusCode: http.StatusTooManyRequests, RetryAfter: retryAfter}, true
+	}
+
+	if cfg.ErrorRate > 0 && len(cfg.ErrorCodes) > 0 {
+		rng := newStreamRand(seed)
+		if rng.Float64() < cfg.ErrorRate {
+			code := cfg.ErrorCodes[rng.Int63n(int64(len(cfg.ErrorCodes)))]
+			rejection := FaultRejection{StatusCode: code}
+			if code == http.StatusTooManyRequests {
+				rejection.RetryAfter = time.Duration(1+rng.Int63n(5)) * time.Second
+			}
+			return rejection, true
+		}
+	}
+
+	return FaultRejection{}, false
+}
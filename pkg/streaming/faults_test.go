@@ -0,0 +1,93 @@
+package streaming
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimiter_BlocksAfterBurstExhausted(t *testing.T) {
+	limiter := NewRateLimiter()
+	for i := 0; i < 3; i++ {
+		require.True(t, limiter.Allow("key-a", 60, 3))
+	}
+	require.False(t, limiter.Allow("key-a", 60, 3))
+}
+
+func TestRateLimiter_TracksKeysIndependently(t *testing.T) {
+	limiter := NewRateLimiter()
+	require.True(t, limiter.Allow("key-a", 60, 1))
+	require.False(t, limiter.Allow("key-a", 60, 1))
+	require.True(t, limiter.Allow("key-b", 60, 1))
+}
+
+func TestRateLimiter_ZeroRateAlwaysAllows(t *testing.T) {
+	limiter := NewRateLimiter()
+	for i := 0; i < 5; i++ {
+		require.True(t, limiter.Allow("key-a", 0, 0))
+	}
+}
+
+func TestEvaluateFaults_RateLimitTakesPrecedence(t *testing.T) {
+	limiter := NewRateLimiter()
+	cfg := FaultConfig{RateLimitPerMinute: 60, RateLimitBurst: 1, ErrorRate: 0}
+	_, reject := EvaluateFaults(cfg, limiter, "key-a", 1)
+	require.False(t, reject)
+	rejection, reject := EvaluateFaults(cfg, limiter, "key-a", 1)
+	require.True(t, reject)
+	require.Equal(t, http.StatusTooManyRequests, rejection.StatusCode)
+}
+
+func TestEvaluateFaults_ErrorRateOneAlwaysRejects(t *testing.T) {
+	cfg := FaultConfig{ErrorRate: 1, ErrorCodes: []int{503}}
+	rejection, reject := EvaluateFaults(cfg, nil, "key-a", 42)
+	require.True(t, reject)
+	require.Equal(t, 503, rejection.StatusCode)
+}
+
+func TestEvaluateFaults_ErrorRateZeroNeverRejects(t *testing.T) {
+	cfg := FaultConfig{ErrorRate: 0, ErrorCodes: []int{500}}
+	_, reject := EvaluateFaults(cfg, nil, "key-a", 42)
+	require.False(t, reject)
+}
+
+func TestMergeFaults_FillsUnsetFieldsFromDefaults(t *testing.T) {
+	defaults := FaultConfig{ErrorRate: 0.5, ErrorCodes: []int{500}, StallAfterTokens: 10, StallDuration: time.Second}
+	merged := mergeFaults(FaultConfig{ErrorRate: 0.1}, defaults)
+	require.Equal(t, 0.1, merged.ErrorRate)
+	require.Equal(t, []int{500}, merged.ErrorCodes)
+	require.Equal(t, 10, merged.StallAfterTokens)
+	require.Equal(t, time.Second, merged.StallDuration)
+}
+
+func TestStreamCompletion_TruncatesAfterConfiguredTokens(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Tell me a long story"}},
+	}
+	opts := StreamOptions{ChunkSize: 1, Faults: FaultConfig{TruncateAfterTokens: 2}}
+
+	err := handler.StreamCompletion(context.Background(), fw, req, opts)
+	require.NoError(t, err)
+	require.NotContains(t, fw.String(), "[DONE]")
+}
+
+func TestStreamCompletion_InvalidFinishReasonOverridesStop(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hi"}},
+	}
+	opts := StreamOptions{ChunkSize: 3, Faults: FaultConfig{InvalidFinishReason: "content_moderation"}}
+
+	err := handler.StreamCompletion(context.Background(), fw, req, opts)
+	require.NoError(t, err)
+	require.Contains(t, fw.String(), `"finish_reason":"content_moderation"`)
+}
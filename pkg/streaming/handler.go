@@ -7,45 +7,136 @@ import (
 	"math/rand"
 	"net/http"
 	"strings"
-	"sync"
+	"sync/atomic"
 	"time"
 
-	"github.com/openai/openai-api-simulator/pkg/generator"
-	"github.com/openai/openai-api-simulator/pkg/models"
-	"github.com/openai/openai-api-simulator/pkg/utils"
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
+	"github.com/quantalogic/openai-api-simulator/pkg/grammar"
+	"github.com/quantalogic/openai-api-simulator/pkg/models"
+	"github.com/quantalogic/openai-api-simulator/pkg/tokenizer"
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
 )
 
 // StreamOptions configures the streaming session.
 type StreamOptions struct {
-	IncludeUsage      bool
-	ChunkSize         int
+	IncludeUsage bool
+	// UsageInterval, when >0 and IncludeUsage is set, emits an interim
+	// usage chunk carrying a running CompletionUsage every UsageInterval
+	// content chunks, in addition to the final usage chunk IncludeUsage
+	// has always produced. This mirrors how real provider gateways surface
+	// token accounting as the stream progresses, so downstream dashboards
+	// and budget enforcers can react mid-response.
+	UsageInterval int
+	ChunkSize     int
 	// Delay represents an explicit fixed delay after each chunk. Prefer
 	// using DelayMin/Max for variance; Delay is kept for backwards
 	// compatibility with internal calls.
-	Delay             time.Duration
+	Delay time.Duration
 
 	// DelayMin/DelayMax represent a randomized jitter range applied
 	// per-chunk. When set, each chunk will sleep for a uniform random
 	// time between DelayMin and DelayMax.
-	DelayMin          time.Duration
-	DelayMax          time.Duration
+	DelayMin time.Duration
+	DelayMax time.Duration
 
 	// TokensPerSecond, when >0, throttles output to roughly this
 	// token emission rate. It ensures that larger chunks take longer
-	// to send and simulates compute throughput.
+	// to send and simulates compute throughput. It composes with
+	// InterTokenLatency/DelayMin/DelayMax as the slower (max) of the two,
+	// rather than adding on top of it, so the achievable rate is bounded
+	// by whichever constraint is tighter for a given chunk.
 	TokensPerSecond   float64
 	ParallelToolCalls bool
+
+	// ToolCallInterleave selects how multiple tool calls are scheduled when
+	// ParallelToolCalls is set: ToolCallRoundRobin (the default) interleaves
+	// one chunk per tool call per round so a client sees every tool call
+	// progress concurrently, ToolCallWeighted does the same but paces each
+	// tool call by its entry in ToolCallSpeedFactors, and ToolCallSequential
+	// restores the old behavior of streaming one tool call to completion
+	// before starting the next.
+	ToolCallInterleave ToolCallInterleave
+
+	// ToolCallSpeedFactors, used only when ToolCallInterleave is
+	// ToolCallWeighted, maps a tool call's 0-based index (within this
+	// request's tool calls) to a multiplier applied to its per-chunk delay:
+	// >1 simulates a slower tool, <1 a faster one. An index missing from the
+	// map defaults to 1.
+	ToolCallSpeedFactors map[int]float64
+
+	// TimeToFirstToken, when set, is sampled once and applied before the
+	// first delta of a completion, simulating the latency a real
+	// streaming API incurs before its first token.
+	TimeToFirstToken LatencySpec
+
+	// InterTokenLatency, when set (Distribution non-empty), replaces the
+	// legacy DelayMin/DelayMax uniform jitter with a sample drawn per
+	// chunk from the chosen distribution (uniform, normal, lognormal, or
+	// pareto), letting load generators reproduce realistic p50/p95/p99
+	// token-latency shapes.
+	InterTokenLatency LatencySpec
+
+	// Seed, when non-zero, seeds this request's random draws (jitter,
+	// InterTokenLatency, TimeToFirstToken) so its latency sequence is
+	// reproducible across runs.
+	Seed int64
+
+	// Dialect selects the wire format StreamCompletion emits (openai,
+	// anthropic, cohere, azure-openai). Empty defaults to openai.
+	Dialect Dialect
+
+	// MaxFrameBytes, when >0, coalesces consecutive chat-completion chunks
+	// into a single SSE frame as long as the pending frame's marshaled JSON
+	// stays under this byte budget, modeling the batching a gateway or
+	// proxy in front of a real provider often applies under load. The
+	// default (0) sends one delta per frame, today's behavior.
+	MaxFrameBytes int
+
+	// BatchWindow, used only when MaxFrameBytes > 0, forces the pending
+	// frame to flush once it has been open this long, even if more chunks
+	// would still fit under MaxFrameBytes, bounding how stale a batched
+	// frame's latest delta can be.
+	BatchWindow time.Duration
+
+	// Faults configures chaos-testing failure modes (truncated streams,
+	// malformed chunks, stalls, invalid finish reasons) injected into this
+	// completion; see FaultConfig. Probabilistic error-code rejection and
+	// rate limiting, the other two FaultConfig triggers, are evaluated
+	// before streaming begins via EvaluateFaults, not here.
+	Faults FaultConfig
+
+	// Tokenizer counts tokens for usage accounting and TokensPerSecond
+	// pacing (see throttleDelay). StreamCompletion sets this by resolving
+	// req.Model through the handler's tokenizer router; callers that invoke
+	// the per-dialect streaming helpers directly leave it nil, which falls
+	// back to utils.EstimateTokens.
+	Tokenizer tokenizer.Tokenizer
 }
 
 // ChatCompletionRequest models the subset of fields we support.
 type ChatCompletionRequest struct {
-	Model          string                         `json:"model"`
-	Messages       []models.ChatCompletionMessage `json:"messages"`
-	Tools          []generator.ToolDefinition     `json:"tools"`
-	Stream         bool                           `json:"stream"`
-	MaxTokens      int64                          `json:"max_tokens"`
-	Temperature    float64                        `json:"temperature"`
-	ResponseLength string                         `json:"response_length,omitempty"`
+	Model             string                         `json:"model"`
+	Messages          []models.ChatCompletionMessage `json:"messages"`
+	Tools             []generator.ToolDefinition     `json:"tools"`
+	ToolChoice        interface{}                    `json:"tool_choice,omitempty"`
+	ParallelToolCalls bool                           `json:"parallel_tool_calls,omitempty"`
+	Stream            bool                           `json:"stream"`
+	MaxTokens         int64                          `json:"max_tokens"`
+	Temperature       float64                        `json:"temperature"`
+	ResponseLength    string                         `json:"response_length,omitempty"`
+
+	// ResponseFormat, when set to an OpenAI `response_format` value
+	// (`json_schema` or `json_object`), is resolved via ToolCallGenerator.
+	// ResolveResponseFormat and streamed in place of the usual generated
+	// prose; see streamStructuredOutput.
+	ResponseFormat interface{} `json:"response_format,omitempty"`
+
+	// ToolStrategy/ToolCallProbability control whether tool_calls are
+	// emitted at all when Tools is non-empty; see generator.ShouldEmitToolCalls.
+	// ToolStrategy defaults to "always", matching this handler's
+	// historical behavior.
+	ToolStrategy        string  `json:"tool_strategy,omitempty"`
+	ToolCallProbability float64 `json:"tool_call_probability,omitempty"`
 }
 
 // SSEStreamHandler emits Server-Sent Events for chat completions.
@@ -55,6 +146,18 @@ type SSEStreamHandler struct {
 	idGen   *utils.IDGenerator
 	// defaults applied when a client does not set values for options.
 	defaults *StreamOptions
+	// toolbox, when set via SetToolbox, lets streamed tool calls that match
+	// a registered name actually execute instead of only fabricating
+	// arguments.
+	toolbox *generator.Toolbox
+	// backends, when set via SetBackendRouter, resolves textGen per request
+	// model instead of always using the shared textGen, so a model routed
+	// to a real gRPC backend streams that backend's tokens.
+	backends *generator.BackendRouter
+	// tokenizers, when set via SetTokenizerRouter, resolves the Tokenizer
+	// used for usage accounting and TokensPerSecond pacing per request
+	// model; nil resolves every model to the default BPETokenizer.
+	tokenizers *tokenizer.Router
 }
 
 // NewSSEStreamHandler builds a handler backed by default generators.
@@ -78,6 +181,50 @@ func NewSSEStreamHandlerWithDefaults(defaults StreamOptions) *SSEStreamHandler {
 	}
 }
 
+// SetToolbox wires a tool executor registry into the handler. When set, a
+// streamed tool call whose function name matches a registered tool is
+// executed locally, and its result is streamed as a follow-up assistant
+// message — simulating the client/model round trip real agent frameworks
+// perform, so they can be tested end-to-end without a real model.
+func (h *SSEStreamHandler) SetToolbox(tb *generator.Toolbox) {
+	h.toolbox = tb
+}
+
+// SetBackendRouter wires a model → Backend router into the handler. When
+// set, StreamCompletion resolves the text generator for req.Model through
+// the router instead of always using the shared built-in generator, so the
+// same binary can front real inference processes for some models while
+// simulating others.
+func (h *SSEStreamHandler) SetBackendRouter(router *generator.BackendRouter) {
+	h.backends = router
+}
+
+// SetTokenizerRouter wires a model → Tokenizer router into the handler.
+// When set, StreamCompletion resolves req.Model through it for usage
+// accounting and TokensPerSecond pacing instead of always using the default
+// BPETokenizer.
+func (h *SSEStreamHandler) SetTokenizerRouter(router *tokenizer.Router) {
+	h.tokenizers = router
+}
+
+// resolveTextGen returns the TextGenerator that should serve model,
+// dispatching through backends when configured.
+func (h *SSEStreamHandler) resolveTextGen(model string) generator.TextGenerator {
+	if h.backends == nil {
+		return h.textGen
+	}
+	return h.backends.Resolve(model)
+}
+
+// resolveTokenizer returns the Tokenizer that should count tokens for
+// model, dispatching through tokenizers when configured.
+func (h *SSEStreamHandler) resolveTokenizer(model string) tokenizer.Tokenizer {
+	if h.tokenizers == nil {
+		return tokenizer.NewBPETokenizer()
+	}
+	return h.tokenizers.Resolve(model)
+}
+
 // StreamCompletion streams SSE chunks for a completion.
 func (h *SSEStreamHandler) StreamCompletion(
 	ctx context.Context,
@@ -85,11 +232,6 @@ func (h *SSEStreamHandler) StreamCompletion(
 	req *ChatCompletionRequest,
 	opts StreamOptions,
 ) error {
-	w.Header().Set("Content-Type", "text/event-stream")
-	w.Header().Set("Cache-Control", "no-cache")
-	w.Header().Set("Connection", "keep-alive")
-	w.Header().Set("Transfer-Encoding", "chunked")
-
 	flusher, ok := w.(http.Flusher)
 	if !ok {
 		return fmt.Errorf("streaming not supported")
@@ -117,63 +259,180 @@ func (h *SSEStreamHandler) StreamCompletion(
 		if opts.TokensPerSecond == 0 {
 			opts.TokensPerSecond = h.defaults.TokensPerSecond
 		}
+		if opts.UsageInterval == 0 {
+			opts.UsageInterval = h.defaults.UsageInterval
+		}
+		if opts.TimeToFirstToken == (LatencySpec{}) {
+			opts.TimeToFirstToken = h.defaults.TimeToFirstToken
+		}
+		if opts.InterTokenLatency == (LatencySpec{}) {
+			opts.InterTokenLatency = h.defaults.InterTokenLatency
+		}
+		if opts.Seed == 0 {
+			opts.Seed = h.defaults.Seed
+		}
+		if opts.Dialect == "" {
+			opts.Dialect = h.defaults.Dialect
+		}
+		if opts.ToolCallInterleave == "" {
+			opts.ToolCallInterleave = h.defaults.ToolCallInterleave
+		}
+		if opts.ToolCallSpeedFactors == nil {
+			opts.ToolCallSpeedFactors = h.defaults.ToolCallSpeedFactors
+		}
+		if opts.MaxFrameBytes == 0 {
+			opts.MaxFrameBytes = h.defaults.MaxFrameBytes
+		}
+		if opts.BatchWindow == 0 {
+			opts.BatchWindow = h.defaults.BatchWindow
+		}
+		opts.Faults = mergeFaults(opts.Faults, h.defaults.Faults)
+	}
+	if opts.Dialect == "" {
+		opts.Dialect = DialectOpenAI
+	}
+	rng := newStreamRand(opts.Seed)
+	batcher := newFrameBatcher(w, flusher, opts)
+
+	w.Header().Set("Content-Type", contentTypeForDialect(opts.Dialect))
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	if req.ResponseFormat != nil {
+		if handled, err := h.streamStructuredOutput(ctx, w, flusher, completionID, created, req, opts, rng, batcher); handled {
+			return err
+		}
 	}
 
 	var fullText string
 	var toolCalls []models.ChatCompletionMessageToolCall
 	finishReason := "stop"
 
-	if len(req.Tools) > 0 {
-		calls, err := h.toolGen.GenerateToolCalls(ctx, req.Tools, generator.StrategyRandom)
+	if reply, ok := generator.ToolResultReply(req.Messages); ok {
+		// A follow-up request supplying role:"tool" messages - the client
+		// executed our prior tool_calls and is reporting their outputs -
+		// takes priority over generating a fresh tool call.
+		fullText = reply
+	} else if len(req.Tools) > 0 && generator.ShouldEmitToolCalls(req.ToolStrategy, req.ToolCallProbability, rng) {
+		calls, err := h.toolGen.GenerateToolCallsForChoice(ctx, req.Tools, req.ToolChoice, generator.StrategyRandom, req.ParallelToolCalls, req.Messages)
 		if err == nil && len(calls) > 0 {
 			toolCalls = calls
 			finishReason = "tool_calls"
 		}
 	}
 
-	if len(toolCalls) == 0 {
+	if len(toolCalls) == 0 && fullText == "" {
 		// Map friendly response_length to explicit min/max lengths; prefer
 		// response length inferred from the message contents when not set.
 		minLen, maxLen := MapResponseLengthToRangeForMessages(req.ResponseLength, req.Messages)
-		fullText = h.textGen.GenerateText(ctx, minLen, maxLen)
+		fullText = h.resolveTextGen(req.Model).GenerateText(ctx, minLen, maxLen)
+	}
+
+	tok := h.resolveTokenizer(req.Model)
+	opts.Tokenizer = tok
+
+	if len(toolCalls) == 0 && req.MaxTokens > 0 {
+		if truncated, cut := tokenizer.Truncate(tok, fullText, req.MaxTokens); cut {
+			fullText = truncated
+			finishReason = "length"
+		}
+	}
+
+	var tracker *usageTracker
+	if opts.IncludeUsage {
+		promptTokens := tok.Count(promptTokenText(req.Messages, req.Tools))
+		tracker = newUsageTracker(promptTokens, tok)
+	}
+
+	if !applyTimeToFirstToken(ctx, opts, rng) {
+		return ctx.Err()
+	}
+
+	// Non-OpenAI dialects use entirely different wire framing (event names,
+	// line-delimited JSON, extra per-chunk fields). Only the Anthropic
+	// dialect streams tool calls; the others stream the generated text only.
+	switch opts.Dialect {
+	case DialectAnthropic:
+		// Anthropic's protocol ends the stream with message_stop; unlike
+		// OpenAI it has no terminal "[DONE]" sentinel.
+		h.streamAnthropicCompletion(ctx, w, flusher, completionID, req.Model, fullText, toolCalls, opts, rng)
+		return nil
+	case DialectCohere:
+		// Cohere's protocol ends the stream with stream-end; like
+		// Anthropic, there is no "[DONE]" sentinel.
+		h.streamCohereCompletion(ctx, w, flusher, completionID, fullText, opts, rng)
+		return nil
+	case DialectOpenAIResponses:
+		h.streamOpenAIResponsesCompletion(ctx, w, flusher, completionID, created, req.Model, fullText, opts, rng)
+		return nil
+	case DialectAzureOpenAI:
+		h.streamAzureTextChunks(ctx, w, flusher, completionID, created, req.Model, fullText, opts, rng)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		finishReason := "stop"
+		sendAzureChunk(w, flusher, completionID, created, req.Model, azureChunkChoice{
+			ChatCompletionChunkChoice: models.ChatCompletionChunkChoice{
+				Index:        0,
+				FinishReason: &finishReason,
+			},
+		})
+		fmt.Fprint(w, "data: [DONE]\n\n")
+		flusher.Flush()
+		return nil
 	}
 
 	if len(fullText) > 0 {
-		h.streamTextChunks(w, flusher, completionID, created, req.Model, fullText, opts)
+		if !h.streamTextChunks(ctx, w, flusher, completionID, created, req.Model, fullText, opts, rng, tracker, batcher) {
+			return ctx.Err()
+		}
 	}
 
 	if len(toolCalls) > 0 {
-		h.streamToolCallChunks(w, flusher, completionID, created, req.Model, toolCalls, opts)
+		if !h.streamToolCallChunks(ctx, w, flusher, completionID, created, req.Model, toolCalls, opts, rng, tracker, batcher) {
+			return ctx.Err()
+		}
+
+		if h.toolbox != nil {
+			if followUp, ok := h.runToolbox(ctx, toolCalls); ok {
+				// Close out the tool-call turn, then simulate the agent
+				// loop locally: execute the tool and stream a second
+				// assistant message referencing its result, so harnesses
+				// testing multi-turn tool use see a complete round trip
+				// without issuing a second request.
+				toolCallsFinish := "tool_calls"
+				h.sendChunk(batcher, w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
+					Index:        0,
+					Delta:        models.ChatCompletionChunkChoiceDelta{},
+					FinishReason: &toolCallsFinish,
+				}, nil)
+				if !h.streamTextChunks(ctx, w, flusher, completionID, created, req.Model, followUp, opts, rng, tracker, batcher) {
+					return ctx.Err()
+				}
+				finishReason = "stop"
+			}
+		}
 	}
 
-	h.sendChunk(w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
+	if opts.Faults.InvalidFinishReason != "" {
+		finishReason = opts.Faults.InvalidFinishReason
+	}
+	h.sendChunk(batcher, w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
 		Index:        0,
 		Delta:        models.ChatCompletionChunkChoiceDelta{},
 		FinishReason: &finishReason,
 	}, nil)
 
 	if opts.IncludeUsage {
-		promptTokens := utils.EstimateTokens(strings.Join(messageToStrings(req.Messages), " "))
-		completionTokens := utils.EstimateTokens(fullText)
-		usage := &models.CompletionUsage{
-			PromptTokens:     promptTokens,
-			CompletionTokens: completionTokens,
-			TotalTokens:      promptTokens + completionTokens,
-		}
-		chunk := models.ChatCompletionChunk{
-			ID:      completionID,
-			Object:  "chat.completion.chunk",
-			Created: created,
-			Model:   req.Model,
-			Choices: []models.ChatCompletionChunkChoice{{
-				Index: 0,
-				Delta: models.ChatCompletionChunkChoiceDelta{},
-			}},
-			Usage: usage,
-		}
-		data, _ := json.Marshal(chunk)
-		fmt.Fprintf(w, "data: %s\n\n", string(data))
-		flusher.Flush()
+		h.sendChunk(batcher, w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
+			Index: 0,
+			Delta: models.ChatCompletionChunkChoiceDelta{},
+		}, tracker.snapshot())
+	}
+
+	if batcher != nil {
+		batcher.flush()
 	}
 
 	fmt.Fprint(w, "data: [DONE]\n\n")
@@ -182,6 +441,32 @@ func (h *SSEStreamHandler) StreamCompletion(
 	return nil
 }
 
+// runToolbox executes any streamed tool calls that match a registered
+// toolbox entry and renders their results as a short assistant message. It
+// reports ok=false when none of the calls match a registered tool, leaving
+// the caller's existing tool_calls finish behavior untouched.
+func (h *SSEStreamHandler) runToolbox(ctx context.Context, calls []models.ChatCompletionMessageToolCall) (string, bool) {
+	var results []string
+	matched := false
+	for _, call := range calls {
+		executor, ok := h.toolbox.Lookup(call.Function.Name)
+		if !ok {
+			continue
+		}
+		matched = true
+		msg, err := executor.Execute(ctx, call)
+		if err != nil {
+			results = append(results, fmt.Sprintf("%s failed: %v", call.Function.Name, err))
+			continue
+		}
+		results = append(results, fmt.Sprintf("%s returned: %s", call.Function.Name, msg.Content))
+	}
+	if !matched {
+		return "", false
+	}
+	return strings.Join(results, " "), true
+}
+
 // mapResponseLengthToRange maps a friendly profile name to a min/max length
 // used by the generator. Defaults to medium (120-360) when empty or unknown.
 func MapResponseLengthToRange(profile string) (int, int) {
@@ -246,7 +531,261 @@ func MapResponseLengthToRangeForMessages(profile string, messages []models.ChatC
 	return MapResponseLengthToRange("long")
 }
 
+// usageTracker accumulates a running CompletionUsage as chunks are sent,
+// so both interim usage chunks (StreamOptions.UsageInterval) and the final
+// usage chunk (StreamOptions.IncludeUsage) report a consistent token count.
+// promptTokens is fixed at construction; completionTokens and chunkCount are
+// updated atomically, so one tracker is safe to share across goroutines.
+type usageTracker struct {
+	promptTokens     int64
+	completionTokens int64
+	chunkCount       int64
+	tok              tokenizer.Tokenizer
+}
+
+// newUsageTracker creates a tracker seeded with the given prompt token
+// count and zero completion tokens, counting each recorded chunk with tok.
+func newUsageTracker(promptTokens int64, tok tokenizer.Tokenizer) *usageTracker {
+	return &usageTracker{promptTokens: promptTokens, tok: tok}
+}
+
+// record adds text's token count to the running total and increments the
+// chunk count, returning the updated usage snapshot and the new chunk count
+// so callers can decide whether this chunk lands on the UsageInterval
+// cadence.
+func (u *usageTracker) record(text string) (models.CompletionUsage, int64) {
+	completionTokens := atomic.AddInt64(&u.completionTokens, u.tok.Count(text))
+	count := atomic.AddInt64(&u.chunkCount, 1)
+	return models.CompletionUsage{
+		PromptTokens:     u.promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      u.promptTokens + completionTokens,
+	}, count
+}
+
+// snapshot returns the current usage totals without recording a new chunk,
+// used for the final usage chunk sent at the end of a stream.
+func (u *usageTracker) snapshot() *models.CompletionUsage {
+	completionTokens := atomic.LoadInt64(&u.completionTokens)
+	return &models.CompletionUsage{
+		PromptTokens:     u.promptTokens,
+		CompletionTokens: completionTokens,
+		TotalTokens:      u.promptTokens + completionTokens,
+	}
+}
+
+// maybeSendInterimUsage records text against tracker and, if
+// opts.UsageInterval is set and this chunk lands on that cadence, sends an
+// interim usage chunk carrying the running CompletionUsage. It is a no-op
+// when tracker is nil (IncludeUsage was not set).
+func (h *SSEStreamHandler) maybeSendInterimUsage(
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	opts StreamOptions,
+	tracker *usageTracker,
+	text string,
+	batcher *frameBatcher,
+) {
+	if tracker == nil {
+		return
+	}
+	usage, count := tracker.record(text)
+	if opts.UsageInterval > 0 && count%int64(opts.UsageInterval) == 0 {
+		h.sendChunk(batcher, w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
+			Index: 0,
+			Delta: models.ChatCompletionChunkChoiceDelta{},
+		}, &usage)
+	}
+}
+
+// streamStructuredOutput handles a request whose response_format resolves
+// to structured JSON or a refusal (see ToolCallGenerator.
+// ResolveResponseFormat), streaming it in place of the usual prose/tool
+// path and sending the closing finish_reason chunk and "[DONE]" itself.
+// handled is false when response_format didn't resolve to anything (plain
+// prose, an unrecognized shape), so the caller falls through to its normal
+// generation; err is only meaningful when handled is true.
+func (h *SSEStreamHandler) streamStructuredOutput(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	req *ChatCompletionRequest,
+	opts StreamOptions,
+	rng *streamRand,
+	batcher *frameBatcher,
+) (handled bool, err error) {
+	gram, refusal, matched := h.toolGen.ResolveResponseFormatGrammar(req.ResponseFormat)
+	var structured string
+	if !matched {
+		// Not a "json_schema" response format resolving to an
+		// object-rooted schema (e.g. "json_object", or a schema with no
+		// object root) - ResolveResponseFormat's fixed-literal handling
+		// covers these and isn't worth a compiled grammar for.
+		structured, refusal = h.toolGen.ResolveResponseFormat(req.ResponseFormat)
+		if structured == "" && refusal == "" {
+			return false, nil
+		}
+	}
+
+	switch {
+	case refusal != "":
+		h.sendChunk(batcher, w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
+			Index: 0,
+			Delta: models.ChatCompletionChunkChoiceDelta{Role: "assistant", Refusal: refusal},
+		}, nil)
+	case gram != nil:
+		if !h.streamGrammarOutput(ctx, w, flusher, completionID, created, req.Model, gram, opts, rng, batcher) {
+			return true, ctx.Err()
+		}
+	default:
+		if !h.streamJSONChunks(ctx, w, flusher, completionID, created, req.Model, structured, opts, rng, batcher) {
+			return true, ctx.Err()
+		}
+	}
+
+	finishReason := "stop"
+	if opts.Faults.InvalidFinishReason != "" {
+		finishReason = opts.Faults.InvalidFinishReason
+	}
+	h.sendChunk(batcher, w, flusher, completionID, created, req.Model, models.ChatCompletionChunkChoice{
+		Index:        0,
+		Delta:        models.ChatCompletionChunkChoiceDelta{},
+		FinishReason: &finishReason,
+	}, nil)
+	if batcher != nil {
+		batcher.flush()
+	}
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+	return true, nil
+}
+
+// grammarFlushThreshold is how many buffered bytes streamGrammarOutput
+// accumulates from a Grammar before flushing a content delta, matching
+// streamJSONChunks' fixed 20-byte fragmentation so both paths pace the
+// same regardless of which one a given response_format resolves to.
+const grammarFlushThreshold = 20
+
+// streamGrammarOutput drives gram.Generate and forwards what it emits as
+// content deltas, buffering into grammarFlushThreshold-sized chunks
+// before each send so pacing matches streamJSONChunks even though the
+// characters themselves are chosen live, one grammar state at a time,
+// rather than sliced out of an already-complete string. Reports false if
+// ctx is canceled before generation finishes, the same contract
+// streamJSONChunks/streamTextChunks use.
+func (h *SSEStreamHandler) streamGrammarOutput(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	gram *grammar.Grammar,
+	opts StreamOptions,
+	rng *streamRand,
+	batcher *frameBatcher,
+) bool {
+	var buf strings.Builder
+	first := true
+
+	flush := func() bool {
+		chunkText := buf.String()
+		buf.Reset()
+		delta := models.ChatCompletionChunkChoiceDelta{Content: chunkText}
+		if first {
+			delta.Role = "assistant"
+			first = false
+		}
+		h.sendChunk(batcher, w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
+			Index: 0,
+			Delta: delta,
+		}, nil)
+		return sleepForThrottle(ctx, opts, rng, chunkText)
+	}
+
+	aborted := false
+	// Generate only ever returns an error here via the ctx.Err() this
+	// emit callback propagates below - Compile already validated every
+	// $ref up front, so the grammar itself cannot fail mid-generation.
+	_ = gram.Generate(rng.Int63n(1<<62), func(s string) error {
+		if ctx.Err() != nil {
+			aborted = true
+			return ctx.Err()
+		}
+		buf.WriteString(s)
+		if buf.Len() < grammarFlushThreshold {
+			return nil
+		}
+		if !flush() {
+			aborted = true
+			return ctx.Err()
+		}
+		return nil
+	})
+	if aborted {
+		return false
+	}
+	if buf.Len() > 0 {
+		return flush()
+	}
+	return true
+}
+
+// streamJSONChunks sends text - already a complete, schema-valid JSON
+// value from ResolveResponseFormat's "json_object"/fallback cases - as a
+// sequence of content deltas sliced every 20 bytes, the same fixed-size
+// fragmentation buildToolCallSteps uses for tool call arguments, rather
+// than streamTextChunks' word splitting: a compactly marshaled JSON value
+// has no natural word boundaries. Fault injection (see FaultConfig) is
+// scoped to the plain-text path in streamTextChunks and does not apply
+// here. "json_schema" response formats instead stream through
+// streamGrammarOutput, generating character-by-character as they go.
+func (h *SSEStreamHandler) streamJSONChunks(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	text string,
+	opts StreamOptions,
+	rng *streamRand,
+	batcher *frameBatcher,
+) bool {
+	for i := 0; i < len(text); i += 20 {
+		if ctx.Err() != nil {
+			return false
+		}
+		end := i + 20
+		if end > len(text) {
+			end = len(text)
+		}
+		chunkText := text[i:end]
+		delta := models.ChatCompletionChunkChoiceDelta{Content: chunkText}
+		if i == 0 {
+			delta.Role = "assistant"
+		}
+		h.sendChunk(batcher, w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
+			Index: 0,
+			Delta: delta,
+		}, nil)
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return false
+		}
+	}
+	return true
+}
+
+// streamTextChunks reports false if ctx is canceled before every chunk was
+// sent, so StreamCompletion can stop the rest of the response immediately
+// instead of finishing a disconnected stream.
 func (h *SSEStreamHandler) streamTextChunks(
+	ctx context.Context,
 	w http.ResponseWriter,
 	flusher http.Flusher,
 	completionID string,
@@ -254,9 +793,16 @@ func (h *SSEStreamHandler) streamTextChunks(
 	model string,
 	text string,
 	opts StreamOptions,
-) {
+	rng *streamRand,
+	tracker *usageTracker,
+	batcher *frameBatcher,
+) bool {
 	words := strings.Fields(text)
+	chunksSent := 0
 	for i := 0; i < len(words); i += opts.ChunkSize {
+		if ctx.Err() != nil {
+			return false
+		}
 		end := i + opts.ChunkSize
 		if end > len(words) {
 			end = len(words)
@@ -265,48 +811,111 @@ func (h *SSEStreamHandler) streamTextChunks(
 		if end < len(words) {
 			chunkText += " "
 		}
-		h.sendChunk(w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
-			Index: 0,
+
+		delta := models.ChatCompletionChunkChoiceDelta{Role: "assistant", Content: chunkText}
+		if opts.Faults.MalformedJSONRate > 0 && rng.Float64() < opts.Faults.MalformedJSONRate {
+			sendMalformedChunk(w, flusher, completionID, created, model, delta)
+		} else {
+			h.sendChunk(batcher, w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
+				Index: 0,
+				Delta: delta,
+			}, nil)
+		}
+		h.maybeSendInterimUsage(w, flusher, completionID, created, model, opts, tracker, chunkText, batcher)
+		chunksSent++
+
+		if opts.Faults.TruncateAfterTokens > 0 && chunksSent >= opts.Faults.TruncateAfterTokens {
+			return false
+		}
+		if opts.Faults.StallAfterTokens > 0 && opts.Faults.StallDuration > 0 && chunksSent == opts.Faults.StallAfterTokens {
+			select {
+			case <-ctx.Done():
+				return false
+			case <-time.After(opts.Faults.StallDuration):
+			}
+		}
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return false
+		}
+	}
+	return true
+}
+
+// ToolCallInterleave selects how streamToolCallChunks schedules chunks
+// across multiple tool calls when StreamOptions.ParallelToolCalls is set.
+type ToolCallInterleave string
+
+const (
+	// ToolCallRoundRobin interleaves one chunk from each tool call per
+	// round, the default when ParallelToolCalls is set.
+	ToolCallRoundRobin ToolCallInterleave = "roundrobin"
+	// ToolCallWeighted round-robins like ToolCallRoundRobin but scales each
+	// tool call's per-chunk delay by its ToolCallSpeedFactors entry.
+	ToolCallWeighted ToolCallInterleave = "weighted"
+	// ToolCallSequential streams each tool call to completion before
+	// starting the next, the pre-interleaving behavior.
+	ToolCallSequential ToolCallInterleave = "sequential"
+)
+
+// toolCallStep is one wire chunk in a single tool call's emission sequence,
+// paired with the text that drives its throttle delay and usage-tracker
+// contribution. The opening name/id chunk carries no text, so it is never
+// throttled or counted.
+type toolCallStep struct {
+	choice models.ChatCompletionChunkChoice
+	text   string
+}
+
+// buildToolCallSteps expands one tool call into its wire chunk sequence: a
+// chunk announcing its name/id, followed by one chunk per 20-char slice of
+// its arguments, mirroring the fragmentation streamToolCallChunks has always
+// used.
+func buildToolCallSteps(idx int, call models.ChatCompletionMessageToolCall) []toolCallStep {
+	steps := []toolCallStep{{
+		choice: models.ChatCompletionChunkChoice{
+			Index: int64(idx),
 			Delta: models.ChatCompletionChunkChoiceDelta{
-				Role:    "assistant",
-				Content: chunkText,
+				ToolCalls: []models.ChatCompletionChunkToolCall{{
+					Index: int64(idx),
+					ID:    call.ID,
+					Type:  call.Type,
+					Function: models.ChatCompletionChunkToolCallFunction{
+						Name: call.Function.Name,
+					},
+				}},
 			},
-		}, nil)
-		// Compute delay: prefer DelayMin/DelayMax randomness; if not
-		// set, fall back to Delay fixed value. In addition, honor
-		// TokensPerSecond throttling which may extend the sleep to
-		// respect emission rate.
-		if opts.DelayMin > 0 || opts.DelayMax > 0 {
-			// ensure min <= max
-			min := opts.DelayMin
-			max := opts.DelayMax
-			if max < min {
-				max = min
-			}
-			// random in [min, max]
-			d := time.Duration(rand.Int63n(int64(max-min)+1)) + min
-			time.Sleep(d)
-		} else if opts.Delay > 0 {
-			time.Sleep(opts.Delay)
-		}
-
-		// Throttle by approximate token rate if requested. This sleep
-		// enforces a minimum duration; it does not reduce random jitter
-		// above.
-		if opts.TokensPerSecond > 0 {
-			tokens := utils.EstimateTokens(chunkText)
-			// tokens/sec -> seconds
-			dur := time.Duration(float64(tokens)/opts.TokensPerSecond*float64(time.Second))
-			// if the tokens-based sleep is larger than the previous one
-			// we need to wait the extra time.
-			if dur > 0 {
-				time.Sleep(dur)
-			}
+		},
+	}}
+
+	args := call.Function.Arguments
+	for j := 0; j < len(args); j += 20 {
+		end := j + 20
+		if end > len(args) {
+			end = len(args)
 		}
+		steps = append(steps, toolCallStep{
+			choice: models.ChatCompletionChunkChoice{
+				Index: int64(idx),
+				Delta: models.ChatCompletionChunkChoiceDelta{
+					ToolCalls: []models.ChatCompletionChunkToolCall{{
+						Index: int64(idx),
+						Function: models.ChatCompletionChunkToolCallFunction{
+							Arguments: args[j:end],
+						},
+					}},
+				},
+			},
+			text: args[j:end],
+		})
 	}
+	return steps
 }
 
+// streamToolCallChunks reports false if ctx is canceled before every tool
+// call finished streaming, so StreamCompletion can stop immediately instead
+// of finishing a disconnected stream.
 func (h *SSEStreamHandler) streamToolCallChunks(
+	ctx context.Context,
 	w http.ResponseWriter,
 	flusher http.Flusher,
 	completionID string,
@@ -314,173 +923,103 @@ func (h *SSEStreamHandler) streamToolCallChunks(
 	model string,
 	toolCalls []models.ChatCompletionMessageToolCall,
 	opts StreamOptions,
-) {
-	if opts.ParallelToolCalls {
-		// Use a writer goroutine to safely serialize writes to the ResponseWriter
-		type item struct {
-			chunk models.ChatCompletionChunk
-		}
-
-		ch := make(chan item, len(toolCalls)*4)
-		var wg sync.WaitGroup
-
-		// writer goroutine
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for it := range ch {
-				data, _ := json.Marshal(it.chunk)
-				fmt.Fprintf(w, "data: %s\n\n", string(data))
-				flusher.Flush()
+	rng *streamRand,
+	tracker *usageTracker,
+	batcher *frameBatcher,
+) bool {
+	if opts.ParallelToolCalls && len(toolCalls) > 1 && opts.ToolCallInterleave != ToolCallSequential {
+		return h.streamToolCallChunksInterleaved(ctx, w, flusher, completionID, created, model, toolCalls, opts, rng, tracker, batcher)
+	}
+
+	for idx, call := range toolCalls {
+		for _, step := range buildToolCallSteps(idx, call) {
+			if ctx.Err() != nil {
+				return false
+			}
+			h.sendChunk(batcher, w, flusher, completionID, created, model, step.choice, nil)
+			if step.text == "" {
+				continue
+			}
+			h.maybeSendInterimUsage(w, flusher, completionID, created, model, opts, tracker, step.text, batcher)
+			if !sleepForThrottle(ctx, opts, rng, step.text) {
+				return false
 			}
-		}()
-
-		// launch per-tool goroutines that push chunks to channel
-		var workerWG sync.WaitGroup
-		for idx, call := range toolCalls {
-			workerWG.Add(1)
-			go func(idx int, call models.ChatCompletionMessageToolCall) {
-				defer workerWG.Done()
-
-				chunk := models.ChatCompletionChunk{
-					ID:      completionID,
-					Object:  "chat.completion.chunk",
-					Created: created,
-					Model:   model,
-					Choices: []models.ChatCompletionChunkChoice{{
-						Index: int64(idx),
-						Delta: models.ChatCompletionChunkChoiceDelta{
-							ToolCalls: []models.ChatCompletionChunkToolCall{{
-								Index: int64(idx),
-								ID:    call.ID,
-								Type:  call.Type,
-								Function: models.ChatCompletionChunkToolCallFunction{
-									Name: call.Function.Name,
-								},
-							}},
-						},
-					}},
-				}
-				ch <- item{chunk: chunk}
-
-				args := call.Function.Arguments
-				if args != "" {
-					for j := 0; j < len(args); j += 20 {
-						end := j + 20
-						if end > len(args) {
-							end = len(args)
-						}
-						chunk2 := models.ChatCompletionChunk{
-							ID:      completionID,
-							Object:  "chat.completion.chunk",
-							Created: created,
-							Model:   model,
-							Choices: []models.ChatCompletionChunkChoice{{
-								Index: int64(idx),
-								Delta: models.ChatCompletionChunkChoiceDelta{
-									ToolCalls: []models.ChatCompletionChunkToolCall{{
-										Index: int64(idx),
-										Function: models.ChatCompletionChunkToolCallFunction{
-											Arguments: args[j:end],
-										},
-									}},
-								},
-							}},
-						}
-						ch <- item{chunk: chunk2}
-						// Writer goroutine does not know what the token
-						// content will be, so the worker sleeps between
-						// chunks to simulate the tool output sustain.
-						// Respect random jitter (min/max), fixed Delay, and
-						// the token throttle.
-						if opts.DelayMin > 0 || opts.DelayMax > 0 {
-							min := opts.DelayMin
-							max := opts.DelayMax
-							if max < min {
-								max = min
-							}
-							d := time.Duration(rand.Int63n(int64(max-min)+1)) + min
-							time.Sleep(d)
-						} else if opts.Delay > 0 {
-							time.Sleep(opts.Delay)
-						}
-						if opts.TokensPerSecond > 0 {
-							tokens := utils.EstimateTokens(args[j:end])
-							dur := time.Duration(float64(tokens)/opts.TokensPerSecond*float64(time.Second))
-							if dur > 0 {
-								time.Sleep(dur)
-							}
-						}
-					}
-				}
-			}(idx, call)
 		}
-
-		workerWG.Wait()
-		close(ch)
-		wg.Wait()
-		return
 	}
+	return true
+}
 
+// streamToolCallChunksInterleaved fans multiple tool calls' chunk sequences
+// into a single round-robin schedule: one chunk from each tool call still in
+// progress per round, so a client observes interleaved index-addressed
+// deltas the way real providers emit concurrent tool calls, rather than one
+// tool call finishing before the next starts. When opts.ToolCallInterleave
+// is ToolCallWeighted, each tool call's per-chunk delay is scaled by its
+// entry in opts.ToolCallSpeedFactors (missing entries default to 1), so one
+// tool can be simulated as slower than another while the round-robin order
+// stays the same. It reports false if ctx is canceled before every tool
+// call finished.
+func (h *SSEStreamHandler) streamToolCallChunksInterleaved(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	toolCalls []models.ChatCompletionMessageToolCall,
+	opts StreamOptions,
+	rng *streamRand,
+	tracker *usageTracker,
+	batcher *frameBatcher,
+) bool {
+	queues := make([][]toolCallStep, len(toolCalls))
+	remaining := 0
 	for idx, call := range toolCalls {
-		h.sendChunk(w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
-			Index: int64(idx),
-			Delta: models.ChatCompletionChunkChoiceDelta{
-				ToolCalls: []models.ChatCompletionChunkToolCall{
-					{
-						Index: int64(idx),
-						ID:    call.ID,
-						Type:  call.Type,
-						Function: models.ChatCompletionChunkToolCallFunction{
-							Name: call.Function.Name,
-						},
-					},
-				},
-			},
-		}, nil)
-		args := call.Function.Arguments
-		if args != "" {
-			for j := 0; j < len(args); j += 20 {
-				end := j + 20
-				if end > len(args) {
-					end = len(args)
+		queues[idx] = buildToolCallSteps(idx, call)
+		remaining++
+	}
+
+	for remaining > 0 {
+		for idx := range queues {
+			if len(queues[idx]) == 0 {
+				continue
+			}
+			if ctx.Err() != nil {
+				return false
+			}
+
+			step := queues[idx][0]
+			queues[idx] = queues[idx][1:]
+			if len(queues[idx]) == 0 {
+				remaining--
+			}
+
+			h.sendChunk(batcher, w, flusher, completionID, created, model, step.choice, nil)
+			if step.text == "" {
+				continue
+			}
+			h.maybeSendInterimUsage(w, flusher, completionID, created, model, opts, tracker, step.text, batcher)
+
+			speedFactor := 1.0
+			if opts.ToolCallInterleave == ToolCallWeighted {
+				if f, ok := opts.ToolCallSpeedFactors[idx]; ok && f > 0 {
+					speedFactor = f
 				}
-				h.sendChunk(w, flusher, completionID, created, model, models.ChatCompletionChunkChoice{
-					Index: int64(idx),
-					Delta: models.ChatCompletionChunkChoiceDelta{
-						ToolCalls: []models.ChatCompletionChunkToolCall{{
-							Index: int64(idx),
-							Function: models.ChatCompletionChunkToolCallFunction{
-								Arguments: args[j:end],
-							},
-						}},
-					},
-				}, nil)
-					// Sleep by jitter / fixed delay then throttle by token rate
-					if opts.DelayMin > 0 || opts.DelayMax > 0 {
-						min := opts.DelayMin
-						max := opts.DelayMax
-						if max < min {
-							max = min
-						}
-						d := time.Duration(rand.Int63n(int64(max-min)+1)) + min
-						time.Sleep(d)
-					} else if opts.Delay > 0 {
-						time.Sleep(opts.Delay)
-					}
-					if opts.TokensPerSecond > 0 {
-						tokens := utils.EstimateTokens(args[j:end])
-						dur := time.Duration(float64(tokens)/opts.TokensPerSecond*float64(time.Second))
-						if dur > 0 {
-							time.Sleep(dur)
-						}
-					}
+			}
+			if !sleepForThrottleScaled(ctx, opts, rng, step.text, speedFactor) {
+				return false
 			}
 		}
 	}
+	return true
 }
 
+// sendChunk emits choice (and usage, if any) as an SSE frame. When batcher
+// is non-nil (StreamOptions.MaxFrameBytes > 0), the delta is coalesced into
+// the batcher's pending frame instead of being written immediately; see
+// frameBatcher.
 func (h *SSEStreamHandler) sendChunk(
+	batcher *frameBatcher,
 	w http.ResponseWriter,
 	flusher http.Flusher,
 	completionID string,
@@ -489,6 +1028,10 @@ func (h *SSEStreamHandler) sendChunk(
 	choice models.ChatCompletionChunkChoice,
 	usage *models.CompletionUsage,
 ) {
+	if batcher != nil {
+		batcher.add(completionID, created, model, choice, usage)
+		return
+	}
 	chunk := models.ChatCompletionChunk{
 		ID:      completionID,
 		Object:  "chat.completion.chunk",
@@ -502,6 +1045,195 @@ func (h *SSEStreamHandler) sendChunk(
 	flusher.Flush()
 }
 
+// sendMalformedChunk writes a deliberately corrupted SSE data frame —
+// otherwise-valid JSON cut off mid-object — exercising a client's handling
+// of a malformed chunk a flaky real provider might emit. It bypasses any
+// frameBatcher since the corruption only makes sense for one standalone
+// frame, not a coalesced one.
+func sendMalformedChunk(w http.ResponseWriter, flusher http.Flusher, completionID string, created int64, model string, delta models.ChatCompletionChunkChoiceDelta) {
+	chunk := models.ChatCompletionChunk{
+		ID:      completionID,
+		Object:  "chat.completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []models.ChatCompletionChunkChoice{{Index: 0, Delta: delta}},
+	}
+	data, _ := json.Marshal(chunk)
+	cut := len(data) / 2
+	fmt.Fprintf(w, "data: %s\n\n", string(data[:cut]))
+	flusher.Flush()
+}
+
+// StreamLegacyCompletion streams SSE chunks for the legacy `/v1/completions`
+// endpoint, reusing the same chunking, jitter, and token-throttle logic as
+// chat completions but emitting `text_completion`/`text_completion.chunk`
+// shapes instead.
+func (h *SSEStreamHandler) StreamLegacyCompletion(
+	ctx context.Context,
+	w http.ResponseWriter,
+	model string,
+	prompts []string,
+	maxTokens int64,
+	echo bool,
+	suffix string,
+	stops []string,
+	opts StreamOptions,
+) error {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("Transfer-Encoding", "chunked")
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return fmt.Errorf("streaming not supported")
+	}
+
+	completionID := h.idGen.GenerateID()
+	created := time.Now().Unix()
+
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = 3
+	}
+
+	if h.defaults != nil {
+		if opts.DelayMin == 0 {
+			opts.DelayMin = h.defaults.DelayMin
+		}
+		if opts.DelayMax == 0 {
+			opts.DelayMax = h.defaults.DelayMax
+		}
+		if opts.TokensPerSecond == 0 {
+			opts.TokensPerSecond = h.defaults.TokensPerSecond
+		}
+		if opts.TimeToFirstToken == (LatencySpec{}) {
+			opts.TimeToFirstToken = h.defaults.TimeToFirstToken
+		}
+		if opts.InterTokenLatency == (LatencySpec{}) {
+			opts.InterTokenLatency = h.defaults.InterTokenLatency
+		}
+		if opts.Seed == 0 {
+			opts.Seed = h.defaults.Seed
+		}
+	}
+	rng := newStreamRand(opts.Seed)
+	if !applyTimeToFirstToken(ctx, opts, rng) {
+		return ctx.Err()
+	}
+
+	var totalCompletionTokens int64
+	for idx, prompt := range prompts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		minLen, maxLen := MapResponseLengthToRange("")
+		text := h.resolveTextGen(model).GenerateText(ctx, minLen, maxLen)
+
+		finishReason := "stop"
+		if truncated, stopped := utils.TruncateAtStop(text, stops); stopped {
+			text = truncated
+		} else if maxTokens > 0 {
+			if tokens := utils.EstimateTokens(text); tokens >= maxTokens {
+				finishReason = "length"
+			}
+		}
+		if echo {
+			text = prompt + text
+		}
+		if suffix != "" {
+			text += suffix
+		}
+		totalCompletionTokens += utils.EstimateTokens(text)
+
+		if !h.streamLegacyTextChunks(ctx, w, flusher, completionID, created, model, int64(idx), text, opts, rng) {
+			return ctx.Err()
+		}
+
+		h.sendLegacyChunk(w, flusher, completionID, created, model, models.CompletionChunkChoice{
+			Text:         "",
+			Index:        int64(idx),
+			FinishReason: &finishReason,
+		}, nil)
+	}
+
+	if opts.IncludeUsage {
+		promptTokens := utils.EstimateTokens(strings.Join(prompts, " "))
+		usage := &models.CompletionUsage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: totalCompletionTokens,
+			TotalTokens:      promptTokens + totalCompletionTokens,
+		}
+		h.sendLegacyChunk(w, flusher, completionID, created, model, models.CompletionChunkChoice{Index: 0}, usage)
+	}
+
+	fmt.Fprint(w, "data: [DONE]\n\n")
+	flusher.Flush()
+
+	return nil
+}
+
+// streamLegacyTextChunks reports false if ctx is canceled before every
+// chunk was sent, so StreamLegacyCompletion can stop the rest of the
+// response immediately instead of finishing a disconnected stream.
+func (h *SSEStreamHandler) streamLegacyTextChunks(
+	ctx context.Context,
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	index int64,
+	text string,
+	opts StreamOptions,
+	rng *streamRand,
+) bool {
+	words := strings.Fields(text)
+	for i := 0; i < len(words); i += opts.ChunkSize {
+		if ctx.Err() != nil {
+			return false
+		}
+		end := i + opts.ChunkSize
+		if end > len(words) {
+			end = len(words)
+		}
+		chunkText := strings.Join(words[i:end], " ")
+		if end < len(words) {
+			chunkText += " "
+		}
+		h.sendLegacyChunk(w, flusher, completionID, created, model, models.CompletionChunkChoice{
+			Text:  chunkText,
+			Index: index,
+		}, nil)
+
+		if !sleepForThrottle(ctx, opts, rng, chunkText) {
+			return false
+		}
+	}
+	return true
+}
+
+func (h *SSEStreamHandler) sendLegacyChunk(
+	w http.ResponseWriter,
+	flusher http.Flusher,
+	completionID string,
+	created int64,
+	model string,
+	choice models.CompletionChunkChoice,
+	usage *models.CompletionUsage,
+) {
+	chunk := models.CompletionChunk{
+		ID:      completionID,
+		Object:  "text_completion.chunk",
+		Created: created,
+		Model:   model,
+		Choices: []models.CompletionChunkChoice{choice},
+		Usage:   usage,
+	}
+	data, _ := json.Marshal(chunk)
+	fmt.Fprintf(w, "data: %s\n\n", string(data))
+	flusher.Flush()
+}
+
 func messageToStrings(messages []models.ChatCompletionMessage) []string {
 	var result []string
 	for _, msg := range messages {
@@ -511,3 +1243,14 @@ func messageToStrings(messages []models.ChatCompletionMessage) []string {
 	}
 	return result
 }
+
+// promptTokenText concatenates message content with a rendering of the
+// available tool definitions, so prompt token accounting charges for tools
+// the same way a real provider bills them as part of the prompt.
+func promptTokenText(messages []models.ChatCompletionMessage, tools []generator.ToolDefinition) string {
+	parts := messageToStrings(messages)
+	for _, t := range tools {
+		parts = append(parts, t.Function.Name, t.Function.Description, string(t.Function.Parameters))
+	}
+	return strings.Join(parts, " ")
+}
@@ -2,11 +2,13 @@ package streaming
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/quantalogic/openai-api-simulator/pkg/generator"
 	"github.com/quantalogic/openai-api-simulator/pkg/models"
 	"github.com/stretchr/testify/require"
 )
@@ -63,3 +65,493 @@ func TestStreamCompletion_WithLatencyAndThrottle(t *testing.T) {
 	require.Contains(t, out, "chat.completion.chunk")
 	require.Contains(t, out, "[DONE]")
 }
+
+func TestStreamCompletion_TimeToFirstTokenAndInterTokenLatency(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	opts := StreamOptions{
+		ChunkSize:         3,
+		Seed:              7,
+		TimeToFirstToken:  LatencySpec{Distribution: LatencyUniform, Min: time.Millisecond, Max: 2 * time.Millisecond},
+		InterTokenLatency: LatencySpec{Distribution: LatencyNormal, Mean: 0.001, StdDev: 0.0005},
+	}
+
+	fw := &fakeFlusher{}
+	start := time.Now()
+	err := handler.StreamCompletion(context.Background(), fw, req, opts)
+	require.NoError(t, err)
+	require.Greater(t, time.Since(start), time.Duration(0))
+
+	out := fw.String()
+	require.Contains(t, out, "chat.completion.chunk")
+	require.Contains(t, out, "[DONE]")
+}
+
+func TestStreamCompletion_UsageIntervalEmitsInterimUsageChunks(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:          "gpt-sim-1",
+		Messages:       []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+		ResponseLength: "long",
+	}
+
+	fw := &fakeFlusher{}
+	opts := StreamOptions{IncludeUsage: true, ChunkSize: 3, UsageInterval: 2}
+	err := handler.StreamCompletion(context.Background(), fw, req, opts)
+	require.NoError(t, err)
+
+	out := fw.String()
+	lines := strings.Split(out, "\n")
+
+	var completionTokens []int64
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			completionTokens = append(completionTokens, chunk.Usage.CompletionTokens)
+		}
+	}
+
+	// At least one interim usage chunk in addition to the final one, and
+	// completion tokens never decrease as the stream progresses.
+	require.Greater(t, len(completionTokens), 1)
+	for i := 1; i < len(completionTokens); i++ {
+		require.GreaterOrEqual(t, completionTokens[i], completionTokens[i-1])
+	}
+}
+
+func TestStreamCompletion_MaxTokensTruncatesAndSetsLengthFinishReason(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:          "gpt-sim-1",
+		Messages:       []models.ChatCompletionMessage{{Role: "user", Content: "Tell me a long story"}},
+		ResponseLength: "long",
+		MaxTokens:      2,
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{IncludeUsage: true, ChunkSize: 3})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, `"finish_reason":"length"`)
+
+	var completionTokens int64
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			continue
+		}
+		if chunk.Usage != nil {
+			completionTokens = chunk.Usage.CompletionTokens
+		}
+	}
+	require.LessOrEqual(t, completionTokens, int64(2))
+}
+
+func TestStreamCompletion_ContextCancelStopsPromptly(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:          "gpt-sim-1",
+		Messages:       []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+		ResponseLength: "long",
+	}
+
+	// A long per-chunk delay means an uncancelled stream would take seconds
+	// to finish; cancelling shortly after it starts should make it return in
+	// well under that time instead of running to completion.
+	opts := StreamOptions{ChunkSize: 1, Delay: 200 * time.Millisecond}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fw := &fakeFlusher{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- handler.StreamCompletion(ctx, fw, req, opts)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, context.Canceled)
+	case <-time.After(time.Second):
+		t.Fatal("StreamCompletion did not return promptly after context cancellation")
+	}
+}
+
+func TestStreamCompletion_ParallelToolCallsContextCancelStopsPromptly(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "What time is it?"}},
+		Tools: []generator.ToolDefinition{
+			{Type: "function", Function: models.FunctionDefinition{Name: "now"}},
+			{Type: "function", Function: models.FunctionDefinition{Name: "later"}},
+		},
+		ToolChoice:        "required",
+		ParallelToolCalls: true,
+	}
+
+	opts := StreamOptions{ChunkSize: 1, Delay: 200 * time.Millisecond, ParallelToolCalls: true}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	fw := &fakeFlusher{}
+
+	done := make(chan error, 1)
+	go func() {
+		done <- handler.StreamCompletion(ctx, fw, req, opts)
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("StreamCompletion with ParallelToolCalls did not return promptly after context cancellation")
+	}
+}
+
+// twoToolCalls builds two tool calls whose arguments are long enough to
+// fragment into several chunks each, so interleaving across calls is
+// observable in the emitted chunk sequence.
+func twoToolCalls() []models.ChatCompletionMessageToolCall {
+	return []models.ChatCompletionMessageToolCall{
+		{ID: "call_0", Type: "function", Function: models.ChatCompletionMessageToolCallFunction{
+			Name: "now", Arguments: `{"timezone":"America/New_York","format":"iso8601"}`,
+		}},
+		{ID: "call_1", Type: "function", Function: models.ChatCompletionMessageToolCallFunction{
+			Name: "weather", Arguments: `{"city":"San Francisco","units":"metric"}`,
+		}},
+	}
+}
+
+func toolCallChunkIndexes(t *testing.T, out string) []int64 {
+	t.Helper()
+	var indexes []int64
+	for _, line := range strings.Split(out, "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		if err := json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk); err != nil {
+			continue
+		}
+		for _, choice := range chunk.Choices {
+			if len(choice.Delta.ToolCalls) > 0 {
+				indexes = append(indexes, choice.Index)
+			}
+		}
+	}
+	return indexes
+}
+
+func TestStreamToolCallChunks_RoundRobinInterleavesAcrossCalls(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+
+	ok := handler.streamToolCallChunks(context.Background(), fw, fw, "chatcmpl-1", 0, "gpt-sim-1",
+		twoToolCalls(), StreamOptions{ParallelToolCalls: true}, newStreamRand(1), nil, nil)
+	require.True(t, ok)
+
+	indexes := toolCallChunkIndexes(t, fw.String())
+	// ToolCallRoundRobin is the default, so the two tool calls' chunks
+	// interleave rather than one finishing before the other starts: index 0
+	// must reappear after index 1 has been seen.
+	require.GreaterOrEqual(t, len(indexes), 4)
+	sawIndex1 := false
+	sawIndex0AfterIndex1 := false
+	for _, idx := range indexes {
+		if idx == 1 {
+			sawIndex1 = true
+		}
+		if idx == 0 && sawIndex1 {
+			sawIndex0AfterIndex1 = true
+		}
+	}
+	require.True(t, sawIndex0AfterIndex1, "expected tool call 0's chunks to interleave with tool call 1's, got index sequence %v", indexes)
+}
+
+func TestStreamToolCallChunks_SequentialFinishesOneBeforeNext(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+
+	opts := StreamOptions{ParallelToolCalls: true, ToolCallInterleave: ToolCallSequential}
+	ok := handler.streamToolCallChunks(context.Background(), fw, fw, "chatcmpl-1", 0, "gpt-sim-1",
+		twoToolCalls(), opts, newStreamRand(1), nil, nil)
+	require.True(t, ok)
+
+	indexes := toolCallChunkIndexes(t, fw.String())
+	// ToolCallSequential restores the pre-interleaving behavior: once index 1
+	// appears, index 0 must never appear again.
+	sawIndex1 := false
+	for _, idx := range indexes {
+		if idx == 1 {
+			sawIndex1 = true
+		}
+		if idx == 0 && sawIndex1 {
+			t.Fatalf("expected tool call 0 to finish before tool call 1 started, got index sequence %v", indexes)
+		}
+	}
+}
+
+func TestStreamToolCallChunks_WeightedSlowsSlowerToolCall(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	fw := &fakeFlusher{}
+
+	opts := StreamOptions{
+		ParallelToolCalls:    true,
+		ToolCallInterleave:   ToolCallWeighted,
+		ToolCallSpeedFactors: map[int]float64{1: 20},
+		Delay:                2 * time.Millisecond,
+	}
+
+	start := time.Now()
+	ok := handler.streamToolCallChunks(context.Background(), fw, fw, "chatcmpl-1", 0, "gpt-sim-1",
+		twoToolCalls(), opts, newStreamRand(1), nil, nil)
+	elapsed := time.Since(start)
+	require.True(t, ok)
+
+	// Tool call 1's per-chunk delay is scaled 20x, so a stream that would
+	// otherwise finish in a handful of milliseconds takes noticeably longer.
+	require.Greater(t, elapsed, 20*time.Millisecond)
+}
+
+func TestStreamCompletion_ToolboxExecutesAndStreamsFollowUp(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	handler.SetToolbox(generator.NewToolbox())
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "What time is it?"}},
+		Tools: []generator.ToolDefinition{
+			{Type: "function", Function: models.FunctionDefinition{Name: "now"}},
+		},
+		ToolChoice: map[string]interface{}{
+			"type":     "function",
+			"function": map[string]interface{}{"name": "now"},
+		},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, `"name":"now"`)
+	require.Contains(t, out, `"finish_reason":"tool_calls"`)
+	require.Contains(t, out, "now returned:")
+	require.Contains(t, out, `"finish_reason":"stop"`)
+}
+
+func TestStreamCompletion_NeverStrategySuppressesToolCalls(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "What time is it?"}},
+		Tools: []generator.ToolDefinition{
+			{Type: "function", Function: models.FunctionDefinition{Name: "now"}},
+		},
+		ToolStrategy: "never",
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.NotContains(t, out, "tool_calls")
+}
+
+func TestStreamCompletion_ToolResultMessageGeneratesReferencingReply(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model: "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{
+			{Role: "user", Content: "What time is it?"},
+			{Role: "assistant", ToolCalls: []models.ChatCompletionMessageToolCall{
+				{ID: "call_1", Type: "function", Function: models.ChatCompletionMessageToolCallFunction{Name: "now"}},
+			}},
+			{Role: "tool", ToolCallID: "call_1", Content: "14:32 UTC"},
+		},
+		Tools: []generator.ToolDefinition{
+			{Type: "function", Function: models.FunctionDefinition{Name: "now"}},
+		},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, "now ")
+	require.Contains(t, out, "returned: 14:32 UTC")
+	require.Contains(t, out, `"finish_reason":"stop"`)
+	require.NotContains(t, out, `"finish_reason":"tool_calls"`)
+}
+
+func TestStreamCompletion_BackendRouterServesRoutedModel(t *testing.T) {
+	handler := NewSSEStreamHandler()
+	cfg := &generator.ModelsConfig{Models: []generator.ModelConfig{
+		{Name: "gpt-sim-1", Backend: generator.ModelBackendConfig{Type: generator.BackendTypeSimulated}},
+	}}
+	router, err := generator.NewBackendRouterFromConfig(cfg)
+	require.NoError(t, err)
+	handler.SetBackendRouter(router)
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	fw := &fakeFlusher{}
+	err = handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, "chat.completion.chunk")
+	require.Contains(t, out, "[DONE]")
+}
+
+func TestStreamCompletion_AnthropicDialect(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3, Dialect: DialectAnthropic})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, "event: message_start")
+	require.Contains(t, out, "event: content_block_start")
+	require.Contains(t, out, "event: content_block_delta")
+	require.Contains(t, out, "event: content_block_stop")
+	require.Contains(t, out, "event: message_delta")
+	require.Contains(t, out, "event: message_stop")
+	require.Contains(t, out, `"stop_reason":"end_turn"`)
+	require.NotContains(t, out, "[DONE]")
+}
+
+func TestStreamCompletion_AnthropicDialect_ToolUse(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "What time is it?"}},
+		Tools: []generator.ToolDefinition{
+			{Type: "function", Function: models.FunctionDefinition{Name: "now"}},
+		},
+		ToolChoice: map[string]interface{}{
+			"type":     "function",
+			"function": map[string]interface{}{"name": "now"},
+		},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3, Dialect: DialectAnthropic})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, `"type":"tool_use"`)
+	require.Contains(t, out, `"name":"now"`)
+	require.Contains(t, out, `"type":"input_json_delta"`)
+	require.Contains(t, out, `"stop_reason":"tool_use"`)
+}
+
+func TestStreamCompletion_CohereDialect(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3, Dialect: DialectCohere})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, `"event_type":"stream-start"`)
+	require.Contains(t, out, `"event_type":"text-generation"`)
+	require.Contains(t, out, `"event_type":"stream-end"`)
+}
+
+func TestStreamCompletion_AzureDialect(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Hello"}},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3, Dialect: DialectAzureOpenAI})
+	require.NoError(t, err)
+
+	out := fw.String()
+	require.Contains(t, out, "content_filter_results")
+	require.Contains(t, out, "[DONE]")
+}
+
+func TestStreamCompletion_ResponseFormatStreamsStructuredJSON(t *testing.T) {
+	handler := NewSSEStreamHandler()
+
+	req := &ChatCompletionRequest{
+		Model:    "gpt-sim-1",
+		Messages: []models.ChatCompletionMessage{{Role: "user", Content: "Describe a person"}},
+		ResponseFormat: map[string]interface{}{
+			"type": "json_schema",
+			"json_schema": map[string]interface{}{
+				"schema": map[string]interface{}{
+					"type":       "object",
+					"properties": map[string]interface{}{"name": map[string]interface{}{"type": "string"}},
+					"required":   []interface{}{"name"},
+				},
+			},
+		},
+	}
+
+	fw := &fakeFlusher{}
+	err := handler.StreamCompletion(context.Background(), fw, req, StreamOptions{ChunkSize: 3})
+	require.NoError(t, err)
+
+	var accumulated strings.Builder
+	for _, line := range strings.Split(fw.String(), "\n") {
+		if !strings.HasPrefix(line, "data: ") || strings.TrimPrefix(line, "data: ") == "[DONE]" {
+			continue
+		}
+		var chunk models.ChatCompletionChunk
+		require.NoError(t, json.Unmarshal([]byte(strings.TrimPrefix(line, "data: ")), &chunk))
+		accumulated.WriteString(chunk.Choices[0].Delta.Content)
+	}
+
+	var parsed map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(accumulated.String()), &parsed))
+	require.Contains(t, parsed, "name")
+	require.Contains(t, fw.String(), `"finish_reason":"stop"`)
+}
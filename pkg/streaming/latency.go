@@ -0,0 +1,240 @@
+package streaming
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/quantalogic/openai-api-simulator/pkg/utils"
+)
+
+// LatencyDistribution selects how a LatencySpec samples its delay.
+type LatencyDistribution string
+
+const (
+	// LatencyUniform draws uniformly between Min and Max, matching the
+	// jitter StreamOptions.DelayMin/DelayMax have always offered.
+	LatencyUniform LatencyDistribution = "uniform"
+	// LatencyNormal draws from a normal distribution (Mean, StdDev in
+	// seconds), clamped to >=0.
+	LatencyNormal LatencyDistribution = "normal"
+	// LatencyLognormal draws from a log-normal distribution: Mean and
+	// StdDev are the mu/sigma (in log-seconds) of the underlying normal.
+	LatencyLognormal LatencyDistribution = "lognormal"
+	// LatencyPareto draws from a Pareto Type-I distribution (Shape is the
+	// tail index alpha, Scale is the minimum value xm), useful for
+	// modeling occasional "slow token" spikes.
+	LatencyPareto LatencyDistribution = "pareto"
+)
+
+// LatencySpec samples a single delay. It is used both for
+// StreamOptions.TimeToFirstToken, sampled exactly once before the first
+// delta, and StreamOptions.InterTokenLatency, sampled once per emitted
+// chunk. The zero value samples no delay.
+type LatencySpec struct {
+	Distribution LatencyDistribution
+
+	// Min/Max bound a LatencyUniform draw.
+	Min time.Duration
+	Max time.Duration
+
+	// Mean/StdDev parameterize LatencyNormal directly in seconds, and for
+	// LatencyLognormal are the mu/sigma (in log-seconds) of the underlying
+	// normal distribution whose exponential is sampled.
+	Mean   float64
+	StdDev float64
+
+	// Shape/Scale parameterize LatencyPareto: the classic alpha (tail
+	// index) and xm (minimum/scale). Smaller Shape means heavier tails.
+	Shape float64
+	Scale time.Duration
+}
+
+// sample draws one delay from the spec's distribution, clamped to >=0. The
+// zero value (Distribution == "") samples no delay, letting callers fall
+// back to StreamOptions' legacy Delay/DelayMin/DelayMax fields.
+func (s LatencySpec) sample(rng *streamRand) time.Duration {
+	switch s.Distribution {
+	case LatencyUniform:
+		min, max := s.Min, s.Max
+		if max < min {
+			max = min
+		}
+		if max == min {
+			return min
+		}
+		return time.Duration(rng.Int63n(int64(max-min)+1)) + min
+
+	case LatencyNormal:
+		seconds := s.Mean + s.StdDev*rng.NormFloat64()
+		if seconds < 0 {
+			seconds = 0
+		}
+		return time.Duration(seconds * float64(time.Second))
+
+	case LatencyLognormal:
+		seconds := math.Exp(s.Mean + s.StdDev*rng.NormFloat64())
+		return time.Duration(seconds * float64(time.Second))
+
+	case LatencyPareto:
+		shape := s.Shape
+		if shape <= 0 {
+			shape = 1
+		}
+		u := rng.Float64()
+		if u >= 1 {
+			u = 0.999999
+		}
+		seconds := float64(s.Scale) / math.Pow(1-u, 1/shape)
+		return time.Duration(seconds)
+
+	default:
+		return 0
+	}
+}
+
+// streamRand wraps a *rand.Rand with a mutex so a single per-request
+// source is safe to share across goroutines, while still producing a
+// reproducible sequence when StreamOptions.Seed is set.
+type streamRand struct {
+	mu  sync.Mutex
+	rng *rand.Rand
+}
+
+// newStreamRand seeds a streamRand from seed, or from the current time
+// when seed is 0 so unseeded requests keep today's non-deterministic
+// behavior.
+func newStreamRand(seed int64) *streamRand {
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &streamRand{rng: rand.New(rand.NewSource(seed))}
+}
+
+func (r *streamRand) Int63n(n int64) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Int63n(n)
+}
+
+func (r *streamRand) Float64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.Float64()
+}
+
+func (r *streamRand) NormFloat64() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.rng.NormFloat64()
+}
+
+// throttleDelay computes the single per-chunk delay shared by every
+// dialect's chunking loop. InterTokenLatency, when set, replaces the
+// legacy Delay/DelayMin/DelayMax jitter. TokensPerSecond models a
+// throughput cap rather than an additional latency, so the two compose as
+// the slower (max) of the two rather than stacking: the achievable rate is
+// bounded by whichever constraint is tighter for this chunk.
+func throttleDelay(opts StreamOptions, rng *streamRand, chunkText string) time.Duration {
+	var latencyDelay time.Duration
+	switch {
+	case opts.InterTokenLatency.Distribution != "":
+		latencyDelay = opts.InterTokenLatency.sample(rng)
+	case opts.DelayMin > 0 || opts.DelayMax > 0:
+		min, max := opts.DelayMin, opts.DelayMax
+		if max < min {
+			max = min
+		}
+		latencyDelay = time.Duration(rng.Int63n(int64(max-min)+1)) + min
+	case opts.Delay > 0:
+		latencyDelay = opts.Delay
+	}
+
+	var throughputDelay time.Duration
+	if opts.TokensPerSecond > 0 {
+		var tokens int64
+		if opts.Tokenizer != nil {
+			tokens = opts.Tokenizer.Count(chunkText)
+		} else {
+			tokens = utils.EstimateTokens(chunkText)
+		}
+		throughputDelay = time.Duration(float64(tokens) / opts.TokensPerSecond * float64(time.Second))
+	}
+
+	delay := latencyDelay
+	if throughputDelay > delay {
+		delay = throughputDelay
+	}
+	return delay
+}
+
+// SimulateLatency blocks for a one-shot delay sampled from opts' jitter
+// fields (TimeToFirstToken, InterTokenLatency, or the legacy Delay/DelayMin/
+// DelayMax), for non-streaming endpoints (embeddings, audio, images) that
+// want to honor the same pacing controls as the chat completions streaming
+// path without opening a full SSE session. TokensPerSecond has no effect
+// here since there is no per-chunk text to size it against. It reports
+// false if ctx is canceled before the delay elapses.
+func SimulateLatency(ctx context.Context, opts StreamOptions) bool {
+	rng := newStreamRand(opts.Seed)
+	switch {
+	case opts.TimeToFirstToken.Distribution != "":
+		return waitForDelay(ctx, opts.TimeToFirstToken.sample(rng))
+	case opts.InterTokenLatency.Distribution != "":
+		return waitForDelay(ctx, opts.InterTokenLatency.sample(rng))
+	case opts.DelayMin > 0 || opts.DelayMax > 0:
+		min, max := opts.DelayMin, opts.DelayMax
+		if max < min {
+			max = min
+		}
+		return waitForDelay(ctx, time.Duration(rng.Int63n(int64(max-min)+1))+min)
+	case opts.Delay > 0:
+		return waitForDelay(ctx, opts.Delay)
+	default:
+		return ctx.Err() == nil
+	}
+}
+
+// waitForDelay blocks for delay, or until ctx is canceled first. It reports
+// false on cancellation, so callers can stop their chunking loop immediately
+// instead of finishing a disconnected stream.
+func waitForDelay(ctx context.Context, delay time.Duration) bool {
+	if delay <= 0 {
+		return ctx.Err() == nil
+	}
+	select {
+	case <-time.After(delay):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// sleepForThrottle applies a single per-chunk delay computed by
+// throttleDelay. It reports false if ctx is canceled before the delay
+// elapses.
+func sleepForThrottle(ctx context.Context, opts StreamOptions, rng *streamRand, chunkText string) bool {
+	return waitForDelay(ctx, throttleDelay(opts, rng, chunkText))
+}
+
+// sleepForThrottleScaled applies throttleDelay scaled by speedFactor (>1
+// slower, <1 faster), letting StreamOptions.ToolCallWeighted simulate one
+// concurrent tool call as slower or faster than another without changing
+// the distribution/throughput model itself. It reports false if ctx is
+// canceled before the delay elapses.
+func sleepForThrottleScaled(ctx context.Context, opts StreamOptions, rng *streamRand, chunkText string, speedFactor float64) bool {
+	delay := throttleDelay(opts, rng, chunkText)
+	if speedFactor > 0 {
+		delay = time.Duration(float64(delay) * speedFactor)
+	}
+	return waitForDelay(ctx, delay)
+}
+
+// applyTimeToFirstToken sleeps once for opts.TimeToFirstToken, before any
+// delta is sent, simulating the provider-side latency a real streaming API
+// incurs before its first token. It reports false if ctx is canceled first.
+func applyTimeToFirstToken(ctx context.Context, opts StreamOptions, rng *streamRand) bool {
+	return waitForDelay(ctx, opts.TimeToFirstToken.sample(rng))
+}
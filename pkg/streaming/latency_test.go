@@ -0,0 +1,94 @@
+package streaming
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatencySpecSampleUniformBounded(t *testing.T) {
+	spec := LatencySpec{Distribution: LatencyUniform, Min: 10 * time.Millisecond, Max: 20 * time.Millisecond}
+	rng := newStreamRand(1)
+	for i := 0; i < 50; i++ {
+		d := spec.sample(rng)
+		require.GreaterOrEqual(t, d, spec.Min)
+		require.LessOrEqual(t, d, spec.Max)
+	}
+}
+
+func TestLatencySpecSampleNormalNeverNegative(t *testing.T) {
+	spec := LatencySpec{Distribution: LatencyNormal, Mean: 0, StdDev: 1}
+	rng := newStreamRand(2)
+	for i := 0; i < 100; i++ {
+		require.GreaterOrEqual(t, spec.sample(rng), time.Duration(0))
+	}
+}
+
+func TestLatencySpecSampleLognormalPositive(t *testing.T) {
+	spec := LatencySpec{Distribution: LatencyLognormal, Mean: -3, StdDev: 0.5}
+	rng := newStreamRand(3)
+	for i := 0; i < 50; i++ {
+		require.Greater(t, spec.sample(rng), time.Duration(0))
+	}
+}
+
+func TestLatencySpecSampleParetoAtLeastScale(t *testing.T) {
+	spec := LatencySpec{Distribution: LatencyPareto, Shape: 2, Scale: 5 * time.Millisecond}
+	rng := newStreamRand(4)
+	for i := 0; i < 50; i++ {
+		require.GreaterOrEqual(t, spec.sample(rng), spec.Scale)
+	}
+}
+
+func TestLatencySpecZeroValueSamplesNoDelay(t *testing.T) {
+	rng := newStreamRand(5)
+	require.Equal(t, time.Duration(0), (LatencySpec{}).sample(rng))
+}
+
+func TestNewStreamRandSameSeedReproducesSequence(t *testing.T) {
+	a := newStreamRand(42)
+	b := newStreamRand(42)
+	for i := 0; i < 20; i++ {
+		require.Equal(t, a.Int63n(1000), b.Int63n(1000))
+	}
+}
+
+func TestSleepForThrottleUsesSlowerOfLatencyAndThroughput(t *testing.T) {
+	opts := StreamOptions{
+		InterTokenLatency: LatencySpec{Distribution: LatencyUniform, Min: time.Millisecond, Max: time.Millisecond},
+		TokensPerSecond:   10, // ~3 tokens at 10 tok/sec -> ~300ms, dwarfing the 1ms InterTokenLatency draw
+	}
+	rng := newStreamRand(6)
+
+	start := time.Now()
+	sleepForThrottle(context.Background(), opts, rng, "one two three")
+	elapsed := time.Since(start)
+
+	// Confirms the two constraints compose as max(), not a stacked sum:
+	// a sum would still pass this bound, but a regression that dropped the
+	// throughput delay entirely would fail it.
+	require.Greater(t, elapsed, 100*time.Millisecond)
+}
+
+func TestSleepForThrottleScaledMultipliesDelay(t *testing.T) {
+	opts := StreamOptions{Delay: 5 * time.Millisecond}
+	rng := newStreamRand(8)
+
+	start := time.Now()
+	sleepForThrottleScaled(context.Background(), opts, rng, "chunk", 10)
+	elapsed := time.Since(start)
+
+	require.Greater(t, elapsed, 40*time.Millisecond)
+}
+
+func TestSleepForThrottleReturnsFalseWhenContextCanceled(t *testing.T) {
+	opts := StreamOptions{Delay: time.Hour}
+	rng := newStreamRand(7)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	require.False(t, sleepForThrottle(ctx, opts, rng, "one"))
+}
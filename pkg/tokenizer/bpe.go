@@ -0,0 +1,81 @@
+package tokenizer
+
+import "unicode"
+
+// mergeRank maps a (left, right) symbol pair to its priority in merges
+// (lower is higher priority), built once at package init time.
+var mergeRank = func() map[mergeRule]int {
+	ranks := make(map[mergeRule]int, len(merges))
+	for i, m := range merges {
+		ranks[m] = i
+	}
+	return ranks
+}()
+
+// BPETokenizer approximates a cl100k-style byte-pair-encoding tokenizer: it
+// pretokenizes text into letter/digit/whitespace/punctuation runs, then
+// repeatedly merges the best-ranked adjacent symbol pair within each letter
+// run until no merge rule applies, using the embedded merges table.
+type BPETokenizer struct{}
+
+// NewBPETokenizer builds the default tokenizer: the embedded merge table,
+// no external vocabulary file required.
+func NewBPETokenizer() *BPETokenizer {
+	return &BPETokenizer{}
+}
+
+// Encode implements Tokenizer.
+func (BPETokenizer) Encode(text string) []string {
+	var tokens []string
+	for _, piece := range pretokenize(text) {
+		r := []rune(piece)
+		if len(r) > 1 && unicode.IsLetter(r[0]) {
+			tokens = append(tokens, bpeMerge(piece)...)
+		} else {
+			tokens = append(tokens, piece)
+		}
+	}
+	return tokens
+}
+
+// Count implements Tokenizer.
+func (t BPETokenizer) Count(text string) int64 {
+	return int64(len(t.Encode(text)))
+}
+
+// bpeMerge runs the classic BPE loop over a single letter run: repeatedly
+// find the adjacent symbol pair with the best (lowest) rank in mergeRank and
+// collapse every occurrence of it, until no adjacent pair has a rule.
+func bpeMerge(piece string) []string {
+	symbols := make([]string, 0, len(piece))
+	for _, r := range piece {
+		symbols = append(symbols, string(r))
+	}
+
+	for len(symbols) > 1 {
+		bestRank := -1
+		var best mergeRule
+		for i := 0; i < len(symbols)-1; i++ {
+			pair := mergeRule{symbols[i], symbols[i+1]}
+			if rank, ok := mergeRank[pair]; ok && (bestRank == -1 || rank < bestRank) {
+				bestRank = rank
+				best = pair
+			}
+		}
+		if bestRank == -1 {
+			break
+		}
+
+		merged := make([]string, 0, len(symbols))
+		for i := 0; i < len(symbols); i++ {
+			if i < len(symbols)-1 && symbols[i] == best.left && symbols[i+1] == best.right {
+				merged = append(merged, best.left+best.right)
+				i++
+				continue
+			}
+			merged = append(merged, symbols[i])
+		}
+		symbols = merged
+	}
+	return symbols
+}
@@ -0,0 +1,52 @@
+package tokenizer
+
+// mergeRule is one entry of a BPE merge table: whenever left and right
+// appear as adjacent symbols, they may be combined into left+right.
+type mergeRule struct {
+	left, right string
+}
+
+// merges is a hand-built approximation of the merge table a real cl100k-style
+// BPE tokenizer learns from corpus statistics, ordered highest-priority
+// first: common English letter bigrams merge first (roughly by frequency),
+// then a second pass lets those pairs combine into common whole words and
+// affixes. It is not derived from an actual trained vocabulary - just large
+// enough to turn typical English prose into noticeably fewer tokens than
+// characters, which is all the simulator's usage accounting needs.
+var merges = []mergeRule{
+	// Pass 1: common letter bigrams, in roughly descending English frequency.
+	{"t", "h"}, {"i", "n"}, {"e", "r"}, {"a", "n"}, {"r", "e"}, {"o", "n"}, {"a", "t"}, {"e", "n"},
+	{"n", "d"}, {"t", "i"}, {"e", "s"}, {"o", "r"}, {"t", "e"}, {"o", "f"}, {"e", "d"}, {"i", "s"},
+	{"i", "t"}, {"a", "l"}, {"a", "r"}, {"s", "t"}, {"t", "o"}, {"n", "t"}, {"n", "g"}, {"s", "e"},
+	{"h", "a"}, {"a", "s"}, {"o", "u"}, {"i", "o"}, {"l", "e"}, {"v", "e"}, {"c", "o"}, {"m", "e"},
+	{"d", "e"}, {"h", "i"}, {"r", "i"}, {"r", "o"}, {"i", "c"}, {"n", "e"}, {"e", "a"}, {"r", "a"},
+	{"c", "e"}, {"l", "i"}, {"c", "h"}, {"l", "l"}, {"b", "e"}, {"m", "a"}, {"s", "i"}, {"o", "m"},
+	{"u", "r"}, {"g", "h"}, {"h", "e"}, {"w", "h"}, {"w", "i"}, {"n", "o"}, {"c", "a"}, {"f", "o"},
+	{"b", "u"}, {"y", "o"}, {"p", "r"}, {"d", "i"}, {"u", "s"}, {"s", "o"}, {"w", "e"}, {"a", "c"},
+
+	// Pass 2: pairing pass-1 outputs (and raw letters) into common whole
+	// words and affixes.
+	{"th", "e"},  // the
+	{"th", "at"}, // that
+	{"th", "is"}, // this
+	{"wh", "at"}, // what
+	{"wh", "e"},  // whe-
+	{"whe", "n"}, // when
+	{"wi", "th"}, // with
+	{"in", "g"},  // ing
+	{"an", "d"},  // and
+	{"ti", "on"}, // tion
+	{"er", "s"},  // ers
+	{"en", "t"},  // ent
+	{"re", "s"},  // res
+	{"no", "t"},  // not
+	{"ca", "n"},  // can
+	{"ar", "e"},  // are
+	{"f", "or"},  // for
+	{"y", "ou"},  // you
+	{"al", "l"},  // all
+	{"ha", "ve"}, // have
+	{"w", "as"},  // was
+	{"u", "t"},   // ut (feeds "but" below)
+	{"b", "ut"},  // but
+}
@@ -0,0 +1,37 @@
+package tokenizer
+
+import "unicode"
+
+// pretokenize splits text into maximal runs of letters, digits, whitespace,
+// or a single other/punctuation rune, mirroring the coarse pre-split real
+// BPE tokenizers (GPT-2/cl100k) apply before merging. Every returned piece
+// is an exact substring of text, and concatenating all pieces reproduces
+// text exactly - callers rely on this to truncate at a token boundary
+// without corrupting the surrounding text.
+func pretokenize(text string) []string {
+	runes := []rune(text)
+	var pieces []string
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		var class func(rune) bool
+		switch {
+		case unicode.IsSpace(r):
+			class = unicode.IsSpace
+		case unicode.IsLetter(r):
+			class = unicode.IsLetter
+		case unicode.IsDigit(r):
+			class = unicode.IsDigit
+		default:
+			pieces = append(pieces, string(r))
+			i++
+			continue
+		}
+		j := i + 1
+		for j < len(runes) && class(runes[j]) {
+			j++
+		}
+		pieces = append(pieces, string(runes[i:j]))
+		i = j
+	}
+	return pieces
+}
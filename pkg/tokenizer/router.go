@@ -0,0 +1,47 @@
+package tokenizer
+
+// Router resolves a Tokenizer for a requested model name, dispatching to a
+// configured entry when one is registered and falling back to the default
+// tokenizer for any unknown model - the same resolve-with-fallback shape
+// generator.BackendRouter uses for model routing.
+type Router struct {
+	routes   map[string]Tokenizer
+	fallback Tokenizer
+}
+
+// NewRouter builds a router with no configured routes; every model resolves
+// to the default BPETokenizer until entries are registered.
+func NewRouter() *Router {
+	return &Router{
+		routes:   make(map[string]Tokenizer),
+		fallback: NewBPETokenizer(),
+	}
+}
+
+// Register assigns t as the tokenizer for model.
+func (r *Router) Register(model string, t Tokenizer) {
+	r.routes[model] = t
+}
+
+// Resolve returns model's registered tokenizer, or the default BPETokenizer
+// if none is registered.
+func (r *Router) Resolve(model string) Tokenizer {
+	if t, ok := r.routes[model]; ok {
+		return t
+	}
+	return r.fallback
+}
+
+// ByName resolves a tokenizer implementation by its config name: "bpe" (also
+// the default for an empty name) or "whitespace". It returns nil for an
+// unrecognized name so callers can decide how to report the error.
+func ByName(name string) Tokenizer {
+	switch name {
+	case "", "bpe":
+		return NewBPETokenizer()
+	case "whitespace":
+		return NewWhitespaceTokenizer()
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,44 @@
+// Package tokenizer provides lightweight, deterministic token counting for
+// the simulator's usage accounting and max_tokens/pacing enforcement. It
+// does not bundle a real model vocabulary; BPETokenizer instead applies an
+// embedded table of the most common English merges (a cl100k-style
+// approximation), which is enough to turn character counts into plausible,
+// stable token counts without shipping a multi-megabyte vocab file.
+package tokenizer
+
+import "strings"
+
+// Tokenizer turns text into the token strings a model's tokenizer would
+// emit for it. Every token is a substring of text, in order. Joining
+// BPETokenizer's tokens with "" reconstructs the original text exactly;
+// WhitespaceTokenizer drops whitespace, so joining its tokens does not.
+type Tokenizer interface {
+	// Encode splits text into token strings.
+	Encode(text string) []string
+	// Count is equivalent to len(Encode(text)), exposed separately so
+	// callers that only need a count avoid allocating the token slice.
+	Count(text string) int64
+}
+
+// Truncate returns the longest prefix of text whose token count under t is
+// <= maxTokens, ending right after the last kept token, found by locating
+// each kept token in text in order. ok reports whether text had to be cut;
+// when false, truncated == text. maxTokens <= 0 is treated as "no limit".
+func Truncate(t Tokenizer, text string, maxTokens int64) (truncated string, ok bool) {
+	if maxTokens <= 0 {
+		return text, false
+	}
+	tokens := t.Encode(text)
+	if int64(len(tokens)) <= maxTokens {
+		return text, false
+	}
+	end := 0
+	for _, tok := range tokens[:maxTokens] {
+		i := strings.Index(text[end:], tok)
+		if i < 0 {
+			break
+		}
+		end += i + len(tok)
+	}
+	return text[:end], true
+}
@@ -0,0 +1,59 @@
+package tokenizer
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestBPETokenizer_EncodeReconstructsOriginalText(t *testing.T) {
+	tok := NewBPETokenizer()
+	text := "The quick brown fox jumps over 42 lazy dogs!"
+
+	require.Equal(t, text, strings.Join(tok.Encode(text), ""))
+}
+
+func TestBPETokenizer_CompressesCommonWordsBelowCharacterCount(t *testing.T) {
+	tok := NewBPETokenizer()
+	text := "the quick brown fox and the lazy dog"
+
+	count := tok.Count(text)
+	require.Less(t, count, int64(len(text)))
+	require.Greater(t, count, int64(0))
+}
+
+func TestWhitespaceTokenizer_DropsWhitespaceKeepsWordsAndPunctuation(t *testing.T) {
+	tok := NewWhitespaceTokenizer()
+
+	tokens := tok.Encode("Hello, world!")
+	require.Equal(t, []string{"Hello", ",", "world", "!"}, tokens)
+}
+
+func TestRouter_ResolveFallsBackToDefault(t *testing.T) {
+	r := NewRouter()
+	r.Register("gpt-sim-1", NewWhitespaceTokenizer())
+
+	_, ok := r.Resolve("gpt-sim-1").(*WhitespaceTokenizer)
+	require.True(t, ok)
+
+	_, ok = r.Resolve("unconfigured-model").(*BPETokenizer)
+	require.True(t, ok)
+}
+
+func TestByName_UnrecognizedReturnsNil(t *testing.T) {
+	require.Nil(t, ByName("gguf-vocab"))
+}
+
+func TestTruncate_CutsAtTokenBoundaryAndReportsTruncation(t *testing.T) {
+	tok := NewWhitespaceTokenizer()
+	text := "one two three four five"
+
+	truncated, ok := Truncate(tok, text, 2)
+	require.True(t, ok)
+	require.Equal(t, "one two", truncated)
+
+	same, ok := Truncate(tok, text, 100)
+	require.False(t, ok)
+	require.Equal(t, text, same)
+}
@@ -0,0 +1,32 @@
+package tokenizer
+
+import "strings"
+
+// WhitespaceTokenizer is the fast tokenizer a model gets by configuring
+// the name "whitespace" (see ByName); Router does not fall back to it, as a
+// model with no configured tokenizer resolves to BPETokenizer. Every run of
+// letters or digits, and every other non-space rune, is one token, and
+// whitespace runs are dropped rather than counted, with no BPE merge pass.
+type WhitespaceTokenizer struct{}
+
+// NewWhitespaceTokenizer builds a WhitespaceTokenizer.
+func NewWhitespaceTokenizer() *WhitespaceTokenizer {
+	return &WhitespaceTokenizer{}
+}
+
+// Encode implements Tokenizer.
+func (WhitespaceTokenizer) Encode(text string) []string {
+	var tokens []string
+	for _, piece := range pretokenize(text) {
+		if strings.TrimSpace(piece) == "" {
+			continue
+		}
+		tokens = append(tokens, piece)
+	}
+	return tokens
+}
+
+// Count implements Tokenizer.
+func (t WhitespaceTokenizer) Count(text string) int64 {
+	return int64(len(t.Encode(text)))
+}
@@ -0,0 +1,75 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+)
+
+// silentWAVSampleRate/BitsPerSample/Channels describe the PCM format used
+// for synthesized audio; 16kHz mono 16-bit is small and decodes cleanly in
+// every client library we've seen exercise this endpoint.
+const (
+	silentWAVSampleRate    = 16000
+	silentWAVBitsPerSample = 16
+	silentWAVChannels      = 1
+)
+
+// SilentWAV builds a valid (silent) PCM WAV file of the given duration.
+func SilentWAV(duration float64) []byte {
+	if duration < 0 {
+		duration = 0
+	}
+	numSamples := int(duration * float64(silentWAVSampleRate))
+	dataSize := numSamples * silentWAVChannels * (silentWAVBitsPerSample / 8)
+
+	var buf bytes.Buffer
+	buf.WriteString("RIFF")
+	binary.Write(&buf, binary.LittleEndian, uint32(36+dataSize))
+	buf.WriteString("WAVE")
+
+	buf.WriteString("fmt ")
+	binary.Write(&buf, binary.LittleEndian, uint32(16)) // PCM fmt chunk size
+	binary.Write(&buf, binary.LittleEndian, uint16(1))  // PCM format
+	binary.Write(&buf, binary.LittleEndian, uint16(silentWAVChannels))
+	binary.Write(&buf, binary.LittleEndian, uint32(silentWAVSampleRate))
+	byteRate := silentWAVSampleRate * silentWAVChannels * (silentWAVBitsPerSample / 8)
+	binary.Write(&buf, binary.LittleEndian, uint32(byteRate))
+	blockAlign := silentWAVChannels * (silentWAVBitsPerSample / 8)
+	binary.Write(&buf, binary.LittleEndian, uint16(blockAlign))
+	binary.Write(&buf, binary.LittleEndian, uint16(silentWAVBitsPerSample))
+
+	buf.WriteString("data")
+	binary.Write(&buf, binary.LittleEndian, uint32(dataSize))
+	buf.Write(make([]byte, dataSize)) // all-zero samples: silence
+
+	return buf.Bytes()
+}
+
+// SilentWAVBase64 returns a base64-encoded silent WAV of the given
+// duration, matching the `audio.data` field OpenAI returns for audio-out.
+func SilentWAVBase64(duration float64) string {
+	return base64.StdEncoding.EncodeToString(SilentWAV(duration))
+}
+
+// mp3FrameBitrate/SampleRate describe the single MPEG-1 Layer III frame
+// SilentMP3 emits: 128kbps/44.1kHz mono, a standard enough combination that
+// clients sniffing the header don't balk at it.
+const (
+	mp3FrameBitrate    = 128000
+	mp3FrameSampleRate = 44100
+)
+
+// SilentMP3 returns a single valid MPEG-1 Layer III frame header followed
+// by zeroed frame data. There is no MP3 encoder in the simulator, so this
+// isn't real encoded silence, but it carries the sync bytes and frame size
+// real clients expect from an `/v1/audio/speech` response.
+func SilentMP3() []byte {
+	frameSize := (144 * mp3FrameBitrate) / mp3FrameSampleRate
+	frame := make([]byte, frameSize)
+	frame[0] = 0xFF // frame sync
+	frame[1] = 0xFB // MPEG-1, Layer III, no CRC
+	frame[2] = 0x90 // 128kbps, 44.1kHz, no padding
+	frame[3] = 0xC0 // mono, no emphasis
+	return frame
+}
@@ -0,0 +1,34 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+)
+
+// SolidColorPNG renders a width x height PNG filled with a single color, so
+// image-generation endpoints can return a deterministic, valid image
+// without a real diffusion model.
+func SolidColorPNG(width, height int, c color.RGBA) ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: c}, image.Point{}, draw.Src)
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// SolidColorPNGBase64 returns SolidColorPNG, base64-encoded for inline
+// `b64_json` responses.
+func SolidColorPNGBase64(width, height int, c color.RGBA) (string, error) {
+	data, err := SolidColorPNG(width, height, c)
+	if err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(data), nil
+}
@@ -48,6 +48,25 @@ func TokenizeText(text string) []string {
 	return strings.Fields(text)
 }
 
+// TruncateAtStop cuts text at the earliest occurrence of any stop sequence,
+// mirroring OpenAI's `stop` parameter. It reports whether a stop sequence
+// was found so callers can set `finish_reason: "stop"` accordingly.
+func TruncateAtStop(text string, stops []string) (string, bool) {
+	cut := -1
+	for _, stop := range stops {
+		if stop == "" {
+			continue
+		}
+		if idx := strings.Index(text, stop); idx != -1 && (cut == -1 || idx < cut) {
+			cut = idx
+		}
+	}
+	if cut == -1 {
+		return text, false
+	}
+	return text[:cut], true
+}
+
 // RandomInt generates a random integer between min and max
 func RandomInt(min, max int) int {
 	if min > max {